@@ -0,0 +1,72 @@
+// Copyright The Notary Project Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notation
+
+import (
+	"context"
+	"errors"
+
+	"github.com/notaryproject/notation-go/registry"
+)
+
+// AdmissionImage is an image to be admitted, e.g. by a Kubernetes validating
+// admission webhook.
+type AdmissionImage struct {
+	// Repository is the repository of the image
+	Repository registry.Repository
+
+	// VerifyOptions are the options verifying the image. Its
+	// ArtifactReference is the reference of the image.
+	VerifyOptions VerifyOptions
+}
+
+// AdmissionDenial describes why an image is denied admission.
+type AdmissionDenial struct {
+	// ArtifactReference is the reference of the denied image
+	ArtifactReference string
+
+	// Reason is a human readable reason of the denial, suitable for the
+	// message of an admission response
+	Reason string
+
+	// Error is the verification error denying the image
+	Error error
+}
+
+// AdmissionDecision verifies each image against the trust policy of the
+// verifier, and returns whether all images are allowed along with the
+// denials of the images that are not. An image is denied if its signature
+// verification fails, including when it is unsigned under a trust policy
+// statement that does not skip verification. Images under a trust policy
+// statement that skips verification are allowed.
+// An error is only returned if the decision cannot be made, e.g. when ctx
+// is done.
+func AdmissionDecision(ctx context.Context, verifier Verifier, images []AdmissionImage) (allowed bool, denials []AdmissionDenial, err error) {
+	if verifier == nil {
+		return false, nil, errors.New("verifier cannot be nil")
+	}
+	for _, image := range images {
+		if err := ctx.Err(); err != nil {
+			return false, nil, err
+		}
+		if _, _, err := Verify(ctx, verifier, image.Repository, image.VerifyOptions); err != nil {
+			denials = append(denials, AdmissionDenial{
+				ArtifactReference: image.VerifyOptions.ArtifactReference,
+				Reason:            err.Error(),
+				Error:             err,
+			})
+		}
+	}
+	return len(denials) == 0, denials, nil
+}
@@ -0,0 +1,145 @@
+// Copyright The Notary Project Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/notaryproject/notation-go/internal/envelope"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+)
+
+const (
+	// InTotoStatementType is the type of in-toto statements.
+	InTotoStatementType = "https://in-toto.io/Statement/v1"
+
+	// VerificationPredicateType is the predicate type of the in-toto
+	// statements attesting signature verifications.
+	VerificationPredicateType = "https://notaryproject.dev/attestations/verification/v1"
+)
+
+// InTotoStatement is an in-toto statement.
+type InTotoStatement struct {
+	Type          string                `json:"_type"`
+	Subject       []InTotoSubject       `json:"subject"`
+	PredicateType string                `json:"predicateType"`
+	Predicate     VerificationPredicate `json:"predicate"`
+}
+
+// InTotoSubject is the subject of an in-toto statement.
+type InTotoSubject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+// VerificationPredicate is the predicate of an in-toto statement attesting a
+// signature verification.
+type VerificationPredicate struct {
+	// Verified is true if the signature verification succeeded.
+	Verified bool `json:"verified"`
+
+	// Error is the error of the failed signature verification.
+	Error string `json:"error,omitempty"`
+
+	// PolicyStatement is the name of the applied trust policy statement.
+	PolicyStatement string `json:"policyStatement,omitempty"`
+
+	// VerificationLevel is the name of the applied verification level.
+	VerificationLevel string `json:"verificationLevel,omitempty"`
+
+	// SignerIdentity is the trusted identity of the signing certificate.
+	SignerIdentity string `json:"signerIdentity,omitempty"`
+
+	// SigningTime is the authentic signing time of the signature. It is
+	// omitted if the signature has no authentic signing time.
+	SigningTime *time.Time `json:"signingTime,omitempty"`
+
+	// TimeVerified is the time the attestation is created.
+	TimeVerified time.Time `json:"timeVerified"`
+
+	// Results are the results of the performed validations.
+	Results []VerificationPredicateResult `json:"results,omitempty"`
+}
+
+// VerificationPredicateResult is the result of a validation in a
+// VerificationPredicate.
+type VerificationPredicateResult struct {
+	Type   string `json:"type"`
+	Action string `json:"action"`
+	Error  string `json:"error,omitempty"`
+}
+
+// ToAttestation returns the JSON encoded in-toto statement attesting the
+// verification outcome of the signature of the subject artifact. The
+// statement can be signed and stored like any other attestation. It fails if
+// the subject does not match the target artifact of the signature.
+func (outcome *VerificationOutcome) ToAttestation(subject ocispec.Descriptor) ([]byte, error) {
+	if outcome.EnvelopeContent == nil {
+		return nil, errors.New("unable to find envelope content for verification outcome")
+	}
+	var payload envelope.Payload
+	if err := json.Unmarshal(outcome.EnvelopeContent.Payload.Content, &payload); err != nil {
+		return nil, errors.New("failed to unmarshal the payload content in the signature blob to envelope.Payload")
+	}
+	if !content.Equal(payload.TargetArtifact, subject) {
+		return nil, fmt.Errorf("subject %s does not match the target artifact %s of the signature", subject.Digest, payload.TargetArtifact.Digest)
+	}
+	if err := subject.Digest.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid subject digest: %w", err)
+	}
+
+	predicate := VerificationPredicate{
+		Verified:        outcome.Error == nil,
+		PolicyStatement: outcome.PolicyStatement,
+		SignerIdentity:  outcome.SignerIdentity,
+		TimeVerified:    time.Now().UTC(),
+	}
+	if outcome.Error != nil {
+		predicate.Error = outcome.Error.Error()
+	}
+	if outcome.VerificationLevel != nil {
+		predicate.VerificationLevel = outcome.VerificationLevel.Name
+	}
+	if signingTime, err := outcome.EnvelopeContent.SignerInfo.AuthenticSigningTime(); err == nil {
+		predicate.SigningTime = &signingTime
+	}
+	for _, result := range outcome.VerificationResults {
+		predicateResult := VerificationPredicateResult{
+			Type:   string(result.Type),
+			Action: string(result.Action),
+		}
+		if result.Error != nil {
+			predicateResult.Error = result.Error.Error()
+		}
+		predicate.Results = append(predicate.Results, predicateResult)
+	}
+
+	name := subject.Annotations[ocispec.AnnotationRefName]
+	if name == "" {
+		name = subject.Digest.String()
+	}
+	return json.Marshal(InTotoStatement{
+		Type: InTotoStatementType,
+		Subject: []InTotoSubject{{
+			Name:   name,
+			Digest: map[string]string{subject.Digest.Algorithm().String(): subject.Digest.Encoded()},
+		}},
+		PredicateType: VerificationPredicateType,
+		Predicate:     predicate,
+	})
+}
@@ -0,0 +1,56 @@
+// Copyright The Notary Project Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notation
+
+import (
+	"sort"
+
+	"github.com/notaryproject/notation-core-go/signature"
+	"github.com/notaryproject/notation-go/plugin/proto"
+)
+
+// Capabilities describes the features supported by the library at runtime.
+type Capabilities struct {
+	// EnvelopeMediaTypes are the media types of the signature envelopes
+	// registered at runtime, e.g. "application/jose+json" and
+	// "application/cose". An envelope format is registered by importing its
+	// package in notation-core-go.
+	EnvelopeMediaTypes []string
+
+	// RevocationMethods are the supported methods of checking the
+	// revocation status of certificates.
+	RevocationMethods []string
+
+	// SigningSchemes are the supported signing schemes.
+	SigningSchemes []signature.SigningScheme
+
+	// PluginContractVersions are the supported versions of the plugin
+	// contract.
+	PluginContractVersions []string
+}
+
+// GetCapabilities returns the features supported by the library at runtime.
+func GetCapabilities() Capabilities {
+	envelopeMediaTypes := signature.RegisteredEnvelopeTypes()
+	sort.Strings(envelopeMediaTypes)
+	return Capabilities{
+		EnvelopeMediaTypes: envelopeMediaTypes,
+		RevocationMethods:  []string{"ocsp"},
+		SigningSchemes: []signature.SigningScheme{
+			signature.SigningSchemeX509,
+			signature.SigningSchemeX509SigningAuthority,
+		},
+		PluginContractVersions: []string{proto.ContractVersion},
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright The Notary Project Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package envelope
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+
+	"github.com/notaryproject/notation-core-go/signature"
+	"github.com/veraison/go-cose"
+)
+
+// ReplaceCertificateChain returns the signature envelope blob with its
+// embedded certificate chain replaced by certChain. The certificate chain is
+// an unprotected header in both JWS and COSE envelopes, so the signature of
+// the envelope is not affected.
+func ReplaceCertificateChain(envelopeMediaType string, envelopeBytes []byte, certChain []*x509.Certificate) ([]byte, error) {
+	rawCertChain := make([][]byte, 0, len(certChain))
+	for _, cert := range certChain {
+		rawCertChain = append(rawCertChain, cert.Raw)
+	}
+	switch envelopeMediaType {
+	case mediaTypeJWSEnvelope:
+		var envelope map[string]json.RawMessage
+		if err := json.Unmarshal(envelopeBytes, &envelope); err != nil {
+			return nil, &signature.InvalidSignatureError{Msg: fmt.Sprintf("malformed JWS envelope: %v", err)}
+		}
+		var header map[string]json.RawMessage
+		if err := json.Unmarshal(envelope["header"], &header); err != nil {
+			return nil, &signature.InvalidSignatureError{Msg: fmt.Sprintf("malformed JWS envelope header: %v", err)}
+		}
+		var err error
+		if header["x5c"], err = json.Marshal(rawCertChain); err != nil {
+			return nil, err
+		}
+		if envelope["header"], err = json.Marshal(header); err != nil {
+			return nil, err
+		}
+		return json.Marshal(envelope)
+	case mediaTypeCOSEEnvelope:
+		var msg cose.Sign1Message
+		if err := msg.UnmarshalCBOR(envelopeBytes); err != nil {
+			return nil, &signature.InvalidSignatureError{Msg: fmt.Sprintf("malformed COSE envelope: %v", err)}
+		}
+		x5chain := make([]any, 0, len(rawCertChain))
+		for _, rawCert := range rawCertChain {
+			x5chain = append(x5chain, rawCert)
+		}
+		if msg.Headers.Unprotected == nil {
+			msg.Headers.Unprotected = cose.UnprotectedHeader{}
+		}
+		msg.Headers.Unprotected[cose.HeaderLabelX5Chain] = x5chain
+		// drop the raw unprotected header, it takes precedence when
+		// marshalling
+		msg.Headers.RawUnprotected = nil
+		return msg.MarshalCBOR()
+	default:
+		return nil, fmt.Errorf("signature envelope format with media type %q is not supported", envelopeMediaType)
+	}
+}
+
+// coseRawCertificateChain returns the DER encoded certificates of the x5chain
+// unprotected header of a COSE envelope.
+func coseRawCertificateChain(unprotected cose.UnprotectedHeader) ([][]byte, error) {
+	// x5chain is either a single certificate or an array of certificates
+	switch certChain := unprotected[cose.HeaderLabelX5Chain].(type) {
+	case []byte:
+		return [][]byte{certChain}, nil
+	case []any:
+		rawCertChain := make([][]byte, 0, len(certChain))
+		for _, cert := range certChain {
+			rawCert, ok := cert.([]byte)
+			if !ok {
+				return nil, &signature.InvalidSignatureError{Msg: "malformed certificate in certificate chain"}
+			}
+			rawCertChain = append(rawCertChain, rawCert)
+		}
+		return rawCertChain, nil
+	default:
+		return nil, nil
+	}
+}
+
+// parseCertificateChain parses the DER encoded certificates of a certificate
+// chain embedded in a signature envelope.
+func parseCertificateChain(rawCertChain [][]byte) ([]*x509.Certificate, error) {
+	if len(rawCertChain) == 0 || len(rawCertChain[0]) == 0 {
+		return nil, &signature.InvalidSignatureError{Msg: "certificate chain is not present"}
+	}
+	certChain := make([]*x509.Certificate, 0, len(rawCertChain))
+	for i, rawCert := range rawCertChain {
+		cert, err := x509.ParseCertificate(rawCert)
+		if err != nil {
+			if i == 0 {
+				return nil, &signature.InvalidSignatureError{Msg: "malformed leaf certificate"}
+			}
+			return nil, &signature.InvalidSignatureError{Msg: fmt.Sprintf("malformed certificate in certificate chain: %v", err)}
+		}
+		certChain = append(certChain, cert)
+	}
+	return certChain, nil
+}
@@ -0,0 +1,173 @@
+// Copyright The Notary Project Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package envelope
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/notaryproject/notation-core-go/signature"
+	"github.com/veraison/go-cose"
+)
+
+const (
+	mediaTypeJWSEnvelope  = "application/jose+json"
+	mediaTypeCOSEEnvelope = "application/cose"
+
+	headerKeyCrit = "crit"
+)
+
+// reservedJWSHeaders are the header parameters defined by RFC 7515 that must
+// not be listed in the "crit" header.
+// Reference: https://datatracker.ietf.org/doc/html/rfc7515#section-4.1.11
+var reservedJWSHeaders = []string{"alg", "jku", "jwk", "kid", "x5u", "x5c", "x5t", "x5t#S256", "typ", "cty", headerKeyCrit}
+
+// reservedCOSEHeaders are the header parameters defined by RFC 9052 and
+// RFC 9360 that must not be listed in the "crit" header.
+var reservedCOSEHeaders = []int64{
+	cose.HeaderLabelAlgorithm,
+	cose.HeaderLabelCritical,
+	cose.HeaderLabelContentType,
+	cose.HeaderLabelKeyID,
+	cose.HeaderLabelIV,
+	cose.HeaderLabelPartialIV,
+	cose.HeaderLabelCounterSignature,
+	cose.HeaderLabelCounterSignature0,
+	cose.HeaderLabelX5Bag,
+	cose.HeaderLabelX5Chain,
+	cose.HeaderLabelX5T,
+	cose.HeaderLabelX5U,
+}
+
+// validateJWSCriticalHeaders validates the "crit" header of the encoded JWS
+// protected header. If present, "crit" must be non-empty, must not contain
+// duplicate entries, and must only list extension headers that are present in
+// the protected header.
+func validateJWSCriticalHeaders(encodedProtected string) error {
+	headerBytes, err := base64.RawURLEncoding.DecodeString(encodedProtected)
+	if err != nil {
+		return &signature.InvalidSignatureError{Msg: fmt.Sprintf("malformed JWS protected header: %v", err)}
+	}
+	var protected map[string]json.RawMessage
+	if err := json.Unmarshal(headerBytes, &protected); err != nil {
+		return &signature.InvalidSignatureError{Msg: fmt.Sprintf("malformed JWS protected header: %v", err)}
+	}
+	rawCrit, ok := protected[headerKeyCrit]
+	if !ok {
+		return nil
+	}
+	var crit []string
+	if err := json.Unmarshal(rawCrit, &crit); err != nil {
+		return &signature.InvalidSignatureError{Msg: fmt.Sprintf("malformed %q header: %v", headerKeyCrit, err)}
+	}
+
+	labels := make([]any, 0, len(crit))
+	for _, label := range crit {
+		labels = append(labels, label)
+	}
+	return validateCriticalLabels(labels, func(label any) bool {
+		_, ok := protected[label.(string)]
+		return ok
+	}, func(label any) bool {
+		for _, reserved := range reservedJWSHeaders {
+			if label == reserved {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// validateCOSECriticalHeaders validates the "crit" header of the decoded COSE
+// protected header, in the same way as validateJWSCriticalHeaders.
+func validateCOSECriticalHeaders(protectedHeader cose.ProtectedHeader) error {
+	value, ok := protectedHeader[cose.HeaderLabelCritical]
+	if !ok {
+		return nil
+	}
+	crit, ok := value.([]any)
+	if !ok {
+		return &signature.InvalidSignatureError{Msg: fmt.Sprintf("malformed %q header", headerKeyCrit)}
+	}
+
+	// normalize the labels and the protected header keys, so that integer
+	// labels are comparable regardless of their decoded type
+	protected := make(map[any]struct{}, len(protectedHeader))
+	for key := range protectedHeader {
+		protected[normalizeCOSELabel(key)] = struct{}{}
+	}
+	labels := make([]any, 0, len(crit))
+	for _, label := range crit {
+		labels = append(labels, normalizeCOSELabel(label))
+	}
+	return validateCriticalLabels(labels, func(label any) bool {
+		_, ok := protected[label]
+		return ok
+	}, func(label any) bool {
+		for _, reserved := range reservedCOSEHeaders {
+			if label == reserved {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// validateCriticalLabels validates the labels listed in the "crit" header.
+func validateCriticalLabels(labels []any, isPresent, isReserved func(label any) bool) error {
+	if len(labels) == 0 {
+		return &signature.InvalidSignatureError{Msg: fmt.Sprintf("%q header cannot be empty", headerKeyCrit)}
+	}
+	seen := make(map[any]struct{}, len(labels))
+	for _, label := range labels {
+		if _, ok := seen[label]; ok {
+			return &signature.InvalidSignatureError{Msg: fmt.Sprintf("%q header contains duplicate entry %v", headerKeyCrit, label)}
+		}
+		seen[label] = struct{}{}
+		if isReserved(label) {
+			return &signature.InvalidSignatureError{Msg: fmt.Sprintf("%q header cannot contain the reserved header %v", headerKeyCrit, label)}
+		}
+		if !isPresent(label) {
+			return &signature.InvalidSignatureError{Msg: fmt.Sprintf("%v header is marked critical but not present in the protected header", label)}
+		}
+	}
+	return nil
+}
+
+// normalizeCOSELabel converts integer COSE header labels to int64.
+func normalizeCOSELabel(label any) any {
+	switch v := label.(type) {
+	case int:
+		return int64(v)
+	case int8:
+		return int64(v)
+	case int16:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case uint:
+		return int64(v)
+	case uint8:
+		return int64(v)
+	case uint16:
+		return int64(v)
+	case uint32:
+		return int64(v)
+	case uint64:
+		return int64(v)
+	default:
+		return label
+	}
+}
@@ -14,12 +14,17 @@
 package envelope
 
 import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"time"
 
 	"github.com/notaryproject/notation-core-go/signature"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/veraison/go-cose"
 )
 
 // MediaTypePayloadV1 is the supported content type for signature's payload.
@@ -54,6 +59,108 @@ func SanitizeTargetArtifact(targetArtifact ocispec.Descriptor) ocispec.Descripto
 	}
 }
 
+// Envelope is a parsed signature envelope.
+type Envelope struct {
+	signature.Envelope
+
+	// CertificateChain is the certificate chain embedded in the envelope, in
+	// the order of the envelope. It is not validated, so that it can be
+	// inspected before the envelope is verified.
+	// It is nil if the envelope media type is unknown.
+	CertificateChain []*x509.Certificate
+}
+
+// Parse parses the signature envelope blob. In addition to
+// signature.ParseEnvelope, it strictly validates the "crit" protected header
+// and extracts the embedded certificate chain, neither of which the parsed
+// signature.Envelope exposes before it is validated.
+func Parse(envelopeMediaType string, envelopeBytes []byte) (*Envelope, error) {
+	sigEnv, err := signature.ParseEnvelope(envelopeMediaType, envelopeBytes)
+	if err != nil {
+		return nil, err
+	}
+	var rawCertChain [][]byte
+	switch envelopeMediaType {
+	case mediaTypeJWSEnvelope:
+		var envelope struct {
+			Protected string `json:"protected"`
+			Header    struct {
+				CertChain [][]byte `json:"x5c"`
+			} `json:"header"`
+		}
+		if err := json.Unmarshal(envelopeBytes, &envelope); err != nil {
+			return nil, &signature.InvalidSignatureError{Msg: fmt.Sprintf("malformed JWS envelope: %v", err)}
+		}
+		if err := validateJWSCriticalHeaders(envelope.Protected); err != nil {
+			return nil, err
+		}
+		rawCertChain = envelope.Header.CertChain
+	case mediaTypeCOSEEnvelope:
+		var msg cose.Sign1Message
+		if err := msg.UnmarshalCBOR(envelopeBytes); err != nil {
+			return nil, &signature.InvalidSignatureError{Msg: fmt.Sprintf("malformed COSE envelope: %v", err)}
+		}
+		if err := validateCOSECriticalHeaders(msg.Headers.Protected); err != nil {
+			return nil, err
+		}
+		if rawCertChain, err = coseRawCertificateChain(msg.Headers.Unprotected); err != nil {
+			return nil, err
+		}
+	default:
+		return &Envelope{Envelope: sigEnv}, nil
+	}
+	certChain, err := parseCertificateChain(rawCertChain)
+	if err != nil {
+		return nil, err
+	}
+	return &Envelope{Envelope: sigEnv, CertificateChain: certChain}, nil
+}
+
+// parsedEnvelopeContextKey is the context key of a parsed signature envelope.
+type parsedEnvelopeContextKey struct{}
+
+type parsedEnvelope struct {
+	mediaType string
+	blob      []byte
+	envelope  *Envelope
+}
+
+// WithParsedEnvelope returns a copy of ctx carrying the envelope parsed from
+// the envelope blob of the media type, so that it is not parsed again by the
+// verifier.
+func WithParsedEnvelope(ctx context.Context, envelopeMediaType string, envelopeBytes []byte, sigEnv *Envelope) context.Context {
+	return context.WithValue(ctx, parsedEnvelopeContextKey{}, parsedEnvelope{
+		mediaType: envelopeMediaType,
+		blob:      envelopeBytes,
+		envelope:  sigEnv,
+	})
+}
+
+// ParsedEnvelopeFromContext returns the envelope carried by ctx if it was
+// parsed from the same envelope blob of the media type.
+func ParsedEnvelopeFromContext(ctx context.Context, envelopeMediaType string, envelopeBytes []byte) (*Envelope, bool) {
+	parsed, ok := ctx.Value(parsedEnvelopeContextKey{}).(parsedEnvelope)
+	if !ok || parsed.mediaType != envelopeMediaType || !bytes.Equal(parsed.blob, envelopeBytes) {
+		return nil, false
+	}
+	return parsed.envelope, true
+}
+
+// TargetArtifact returns the target artifact of the payload of the parsed
+// signature envelope content without validating the envelope. It allows
+// inspecting the signed artifact, e.g. its digest algorithm, before the
+// envelope is verified.
+func TargetArtifact(envContent *signature.EnvelopeContent) (ocispec.Descriptor, error) {
+	if err := ValidatePayloadContentType(&envContent.Payload); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	var payload Payload
+	if err := json.Unmarshal(envContent.Payload.Content, &payload); err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("signature envelope payload can't be unmarshalled: %w", err)
+	}
+	return payload.TargetArtifact, nil
+}
+
 // SigningTime returns the signing time of a signature envelope blob
 func SigningTime(signerInfo *signature.SignerInfo) (time.Time, error) {
 	// sanity check
@@ -14,6 +14,9 @@
 package envelope
 
 import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"testing"
 	"time"
@@ -21,6 +24,7 @@ import (
 	"github.com/notaryproject/notation-core-go/signature"
 	"github.com/notaryproject/notation-core-go/signature/cose"
 	"github.com/notaryproject/notation-core-go/signature/jws"
+	"github.com/notaryproject/notation-core-go/testhelper"
 	gcose "github.com/veraison/go-cose"
 )
 
@@ -119,6 +123,118 @@ func TestSigningTime(t *testing.T) {
 	}
 }
 
+func TestParseCriticalHeaders(t *testing.T) {
+	leafCert := testhelper.GetRSALeafCertificate().Cert
+	jwsEnvelope := func(protected string) []byte {
+		envelope, _ := json.Marshal(map[string]any{
+			"protected": base64.RawURLEncoding.EncodeToString([]byte(protected)),
+			"header":    map[string]any{"x5c": [][]byte{leafCert.Raw}},
+			"payload":   "",
+			"signature": "",
+		})
+		return envelope
+	}
+	coseEnvelope := func(protected gcose.ProtectedHeader) []byte {
+		msg := gcose.Sign1Message{
+			Headers: gcose.Headers{
+				Protected:   protected,
+				Unprotected: gcose.UnprotectedHeader{gcose.HeaderLabelX5Chain: []any{leafCert.Raw}},
+			},
+			Payload:   []byte("valid"),
+			Signature: []byte("valid"),
+		}
+		envelope, err := msg.MarshalCBOR()
+		if err != nil {
+			t.Fatalf("failed to marshal COSE envelope: %v", err)
+		}
+		return envelope
+	}
+
+	tests := []struct {
+		name        string
+		mediaType   string
+		envelope    []byte
+		expectedErr error
+	}{
+		{
+			name:      "jws valid crit",
+			mediaType: jws.MediaTypeEnvelope,
+			envelope:  jwsEnvelope(`{"alg":"PS256","crit":["io.cncf.notary.signingScheme","SomeKey"],"io.cncf.notary.signingScheme":"notary.x509","SomeKey":"SomeValue"}`),
+		},
+		{
+			name:      "jws without crit",
+			mediaType: jws.MediaTypeEnvelope,
+			envelope:  jwsEnvelope(`{"alg":"PS256"}`),
+		},
+		{
+			name:        "jws empty crit",
+			mediaType:   jws.MediaTypeEnvelope,
+			envelope:    jwsEnvelope(`{"alg":"PS256","crit":[]}`),
+			expectedErr: errors.New(`"crit" header cannot be empty`),
+		},
+		{
+			name:        "jws duplicate crit entries",
+			mediaType:   jws.MediaTypeEnvelope,
+			envelope:    jwsEnvelope(`{"alg":"PS256","crit":["io.cncf.notary.signingScheme","io.cncf.notary.signingScheme"],"io.cncf.notary.signingScheme":"notary.x509"}`),
+			expectedErr: errors.New(`"crit" header contains duplicate entry io.cncf.notary.signingScheme`),
+		},
+		{
+			name:        "jws crit referencing absent header",
+			mediaType:   jws.MediaTypeEnvelope,
+			envelope:    jwsEnvelope(`{"alg":"PS256","crit":["io.cncf.notary.signingScheme","SomeKey"],"io.cncf.notary.signingScheme":"notary.x509"}`),
+			expectedErr: errors.New(`SomeKey header is marked critical but not present in the protected header`),
+		},
+		{
+			name:        "jws crit referencing reserved header",
+			mediaType:   jws.MediaTypeEnvelope,
+			envelope:    jwsEnvelope(`{"alg":"PS256","crit":["alg"]}`),
+			expectedErr: errors.New(`"crit" header cannot contain the reserved header alg`),
+		},
+		{
+			name:      "cose valid crit",
+			mediaType: cose.MediaTypeEnvelope,
+			envelope: coseEnvelope(gcose.ProtectedHeader{
+				gcose.HeaderLabelAlgorithm:     gcose.AlgorithmPS256,
+				gcose.HeaderLabelCritical:      []any{"io.cncf.notary.signingScheme"},
+				"io.cncf.notary.signingScheme": "notary.x509",
+			}),
+		},
+		{
+			name:      "cose duplicate crit entries",
+			mediaType: cose.MediaTypeEnvelope,
+			envelope: coseEnvelope(gcose.ProtectedHeader{
+				gcose.HeaderLabelAlgorithm:     gcose.AlgorithmPS256,
+				gcose.HeaderLabelCritical:      []any{"io.cncf.notary.signingScheme", "io.cncf.notary.signingScheme"},
+				"io.cncf.notary.signingScheme": "notary.x509",
+			}),
+			expectedErr: errors.New(`"crit" header contains duplicate entry io.cncf.notary.signingScheme`),
+		},
+		{
+			name:      "cose crit referencing reserved header",
+			mediaType: cose.MediaTypeEnvelope,
+			envelope: coseEnvelope(gcose.ProtectedHeader{
+				gcose.HeaderLabelAlgorithm: gcose.AlgorithmPS256,
+				gcose.HeaderLabelCritical:  []any{gcose.HeaderLabelAlgorithm},
+			}),
+			expectedErr: errors.New(`"crit" header cannot contain the reserved header 1`),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.mediaType, tt.envelope)
+			if !isErrEqual(tt.expectedErr, err) {
+				t.Fatalf("Parse() expects error: %v, but got: %v.", tt.expectedErr, err)
+			}
+			if err != nil {
+				var invalidSignatureErr *signature.InvalidSignatureError
+				if !errors.As(err, &invalidSignatureErr) {
+					t.Fatalf("expected *signature.InvalidSignatureError, but got %T", err)
+				}
+			}
+		})
+	}
+}
+
 func isErrEqual(wanted, got error) bool {
 	if wanted == nil && got == nil {
 		return true
@@ -139,3 +255,213 @@ func validateEnvelopeMediaType(mediaType string) error {
 	}
 	return errors.New("invalid envelope media type")
 }
+
+func TestReplaceCertificateChain(t *testing.T) {
+	leafCert := testhelper.GetRSALeafCertificate()
+	rootCert := testhelper.GetRSARootCertificate()
+	localSigner, err := signature.NewLocalSigner([]*x509.Certificate{leafCert.Cert, rootCert.Cert}, leafCert.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, mediaType := range []string{jws.MediaTypeEnvelope, cose.MediaTypeEnvelope} {
+		t.Run(mediaType, func(t *testing.T) {
+			sigEnv, err := signature.NewEnvelope(mediaType)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sig, err := sigEnv.Sign(&signature.SignRequest{
+				Payload:       signature.Payload{ContentType: MediaTypePayloadV1, Content: []byte("{}")},
+				Signer:        localSigner,
+				SigningTime:   time.Now(),
+				SigningScheme: signature.SigningSchemeX509,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// reversed certificate chain
+			replaced, err := ReplaceCertificateChain(mediaType, sig, []*x509.Certificate{rootCert.Cert, leafCert.Cert})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			parsedEnv, err := Parse(mediaType, replaced)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			certChain := parsedEnv.CertificateChain
+			if len(certChain) != 2 || !certChain[0].Equal(rootCert.Cert) || !certChain[1].Equal(leafCert.Cert) {
+				t.Fatalf("unexpected certificate chain %v", certChain)
+			}
+
+			// the signature stays valid
+			replaced, err = ReplaceCertificateChain(mediaType, replaced, []*x509.Certificate{leafCert.Cert, rootCert.Cert})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			replacedEnv, err := signature.ParseEnvelope(mediaType, replaced)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := replacedEnv.Verify(); err != nil {
+				t.Fatalf("expected signature to stay valid, but got %v", err)
+			}
+		})
+	}
+
+	t.Run("unsupported media type", func(t *testing.T) {
+		if _, err := ReplaceCertificateChain("application/unknown", nil, nil); err == nil {
+			t.Fatal("expected error for unsupported media type")
+		}
+	})
+}
+
+func TestParseCertificateChain(t *testing.T) {
+	leafCert := testhelper.GetRSALeafCertificate()
+	rootCert := testhelper.GetRSARootCertificate()
+	localSigner, err := signature.NewLocalSigner([]*x509.Certificate{leafCert.Cert, rootCert.Cert}, leafCert.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, mediaType := range []string{jws.MediaTypeEnvelope, cose.MediaTypeEnvelope} {
+		t.Run(mediaType, func(t *testing.T) {
+			sigEnv, err := signature.NewEnvelope(mediaType)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sig, err := sigEnv.Sign(&signature.SignRequest{
+				Payload:       signature.Payload{ContentType: MediaTypePayloadV1, Content: []byte("{}")},
+				Signer:        localSigner,
+				SigningTime:   time.Now(),
+				SigningScheme: signature.SigningSchemeX509,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// the certificate chain is returned in the order of the envelope
+			// without being validated
+			reversed, err := ReplaceCertificateChain(mediaType, sig, []*x509.Certificate{rootCert.Cert, leafCert.Cert})
+			if err != nil {
+				t.Fatal(err)
+			}
+			parsedEnv, err := Parse(mediaType, reversed)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			certChain := parsedEnv.CertificateChain
+			if len(certChain) != 2 || !certChain[0].Equal(rootCert.Cert) || !certChain[1].Equal(leafCert.Cert) {
+				t.Fatalf("unexpected certificate chain %v", certChain)
+			}
+		})
+	}
+
+	t.Run("malformed envelope", func(t *testing.T) {
+		if _, err := Parse(jws.MediaTypeEnvelope, []byte("malformed")); err == nil {
+			t.Fatal("expected error for malformed envelope")
+		}
+	})
+
+	t.Run("certificate chain not present", func(t *testing.T) {
+		_, err := Parse(cose.MediaTypeEnvelope, validCoseSignatureEnvelope)
+		if err == nil || err.Error() != "certificate chain is not present" {
+			t.Fatalf("expected error for missing certificate chain, but got %v", err)
+		}
+	})
+}
+
+func TestProtectedHeaderDigest(t *testing.T) {
+	leafCert := testhelper.GetRSALeafCertificate()
+	rootCert := testhelper.GetRSARootCertificate()
+	localSigner, err := signature.NewLocalSigner([]*x509.Certificate{leafCert.Cert, rootCert.Cert}, leafCert.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signingTime := time.Now().Truncate(time.Second)
+	sign := func(t *testing.T, mediaType string, expiry time.Time, signingAgent string) []byte {
+		t.Helper()
+		sigEnv, err := signature.NewEnvelope(mediaType)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig, err := sigEnv.Sign(&signature.SignRequest{
+			Payload:       signature.Payload{ContentType: MediaTypePayloadV1, Content: []byte("{}")},
+			Signer:        localSigner,
+			SigningTime:   signingTime,
+			Expiry:        expiry,
+			SigningScheme: signature.SigningSchemeX509,
+			SigningAgent:  signingAgent,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sig
+	}
+	expiry := signingTime.Add(time.Hour)
+
+	for _, mediaType := range []string{jws.MediaTypeEnvelope, cose.MediaTypeEnvelope} {
+		t.Run(mediaType, func(t *testing.T) {
+			sig := sign(t, mediaType, expiry, "agent")
+			d, err := ProtectedHeaderDigest(mediaType, sig)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := d.Validate(); err != nil || d.Algorithm() != "sha256" {
+				t.Fatalf("expected a valid sha256 digest, but got %q", d)
+			}
+
+			// the signing agent is an unprotected header
+			if other, err := ProtectedHeaderDigest(mediaType, sign(t, mediaType, expiry, "other agent")); err != nil || other != d {
+				t.Fatalf("expected digest %q to be unchanged by unprotected headers, but got %q, %v", d, other, err)
+			}
+			// the expiry is a protected header
+			if other, err := ProtectedHeaderDigest(mediaType, sign(t, mediaType, expiry.Add(time.Hour), "agent")); err != nil || other == d {
+				t.Fatalf("expected digest to change with protected headers, but got %q, %v", other, err)
+			}
+		})
+	}
+
+	t.Run("any JWS protected header changes", func(t *testing.T) {
+		sig := sign(t, jws.MediaTypeEnvelope, expiry, "agent")
+		d, err := ProtectedHeaderDigest(jws.MediaTypeEnvelope, sig)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var envelope map[string]any
+		if err := json.Unmarshal(sig, &envelope); err != nil {
+			t.Fatal(err)
+		}
+		headerBytes, err := base64.RawURLEncoding.DecodeString(envelope["protected"].(string))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var protected map[string]any
+		if err := json.Unmarshal(headerBytes, &protected); err != nil {
+			t.Fatal(err)
+		}
+		for key := range protected {
+			tampered := make(map[string]any, len(protected))
+			for k, v := range protected {
+				tampered[k] = v
+			}
+			tampered[key] = "tampered"
+			tamperedBytes, err := json.Marshal(tampered)
+			if err != nil {
+				t.Fatal(err)
+			}
+			envelope["protected"] = base64.RawURLEncoding.EncodeToString(tamperedBytes)
+			tamperedSig, err := json.Marshal(envelope)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if other, err := ProtectedHeaderDigest(jws.MediaTypeEnvelope, tamperedSig); err != nil || other == d {
+				t.Errorf("expected digest to change when protected header %q changes, but got %q, %v", key, other, err)
+			}
+		}
+	})
+
+	t.Run("unsupported media type", func(t *testing.T) {
+		if _, err := ProtectedHeaderDigest("application/unknown", []byte("{}")); err == nil {
+			t.Fatal("expected error for unsupported media type")
+		}
+	})
+}
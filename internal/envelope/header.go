@@ -0,0 +1,58 @@
+// Copyright The Notary Project Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package envelope
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/notaryproject/notation-core-go/signature"
+	"github.com/opencontainers/go-digest"
+	"github.com/veraison/go-cose"
+)
+
+// ProtectedHeaderDigest returns the SHA-256 digest of the protected header
+// of the signature envelope blob, as encoded in the envelope and covered by
+// the signature. The digest is stable across systems since the encoding of
+// the protected header cannot change without invalidating the signature.
+func ProtectedHeaderDigest(envelopeMediaType string, envelopeBytes []byte) (digest.Digest, error) {
+	var protected []byte
+	switch envelopeMediaType {
+	case mediaTypeJWSEnvelope:
+		var envelope struct {
+			Protected string `json:"protected"`
+		}
+		if err := json.Unmarshal(envelopeBytes, &envelope); err != nil {
+			return "", &signature.InvalidSignatureError{Msg: fmt.Sprintf("malformed JWS envelope: %v", err)}
+		}
+		var err error
+		protected, err = base64.RawURLEncoding.DecodeString(envelope.Protected)
+		if err != nil {
+			return "", &signature.InvalidSignatureError{Msg: fmt.Sprintf("malformed JWS protected header: %v", err)}
+		}
+	case mediaTypeCOSEEnvelope:
+		var msg cose.Sign1Message
+		if err := msg.UnmarshalCBOR(envelopeBytes); err != nil {
+			return "", &signature.InvalidSignatureError{Msg: fmt.Sprintf("malformed COSE envelope: %v", err)}
+		}
+		protected = msg.Headers.RawProtected
+	default:
+		return "", &signature.UnsupportedSignatureFormatError{MediaType: envelopeMediaType}
+	}
+	if len(protected) == 0 {
+		return "", &signature.InvalidSignatureError{Msg: "protected header is not present"}
+	}
+	return digest.SHA256.FromBytes(protected), nil
+}
@@ -20,7 +20,8 @@ import (
 	ldapv3 "github.com/go-ldap/ldap/v3"
 )
 
-// ParseDistinguishedName parses a DN name and validates Notary Project rules
+// ParseDistinguishedName parses a DN name and validates Notary Project rules.
+// The attribute types of the returned map are uppercased.
 func ParseDistinguishedName(name string) (map[string]string, error) {
 	if strings.Contains(name, "=#") {
 		return nil, fmt.Errorf("unsupported distinguished name (DN) %q: notation does not support x509.subject identities containing \"=#\"", name)
@@ -33,17 +34,27 @@ func ParseDistinguishedName(name string) (map[string]string, error) {
 		return nil, fmt.Errorf("parsing distinguished name (DN) %q failed with err: %v. A valid DN must contain 'C', 'ST', and 'O' RDN attributes at a minimum, and follow RFC 4514 standard", name, err)
 	}
 
+	// the DN parser drops the last RDN attribute if its value is empty
+	if strings.HasSuffix(name, "=") && !strings.HasSuffix(name, `\=`) {
+		attributeType := strings.TrimSpace(name[strings.LastIndexAny(name, ",+")+1 : len(name)-1])
+		return nil, fmt.Errorf("distinguished name (DN) %q has an empty value for RDN attribute %q, RDN attributes must have a value", name, attributeType)
+	}
+
 	for _, rdn := range dn.RDNs {
 		// multi-valued RDNs are not supported (TODO: add spec reference here)
 		if len(rdn.Attributes) > 1 {
 			return nil, fmt.Errorf("distinguished name (DN) %q has multi-valued RDN attributes, remove multi-valued RDN attributes as they are not supported", name)
 		}
 		for _, attribute := range rdn.Attributes {
-			if attrKeyValue[attribute.Type] == "" {
-				attrKeyValue[attribute.Type] = attribute.Value
-			} else {
+			// attribute types are case-insensitive, see RFC 4514 section 3
+			attribute.Type = strings.ToUpper(attribute.Type)
+			if attribute.Value == "" {
+				return nil, fmt.Errorf("distinguished name (DN) %q has an empty value for RDN attribute %q, RDN attributes must have a value", name, attribute.Type)
+			}
+			if _, ok := attrKeyValue[attribute.Type]; ok {
 				return nil, fmt.Errorf("distinguished name (DN) %q has duplicate RDN attribute for %q, DN can only have unique RDN attributes", name, attribute.Type)
 			}
+			attrKeyValue[attribute.Type] = attribute.Value
 		}
 	}
 
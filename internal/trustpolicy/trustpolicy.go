@@ -16,4 +16,8 @@ package trustpolicy
 const (
 	Wildcard    = "*"
 	X509Subject = "x509.subject"
+
+	// X509FingerprintSHA256 is the prefix of trusted identities pinning the
+	// signing certificate by its SHA-256 fingerprint
+	X509FingerprintSHA256 = "x509.fingerprint.sha256"
 )
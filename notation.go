@@ -18,6 +18,7 @@ package notation
 import (
 	"context"
 	"crypto/sha256"
+	"crypto/x509"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
@@ -214,6 +215,48 @@ type VerificationOutcome struct {
 
 	// Error that caused the verification to fail (if it fails)
 	Error error
+
+	// Warnings contains the issues found during verification that do not
+	// fail the verification, e.g. a signing certificate nearing its expiry
+	Warnings []error
+
+	// TimestampExtendedValidity is true if the certificate chain of the
+	// signature is not valid at the time of verification and the signature
+	// passed the authentic timestamp validation only because of its
+	// validated timestamp, i.e. the authentic signing time of the
+	// notary.x509.signingAuthority signing scheme, or the RFC 3161 timestamp
+	// countersignature of the notary.x509 signing scheme verified against
+	// the "tsa" trust stores of the trust policy. Timestamp
+	// countersignatures are not verified if the trust policy has no "tsa"
+	// trust store, so they never extend the validity.
+	TimestampExtendedValidity bool
+
+	// Durations contains the time spent on each validation type that was
+	// performed, including failed validations. The time spent by a
+	// verification plugin is not included.
+	Durations map[trustpolicy.ValidationType]time.Duration
+
+	// ForcedPolicyStatement is the name of the trust policy statement that
+	// was forced for verification, bypassing registry scope matching. It is
+	// empty if no trust policy statement was forced.
+	ForcedPolicyStatement string
+
+	// PolicyStatement is the name of the trust policy statement applied to
+	// the signature.
+	PolicyStatement string
+
+	// SignerGroups are the names of the required signer groups of the trust
+	// policy statement that the signature satisfies. It is empty if the
+	// statement does not require signer groups.
+	SignerGroups []string
+
+	// SignerIdentity is the trusted identity in the x509.subject format of
+	// the signing certificate, e.g. "x509.subject:CN=wabbit-networks.io".
+	// It can be added to the trustedIdentities of a trust policy statement
+	// to pin the signer. It is only set if the certificate chain is trusted
+	// and the subject of the signing certificate is a valid x509.subject
+	// identity value, e.g. it has the mandatory C, ST and O attributes.
+	SignerIdentity string
 }
 
 func (outcome *VerificationOutcome) UserMetadata() (map[string]string, error) {
@@ -251,6 +294,16 @@ type VerifierVerifyOptions struct {
 	// UserMetadata contains key-value pairs that must be present in the
 	// signature.
 	UserMetadata map[string]string
+
+	// ForcePolicyStatement is the name of the trust policy statement to be
+	// used for verification regardless of its registry scopes.
+	ForcePolicyStatement string
+
+	// CertificateChain is the certificate chain obtained out-of-band, with
+	// the leaf certificate first. If set, it is used instead of the
+	// certificate chain embedded in the signature envelope, and the leaf
+	// certificate must hold the key that produced the signature.
+	CertificateChain []*x509.Certificate
 }
 
 // Verifier is a generic interface for verifying an artifact.
@@ -268,6 +321,12 @@ type verifySkipper interface {
 	SkipVerify(ctx context.Context, opts VerifierVerifyOptions) (bool, *trustpolicy.VerificationLevel, error)
 }
 
+type signerGroupsRequirer interface {
+	// RequiredSignerGroups returns the names of the signer groups that must
+	// each be satisfied by at least one signature of the artifact.
+	RequiredSignerGroups(ctx context.Context, opts VerifierVerifyOptions) ([]string, error)
+}
+
 // VerifyOptions contains parameters for notation.Verify.
 type VerifyOptions struct {
 	// ArtifactReference is the reference of the artifact that is been
@@ -285,6 +344,20 @@ type VerifyOptions struct {
 	// UserMetadata contains key-value pairs that must be present in the
 	// signature
 	UserMetadata map[string]string
+
+	// ForcePolicyStatement is the name of the trust policy statement to be
+	// used for verification, bypassing the registry scope matching. It is
+	// meant for operators that need to enforce a specific statement for an
+	// artifact, e.g. during an incident. The forced statement must exist and
+	// must not skip signature verification.
+	ForcePolicyStatement string
+
+	// EnforceSignatureManifestAnnotations fails the verification of a
+	// signature if the signing time recorded in its signature manifest
+	// annotations contradicts the signing time of the verified signature.
+	// If not set, the contradiction is logged and recorded as a warning in
+	// the verification outcome.
+	EnforceSignatureManifestAnnotations bool
 }
 
 // Verify performs signature verification on each of the notation supported
@@ -308,9 +381,10 @@ func Verify(ctx context.Context, verifier Verifier, repo registry.Repository, ve
 
 	// opts to be passed in verifier.Verify()
 	opts := VerifierVerifyOptions{
-		ArtifactReference: verifyOpts.ArtifactReference,
-		PluginConfig:      verifyOpts.PluginConfig,
-		UserMetadata:      verifyOpts.UserMetadata,
+		ArtifactReference:    verifyOpts.ArtifactReference,
+		PluginConfig:         verifyOpts.PluginConfig,
+		UserMetadata:         verifyOpts.UserMetadata,
+		ForcePolicyStatement: verifyOpts.ForcePolicyStatement,
 	}
 
 	if skipChecker, ok := verifier.(verifySkipper); ok {
@@ -326,6 +400,16 @@ func Verify(ctx context.Context, verifier Verifier, repo registry.Repository, ve
 		logger.Info("Check over. Trust policy is not configured to skip signature verification")
 	}
 
+	var requiredSignerGroups []string
+	if groupsRequirer, ok := verifier.(signerGroupsRequirer); ok {
+		var err error
+		requiredSignerGroups, err = groupsRequirer.RequiredSignerGroups(ctx, opts)
+		if err != nil {
+			return ocispec.Descriptor{}, nil, err
+		}
+	}
+	satisfiedSignerGroups := make(map[string]bool)
+
 	// get artifact descriptor
 	artifactRef := verifyOpts.ArtifactReference
 	ref, err := orasRegistry.ParseReference(artifactRef)
@@ -372,8 +456,25 @@ func Verify(ctx context.Context, verifier Verifier, repo registry.Repository, ve
 			// using signature media type fetched from registry
 			opts.SignatureMediaType = sigDesc.MediaType
 
+			// resolve the artifact digest with the digest algorithm used by
+			// the signature. The parsed envelope is passed to the verifier
+			// so that it is not parsed again.
+			verifyCtx, targetDesc := ctx, artifactDescriptor
+			if resolver, ok := repo.(registry.DigestResolver); ok {
+				// malformed signatures are reported by the verifier
+				if sigEnv, err := envelope.Parse(opts.SignatureMediaType, sigBlob); err == nil {
+					verifyCtx = envelope.WithParsedEnvelope(ctx, opts.SignatureMediaType, sigBlob, sigEnv)
+					targetDesc, err = resolveSignedDigest(ctx, resolver, artifactDescriptor, sigEnv)
+					if err != nil {
+						logger.Warnf("Signature %v failed verification with error: %v", sigManifestDesc.Digest, err)
+						verificationFailedErrorArray = append(verificationFailedErrorArray, fmt.Errorf("failed to verify signature with digest %v, unable to resolve the digest of %q signed by the signature, error : %w", sigManifestDesc.Digest, artifactRef, err))
+						continue
+					}
+				}
+			}
+
 			// verify each signature
-			outcome, err := verifier.Verify(ctx, artifactDescriptor, sigBlob, opts)
+			outcome, err := verifier.Verify(verifyCtx, targetDesc, sigBlob, opts)
 			if err != nil {
 				logger.Warnf("Signature %v failed verification with error: %v", sigManifestDesc.Digest, err)
 				if outcome == nil {
@@ -384,6 +485,33 @@ func Verify(ctx context.Context, verifier Verifier, repo registry.Repository, ve
 				verificationFailedErrorArray = append(verificationFailedErrorArray, outcome.Error)
 				continue
 			}
+			// verify the signature manifest annotations against the verified
+			// signature
+			if err := verifySignatureManifestAnnotations(sigManifestDesc.Annotations, outcome); err != nil {
+				if verifyOpts.EnforceSignatureManifestAnnotations {
+					logger.Warnf("Signature %v failed verification with error: %v", sigManifestDesc.Digest, err)
+					outcome.Error = fmt.Errorf("failed to verify signature with digest %v, %w", sigManifestDesc.Digest, err)
+					verificationFailedErrorArray = append(verificationFailedErrorArray, outcome.Error)
+					continue
+				}
+				logger.Warnf("Signature manifest annotations of signature %v are not consistent with the signature: %v", sigManifestDesc.Digest, err)
+				outcome.Warnings = append(outcome.Warnings, fmt.Errorf("signature manifest annotations of signature %v are not consistent with the signature, %w", sigManifestDesc.Digest, err))
+			}
+			if len(requiredSignerGroups) > 0 {
+				// keep verifying signatures until every required signer
+				// group is satisfied
+				verificationOutcomes = append(verificationOutcomes, outcome)
+				for _, group := range outcome.SignerGroups {
+					satisfiedSignerGroups[group] = true
+				}
+				if len(missingSignerGroups(requiredSignerGroups, satisfiedSignerGroups)) > 0 {
+					logger.Debugf("Signature %v satisfies signer groups %v", sigManifestDesc.Digest, outcome.SignerGroups)
+					continue
+				}
+				verificationSucceeded = true
+				logger.Debugf("Signature verification succeeded for artifact %v with all required signer groups satisfied", artifactDescriptor.Digest)
+				return errDoneVerification
+			}
 			// at this point, the signature is verified successfully
 			verificationSucceeded = true
 			// on success, verificationOutcomes only contains the
@@ -415,6 +543,10 @@ func Verify(ctx context.Context, verifier Verifier, repo registry.Repository, ve
 	}
 
 	// Verification Failed
+	if missing := missingSignerGroups(requiredSignerGroups, satisfiedSignerGroups); len(missing) > 0 {
+		logger.Debugf("Signatures of artifact %v do not satisfy the required signer groups %v", artifactDescriptor.Digest, missing)
+		verificationFailedErrorArray[0] = ErrorVerificationFailed{Msg: fmt.Sprintf("signature verification failed, no valid signature is found for the required signer groups %q", missing)}
+	}
 	if !verificationSucceeded {
 		logger.Debugf("Signature verification failed for all the signatures associated with artifact %v", artifactDescriptor.Digest)
 		return ocispec.Descriptor{}, verificationOutcomes, errors.Join(verificationFailedErrorArray...)
@@ -424,6 +556,113 @@ func Verify(ctx context.Context, verifier Verifier, repo registry.Repository, ve
 	return artifactDescriptor, verificationOutcomes, nil
 }
 
+// resolveSignedDigest returns the artifact descriptor with its digest
+// computed using the digest algorithm of the target artifact signed by the
+// parsed signature envelope, if it differs from the resolved one. Otherwise,
+// the artifact descriptor is returned as is, and the verifier reports the
+// mismatch.
+func resolveSignedDigest(ctx context.Context, resolver registry.DigestResolver, artifactDesc ocispec.Descriptor, sigEnv *envelope.Envelope) (ocispec.Descriptor, error) {
+	envContent, err := sigEnv.Content()
+	if err != nil {
+		// malformed signatures are reported by the verifier
+		return artifactDesc, nil
+	}
+	targetArtifact, err := envelope.TargetArtifact(envContent)
+	if err != nil {
+		// malformed signatures are reported by the verifier
+		return artifactDesc, nil
+	}
+	alg := targetArtifact.Digest.Algorithm()
+	if alg == artifactDesc.Digest.Algorithm() || !alg.Available() {
+		return artifactDesc, nil
+	}
+	log.GetLogger(ctx).Debugf("Resolving digest of artifact %v with the digest algorithm %v of the signature", artifactDesc.Digest, alg)
+	return resolver.ResolveDigest(ctx, artifactDesc, alg)
+}
+
+// missingSignerGroups returns the required signer groups that are not
+// satisfied.
+func missingSignerGroups(required []string, satisfied map[string]bool) []string {
+	var missing []string
+	for _, group := range required {
+		if !satisfied[group] {
+			missing = append(missing, group)
+		}
+	}
+	return missing
+}
+
+// CompareVerification verifies each signature envelope blob in `signatures`
+// against the target OCI artifact with manifest descriptor `desc` using both
+// vOld and vNew, and returns the outcomes of each verifier in the order of
+// `signatures`.
+// It is meant for safe rollouts of trust policy or verifier changes, where
+// the outcomes of the current and the proposed verifier are compared before
+// switching over. Verification failures are recorded in the outcomes, an
+// error is only returned if a verifier fails to produce an outcome.
+func CompareVerification(ctx context.Context, vOld, vNew Verifier, desc ocispec.Descriptor, signatures [][]byte, opts VerifierVerifyOptions) (oldOutcomes, newOutcomes []*VerificationOutcome, err error) {
+	// sanity check
+	if vOld == nil || vNew == nil {
+		return nil, nil, errors.New("verifiers cannot be nil")
+	}
+	if len(signatures) == 0 {
+		return nil, nil, errors.New("signatures cannot be empty")
+	}
+
+	logger := log.GetLogger(ctx)
+	verify := func(v Verifier, sig []byte) (*VerificationOutcome, error) {
+		outcome, err := v.Verify(ctx, desc, sig, opts)
+		if outcome == nil {
+			return nil, err
+		}
+		if err != nil && outcome.Error == nil {
+			// make sure the failure is recorded in the outcome
+			outcome.Error = err
+		}
+		return outcome, nil
+	}
+	for i, sig := range signatures {
+		oldOutcome, err := verify(vOld, sig)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to verify signature #%d with the old verifier: %w", i+1, err)
+		}
+		newOutcome, err := verify(vNew, sig)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to verify signature #%d with the new verifier: %w", i+1, err)
+		}
+		if (oldOutcome.Error == nil) != (newOutcome.Error == nil) {
+			logger.Warnf("Verification outcomes of signature #%d differ, old verifier error: %v, new verifier error: %v", i+1, oldOutcome.Error, newOutcome.Error)
+		}
+		oldOutcomes = append(oldOutcomes, oldOutcome)
+		newOutcomes = append(newOutcomes, newOutcome)
+	}
+	return oldOutcomes, newOutcomes, nil
+}
+
+// verifySignatureManifestAnnotations verifies that the signing time recorded
+// in the signature manifest annotations does not contradict the signing time
+// of the verified signature. Annotations without a signing time record are
+// not checked.
+func verifySignatureManifestAnnotations(annotations map[string]string, outcome *VerificationOutcome) error {
+	created, ok := annotations[ocispec.AnnotationCreated]
+	if !ok || outcome == nil || outcome.EnvelopeContent == nil {
+		return nil
+	}
+	annotatedTime, err := time.Parse(time.RFC3339, created)
+	if err != nil {
+		return ErrorVerificationFailed{Msg: fmt.Sprintf("signature manifest annotation %q has malformed value %q", ocispec.AnnotationCreated, created)}
+	}
+	signingTime, err := envelope.SigningTime(&outcome.EnvelopeContent.SignerInfo)
+	if err != nil {
+		return ErrorVerificationFailed{Msg: err.Error()}
+	}
+	// the annotation is recorded with the granularity of seconds
+	if diff := annotatedTime.Sub(signingTime); diff >= time.Second || diff <= -time.Second {
+		return ErrorVerificationFailed{Msg: fmt.Sprintf("signature manifest annotation %q claims signing time %q, but the signature was signed at %q", ocispec.AnnotationCreated, created, signingTime.Format(time.RFC3339))}
+	}
+	return nil
+}
+
 func generateAnnotations(signerInfo *signature.SignerInfo, annotations map[string]string) (map[string]string, error) {
 	// sanity check
 	if signerInfo == nil {
@@ -449,3 +688,12 @@ func generateAnnotations(signerInfo *signature.SignerInfo, annotations map[strin
 	annotations[ocispec.AnnotationCreated] = signingTime.Format(time.RFC3339)
 	return annotations, nil
 }
+
+// ProtectedHeaderDigest returns the SHA-256 digest of the protected header of
+// the signature envelope blob `signature` with media type
+// `signatureMediaType`. The protected header is covered by the signature, so
+// its digest is stable and can be logged and compared across systems for
+// forensic correlation. It does not verify the signature.
+func ProtectedHeaderDigest(signatureMediaType string, signature []byte) (digest.Digest, error) {
+	return envelope.ProtectedHeaderDigest(signatureMediaType, signature)
+}
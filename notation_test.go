@@ -15,21 +15,34 @@ package notation
 
 import (
 	"context"
+	"crypto/x509"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/notaryproject/notation-core-go/signature"
 	"github.com/notaryproject/notation-core-go/signature/cose"
+	"github.com/notaryproject/notation-core-go/signature/jws"
+	"github.com/notaryproject/notation-core-go/testhelper"
+	"github.com/notaryproject/notation-go/internal/envelope"
 	"github.com/notaryproject/notation-go/internal/mock"
+	"github.com/notaryproject/notation-go/internal/slices"
 	"github.com/notaryproject/notation-go/plugin"
+	"github.com/notaryproject/notation-go/plugin/proto"
 	"github.com/notaryproject/notation-go/registry"
 	"github.com/notaryproject/notation-go/verifier/trustpolicy"
+	"github.com/opencontainers/go-digest"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
 )
 
 var expectedMetadata = map[string]string{"foo": "bar", "bar": "foo"}
@@ -301,6 +314,89 @@ func TestVerifyFailed(t *testing.T) {
 	}
 }
 
+func TestVerifySignatureManifestAnnotations(t *testing.T) {
+	signingTime := time.Date(2023, 3, 14, 4, 45, 22, 0, time.UTC)
+	verifier := signingTimeVerifier{signingTime: signingTime}
+	testCases := []struct {
+		name        string
+		created     string
+		enforce     bool
+		wantErr     bool
+		wantWarning bool
+	}{
+		{"consistent", "2023-03-14T04:45:22Z", true, false, false},
+		{"consistentWithTimeZone", "2023-03-14T12:45:22+08:00", true, false, false},
+		{"consistentNotEnforced", "2023-03-14T04:45:22Z", false, false, false},
+		{"contradictoryWarned", "2023-03-15T04:45:22Z", false, false, true},
+		{"malformedWarned", "not a time", false, false, true},
+		{"contradictoryEnforced", "2023-03-15T04:45:22Z", true, true, false},
+		{"malformedEnforced", "not a time", true, true, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := mock.NewRepository()
+			sigManifestDesc := mock.SigManfiestDescriptor
+			sigManifestDesc.Annotations = map[string]string{ocispec.AnnotationCreated: tc.created}
+			repo.ListSignaturesResponse = []ocispec.Descriptor{sigManifestDesc}
+			opts := VerifyOptions{
+				ArtifactReference:                   mock.SampleArtifactUri,
+				MaxSignatureAttempts:                50,
+				EnforceSignatureManifestAnnotations: tc.enforce,
+			}
+			_, outcomes, err := Verify(context.Background(), &verifier, repo, opts)
+			if tc.wantErr {
+				if err == nil || !errors.Is(err, ErrorVerificationFailed{}) {
+					t.Fatalf("expected ErrorVerificationFailed, got: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if len(outcomes) != 1 {
+				t.Fatalf("expected one outcome, got: %d", len(outcomes))
+			}
+			if gotWarning := len(outcomes[0].Warnings) > 0; gotWarning != tc.wantWarning {
+				t.Fatalf("expected warning: %v, got warnings: %v", tc.wantWarning, outcomes[0].Warnings)
+			}
+			if tc.wantWarning && !errors.As(outcomes[0].Warnings[0], &ErrorVerificationFailed{}) {
+				t.Fatalf("expected the warning to wrap ErrorVerificationFailed, got: %v", outcomes[0].Warnings[0])
+			}
+		})
+	}
+}
+
+func TestCompareVerification(t *testing.T) {
+	policyDocument := dummyPolicyDocument()
+	vOld := &dummyVerifier{&policyDocument, mock.PluginManager{}, false, *trustpolicy.LevelPermissive}
+	vNew := &dummyVerifier{&policyDocument, mock.PluginManager{}, true, *trustpolicy.LevelStrict}
+	opts := VerifierVerifyOptions{ArtifactReference: mock.SampleArtifactUri, SignatureMediaType: "application/jose+json"}
+	signatures := [][]byte{mock.MockCaValidSigEnv, mock.MockSaValidSigEnv}
+
+	oldOutcomes, newOutcomes, err := CompareVerification(context.Background(), vOld, vNew, mock.ImageDescriptor, signatures, opts)
+	if err != nil {
+		t.Fatalf("CompareVerification failed with error: %v", err)
+	}
+	if len(oldOutcomes) != len(signatures) || len(newOutcomes) != len(signatures) {
+		t.Fatalf("expected %d outcomes per verifier, got %d and %d", len(signatures), len(oldOutcomes), len(newOutcomes))
+	}
+	for i := range signatures {
+		if oldOutcomes[i].Error != nil || oldOutcomes[i].VerificationLevel.Name != trustpolicy.LevelPermissive.Name {
+			t.Fatalf("expected old outcome #%d to pass with permissive level, got: %+v", i, oldOutcomes[i])
+		}
+		if newOutcomes[i].Error == nil || newOutcomes[i].VerificationLevel.Name != trustpolicy.LevelStrict.Name {
+			t.Fatalf("expected new outcome #%d to fail with strict level, got: %+v", i, newOutcomes[i])
+		}
+	}
+
+	if _, _, err := CompareVerification(context.Background(), vOld, nil, mock.ImageDescriptor, signatures, opts); err == nil {
+		t.Fatalf("expected error for nil verifier")
+	}
+	if _, _, err := CompareVerification(context.Background(), vOld, vNew, mock.ImageDescriptor, nil, opts); err == nil {
+		t.Fatalf("expected error for empty signatures")
+	}
+}
+
 func dummyPolicyDocument() (policyDoc trustpolicy.Document) {
 	policyDoc = trustpolicy.Document{
 		Version:       "1.0",
@@ -363,6 +459,23 @@ func (v *dummyVerifier) Verify(ctx context.Context, desc ocispec.Descriptor, sig
 	return outcome, nil
 }
 
+type signingTimeVerifier struct {
+	signingTime time.Time
+}
+
+func (v *signingTimeVerifier) Verify(ctx context.Context, desc ocispec.Descriptor, sig []byte, opts VerifierVerifyOptions) (*VerificationOutcome, error) {
+	return &VerificationOutcome{
+		EnvelopeContent: &signature.EnvelopeContent{
+			SignerInfo: signature.SignerInfo{
+				SignedAttributes: signature.SignedAttributes{
+					SigningTime: v.signingTime,
+				},
+			},
+		},
+		VerificationLevel: trustpolicy.LevelStrict,
+	}, nil
+}
+
 var (
 	ociLayoutPath     = filepath.FromSlash("./internal/testdata/oci-layout")
 	reference         = "sha256:19dbd2e48e921426ee8ace4dc892edfb2ecdc1d1a72d5416c83670c30acecef0"
@@ -422,3 +535,399 @@ func TestVerifyLocalContent(t *testing.T) {
 		t.Fatalf("failed to verify local content: %v", err)
 	}
 }
+
+func TestTraceTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	t.Run("trace from context", func(t *testing.T) {
+		trace := &NetworkTrace{}
+		client := &http.Client{Transport: &TraceTransport{}}
+		req, err := http.NewRequestWithContext(WithNetworkTrace(context.Background(), trace), http.MethodGet, server.URL+"/ocsp", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		resp.Body.Close()
+
+		requests := trace.Requests()
+		if len(requests) != 1 {
+			t.Fatalf("expected 1 recorded request, but got %d", len(requests))
+		}
+		if requests[0].URL != server.URL+"/ocsp" || requests[0].Method != http.MethodGet || requests[0].StatusCode != http.StatusNotFound || requests[0].Error != nil {
+			t.Fatalf("unexpected recorded request: %+v", requests[0])
+		}
+	})
+
+	t.Run("failed request", func(t *testing.T) {
+		trace := &NetworkTrace{}
+		client := &http.Client{Transport: &TraceTransport{Trace: trace}}
+		if _, err := client.Get("http://127.0.0.1:0/crl"); err == nil {
+			t.Fatal("expected request to fail")
+		}
+		requests := trace.Requests()
+		if len(requests) != 1 || requests[0].Error == nil || requests[0].StatusCode != 0 {
+			t.Fatalf("expected failed request to be recorded, but got %+v", requests)
+		}
+	})
+
+	t.Run("no trace", func(t *testing.T) {
+		client := &http.Client{Transport: &TraceTransport{}}
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		resp.Body.Close()
+	})
+}
+
+func TestGetCapabilities(t *testing.T) {
+	capabilities := GetCapabilities()
+	for _, mediaType := range []string{jws.MediaTypeEnvelope, cose.MediaTypeEnvelope} {
+		if !slices.Contains(capabilities.EnvelopeMediaTypes, mediaType) {
+			t.Errorf("expected envelope media type %q to be supported, but got %v", mediaType, capabilities.EnvelopeMediaTypes)
+		}
+	}
+	if !slices.Contains(capabilities.RevocationMethods, "ocsp") {
+		t.Errorf("expected OCSP to be supported, but got %v", capabilities.RevocationMethods)
+	}
+	for _, scheme := range []signature.SigningScheme{signature.SigningSchemeX509, signature.SigningSchemeX509SigningAuthority} {
+		if !slices.Contains(capabilities.SigningSchemes, scheme) {
+			t.Errorf("expected signing scheme %q to be supported, but got %v", scheme, capabilities.SigningSchemes)
+		}
+	}
+	if !slices.Contains(capabilities.PluginContractVersions, proto.ContractVersion) {
+		t.Errorf("expected plugin contract version %q to be supported, but got %v", proto.ContractVersion, capabilities.PluginContractVersions)
+	}
+}
+
+// scopedVerifier is a dummyVerifier skipping verification of the artifacts
+// in skippedReferences.
+type scopedVerifier struct {
+	dummyVerifier
+	skippedReferences []string
+}
+
+func (v *scopedVerifier) SkipVerify(ctx context.Context, opts VerifierVerifyOptions) (bool, *trustpolicy.VerificationLevel, error) {
+	if slices.Contains(v.skippedReferences, opts.ArtifactReference) {
+		return true, trustpolicy.LevelSkip, nil
+	}
+	return false, &v.VerificationLevel, nil
+}
+
+func TestAdmissionDecision(t *testing.T) {
+	policyDocument := dummyPolicyDocument()
+	skippedReference := "registry.acme-rockets.io/software/skipped@" + mock.SampleDigest.String()
+	verifier := &scopedVerifier{
+		dummyVerifier:     dummyVerifier{&policyDocument, mock.PluginManager{}, false, *trustpolicy.LevelStrict},
+		skippedReferences: []string{skippedReference},
+	}
+	signedImage := AdmissionImage{
+		Repository:    mock.NewRepository(),
+		VerifyOptions: VerifyOptions{ArtifactReference: mock.SampleArtifactUri, MaxSignatureAttempts: 50},
+	}
+	unsignedRepo := mock.NewRepository()
+	unsignedRepo.ListSignaturesResponse = []ocispec.Descriptor{}
+	unsignedImage := AdmissionImage{
+		Repository:    unsignedRepo,
+		VerifyOptions: VerifyOptions{ArtifactReference: mock.SampleArtifactUri, MaxSignatureAttempts: 50},
+	}
+	skippedImage := AdmissionImage{
+		Repository:    unsignedRepo,
+		VerifyOptions: VerifyOptions{ArtifactReference: skippedReference, MaxSignatureAttempts: 50},
+	}
+
+	t.Run("signed and skip-scoped images are allowed", func(t *testing.T) {
+		allowed, denials, err := AdmissionDecision(context.Background(), verifier, []AdmissionImage{signedImage, skippedImage})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !allowed || len(denials) != 0 {
+			t.Fatalf("expected images to be allowed, but got denials %+v", denials)
+		}
+	})
+
+	t.Run("unsigned image is denied", func(t *testing.T) {
+		allowed, denials, err := AdmissionDecision(context.Background(), verifier, []AdmissionImage{signedImage, unsignedImage, skippedImage})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if allowed {
+			t.Fatal("expected images to be denied")
+		}
+		if len(denials) != 1 || denials[0].ArtifactReference != mock.SampleArtifactUri {
+			t.Fatalf("expected a denial of the unsigned image, but got %+v", denials)
+		}
+		var errRetrieval ErrorSignatureRetrievalFailed
+		if !errors.As(denials[0].Error, &errRetrieval) || denials[0].Reason != denials[0].Error.Error() {
+			t.Fatalf("expected signature retrieval failure, but got %+v", denials[0])
+		}
+	})
+
+	t.Run("context canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, _, err := AdmissionDecision(ctx, verifier, []AdmissionImage{signedImage}); !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context canceled error, but got %v", err)
+		}
+	})
+}
+
+func TestToAttestation(t *testing.T) {
+	sigEnv, err := signature.ParseEnvelope(jws.MediaTypeEnvelope, mock.MockCaValidSigEnv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	envContent, err := sigEnv.Verify()
+	if err != nil {
+		t.Fatal(err)
+	}
+	outcome := &VerificationOutcome{
+		EnvelopeContent:   envContent,
+		VerificationLevel: trustpolicy.LevelStrict,
+		VerificationResults: []*ValidationResult{
+			{Type: trustpolicy.TypeIntegrity, Action: trustpolicy.ActionEnforce},
+			{Type: trustpolicy.TypeExpiry, Action: trustpolicy.ActionLog, Error: errors.New("digital signature has expired")},
+		},
+		PolicyStatement: "test-statement",
+		SignerIdentity:  "x509.subject:CN=Notation Test Root,O=Notary,L=Seattle,ST=WA,C=US",
+	}
+
+	attestation, err := outcome.ToAttestation(mock.ImageDescriptor)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var statement InTotoStatement
+	if err := json.Unmarshal(attestation, &statement); err != nil {
+		t.Fatal(err)
+	}
+	if statement.Type != InTotoStatementType || statement.PredicateType != VerificationPredicateType {
+		t.Fatalf("unexpected statement type %q and predicate type %q", statement.Type, statement.PredicateType)
+	}
+	wantSubject := []InTotoSubject{{
+		Name:   mock.ImageDescriptor.Digest.String(),
+		Digest: map[string]string{"sha256": mock.ImageDescriptor.Digest.Encoded()},
+	}}
+	if !reflect.DeepEqual(statement.Subject, wantSubject) {
+		t.Fatalf("expected subject %v, but got %v", wantSubject, statement.Subject)
+	}
+	predicate := statement.Predicate
+	if !predicate.Verified || predicate.PolicyStatement != "test-statement" || predicate.VerificationLevel != "strict" || predicate.SignerIdentity != outcome.SignerIdentity {
+		t.Fatalf("unexpected predicate %+v", predicate)
+	}
+	if predicate.TimeVerified.IsZero() {
+		t.Fatal("expected verification time to be set")
+	}
+	wantResults := []VerificationPredicateResult{
+		{Type: "integrity", Action: "enforce"},
+		{Type: "expiry", Action: "log", Error: "digital signature has expired"},
+	}
+	if !reflect.DeepEqual(predicate.Results, wantResults) {
+		t.Fatalf("expected results %v, but got %v", wantResults, predicate.Results)
+	}
+
+	t.Run("failed verification", func(t *testing.T) {
+		failedOutcome := *outcome
+		failedOutcome.Error = errors.New("signature verification failed")
+		attestation, err := failedOutcome.ToAttestation(mock.ImageDescriptor)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var statement InTotoStatement
+		if err := json.Unmarshal(attestation, &statement); err != nil {
+			t.Fatal(err)
+		}
+		if statement.Predicate.Verified || statement.Predicate.Error != "signature verification failed" {
+			t.Fatalf("unexpected predicate %+v", statement.Predicate)
+		}
+	})
+
+	t.Run("subject mismatch", func(t *testing.T) {
+		subject := mock.ImageDescriptor
+		subject.Digest = mock.ZeroDigest
+		if _, err := outcome.ToAttestation(subject); err == nil {
+			t.Fatal("expected error for mismatched subject")
+		}
+	})
+
+	t.Run("missing envelope content", func(t *testing.T) {
+		if _, err := (&VerificationOutcome{}).ToAttestation(mock.ImageDescriptor); err == nil {
+			t.Fatal("expected error for missing envelope content")
+		}
+	})
+}
+
+// signatureRepository serves the signatures, in order, for the artifacts of
+// the wrapped repository.
+type signatureRepository struct {
+	registry.Repository
+	sigBlobs [][]byte
+}
+
+func (r *signatureRepository) ListSignatures(ctx context.Context, desc ocispec.Descriptor, fn func(signatureManifests []ocispec.Descriptor) error) error {
+	var signatureManifests []ocispec.Descriptor
+	for _, sigBlob := range r.sigBlobs {
+		signatureManifests = append(signatureManifests, ocispec.Descriptor{MediaType: ocispec.MediaTypeImageManifest, Digest: digest.FromBytes(sigBlob)})
+	}
+	return fn(signatureManifests)
+}
+
+func (r *signatureRepository) FetchSignatureBlob(ctx context.Context, desc ocispec.Descriptor) ([]byte, ocispec.Descriptor, error) {
+	for _, sigBlob := range r.sigBlobs {
+		if digest.FromBytes(sigBlob) == desc.Digest {
+			return sigBlob, ocispec.Descriptor{MediaType: jws.MediaTypeEnvelope}, nil
+		}
+	}
+	return nil, ocispec.Descriptor{}, errors.New("signature not found")
+}
+
+// digestResolverRepository resolves digests with any available digest
+// algorithm but the unsupported one.
+type digestResolverRepository struct {
+	signatureRepository
+	unsupported digest.Algorithm
+}
+
+func (r *digestResolverRepository) ResolveDigest(ctx context.Context, desc ocispec.Descriptor, alg digest.Algorithm) (ocispec.Descriptor, error) {
+	if alg == r.unsupported {
+		return ocispec.Descriptor{}, fmt.Errorf("unsupported digest algorithm %v", alg)
+	}
+	return r.Repository.(registry.DigestResolver).ResolveDigest(ctx, desc, alg)
+}
+
+// targetArtifactVerifier verifies that the signed target artifact matches the
+// artifact descriptor. It records the number of envelopes it parsed.
+type targetArtifactVerifier struct {
+	parsed int
+}
+
+func (v *targetArtifactVerifier) Verify(ctx context.Context, desc ocispec.Descriptor, sig []byte, opts VerifierVerifyOptions) (*VerificationOutcome, error) {
+	outcome := &VerificationOutcome{VerificationLevel: trustpolicy.LevelStrict}
+	parsedEnv, ok := envelope.ParsedEnvelopeFromContext(ctx, opts.SignatureMediaType, sig)
+	if !ok {
+		v.parsed++
+		var err error
+		if parsedEnv, err = envelope.Parse(opts.SignatureMediaType, sig); err != nil {
+			return outcome, err
+		}
+	}
+	sigEnv := parsedEnv.Envelope
+	envContent, err := sigEnv.Verify()
+	if err != nil {
+		return outcome, err
+	}
+	var payload struct {
+		TargetArtifact ocispec.Descriptor `json:"targetArtifact"`
+	}
+	if err := json.Unmarshal(envContent.Payload.Content, &payload); err != nil {
+		return outcome, err
+	}
+	if !content.Equal(payload.TargetArtifact, desc) {
+		outcome.Error = fmt.Errorf("signed target artifact %v does not match artifact %v", payload.TargetArtifact.Digest, desc.Digest)
+		return outcome, outcome.Error
+	}
+	outcome.EnvelopeContent = envContent
+	return outcome, nil
+}
+
+func TestVerifySHA512SignedArtifact(t *testing.T) {
+	ociRepo, err := registry.NewOCIRepository(ociLayoutPath, registry.RepositoryOptions{})
+	if err != nil {
+		t.Fatalf("failed to create oci.Store as registry.Repository: %v", err)
+	}
+	manifestDesc, err := ociRepo.Resolve(context.Background(), "v2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifestJSON, err := os.ReadFile(filepath.Join(ociLayoutPath, "blobs", "sha256", manifestDesc.Digest.Encoded()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, root := testhelper.GetRSALeafCertificate(), testhelper.GetRSARootCertificate()
+	localSigner, err := signature.NewLocalSigner([]*x509.Certificate{leaf.Cert, root.Cert}, leaf.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// sign signs the manifest with its digest computed with alg
+	sign := func(alg digest.Algorithm) []byte {
+		t.Helper()
+		targetDesc := ocispec.Descriptor{
+			MediaType: manifestDesc.MediaType,
+			Digest:    alg.FromBytes(manifestJSON),
+			Size:      manifestDesc.Size,
+		}
+		payload, err := json.Marshal(map[string]any{"targetArtifact": targetDesc})
+		if err != nil {
+			t.Fatal(err)
+		}
+		sigEnv, err := signature.NewEnvelope(jws.MediaTypeEnvelope)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sigBlob, err := sigEnv.Sign(&signature.SignRequest{
+			Payload:       signature.Payload{ContentType: "application/vnd.cncf.notary.payload.v1+json", Content: payload},
+			Signer:        localSigner,
+			SigningTime:   time.Now(),
+			SigningScheme: signature.SigningSchemeX509,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sigBlob
+	}
+	sigBlob := sign(digest.SHA512)
+	verifyOpts := VerifyOptions{
+		ArtifactReference:    "local/oci-layout:v2",
+		MaxSignatureAttempts: 50,
+	}
+
+	t.Run("resolved with the signed digest algorithm", func(t *testing.T) {
+		repo := &digestResolverRepository{signatureRepository: signatureRepository{Repository: ociRepo, sigBlobs: [][]byte{sigBlob}}}
+		verifier := &targetArtifactVerifier{}
+		desc, outcomes, err := Verify(context.Background(), verifier, repo, verifyOpts)
+		if err != nil {
+			t.Fatalf("expected verification to succeed, but got %v", err)
+		}
+		if desc.Digest != manifestDesc.Digest {
+			t.Fatalf("expected resolved descriptor %v, but got %v", manifestDesc.Digest, desc.Digest)
+		}
+		if len(outcomes) != 1 || outcomes[0].EnvelopeContent == nil {
+			t.Fatalf("expected one successful outcome, but got %v", outcomes)
+		}
+		if verifier.parsed != 0 {
+			t.Fatalf("expected the verifier to reuse the parsed envelope, but it parsed %d envelopes", verifier.parsed)
+		}
+	})
+
+	t.Run("digest resolution failure fails the signature only", func(t *testing.T) {
+		repo := &digestResolverRepository{
+			signatureRepository: signatureRepository{Repository: ociRepo, sigBlobs: [][]byte{sign(digest.SHA384), sigBlob}},
+			unsupported:         digest.SHA384,
+		}
+		desc, _, err := Verify(context.Background(), &targetArtifactVerifier{}, repo, verifyOpts)
+		if err != nil {
+			t.Fatalf("expected verification to succeed, but got %v", err)
+		}
+		if desc.Digest != manifestDesc.Digest {
+			t.Fatalf("expected resolved descriptor %v, but got %v", manifestDesc.Digest, desc.Digest)
+		}
+
+		repo.sigBlobs = repo.sigBlobs[:1]
+		_, _, err = Verify(context.Background(), &targetArtifactVerifier{}, repo, verifyOpts)
+		if !errors.As(err, &ErrorVerificationFailed{}) || !strings.Contains(err.Error(), "unsupported digest algorithm sha384") {
+			t.Fatalf("expected verification failure, but got %v", err)
+		}
+	})
+
+	t.Run("repository without digest resolver", func(t *testing.T) {
+		repo := &signatureRepository{Repository: ociRepo, sigBlobs: [][]byte{sigBlob}}
+		if _, _, err := Verify(context.Background(), &targetArtifactVerifier{}, repo, verifyOpts); err == nil {
+			t.Fatal("expected verification to fail")
+		}
+	})
+}
@@ -17,6 +17,7 @@ package registry
 import (
 	"context"
 
+	"github.com/opencontainers/go-digest"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 )
 
@@ -38,3 +39,12 @@ type Repository interface {
 	// linked signature envelope blob.
 	PushSignature(ctx context.Context, mediaType string, blob []byte, subject ocispec.Descriptor, annotations map[string]string) (blobDesc, manifestDesc ocispec.Descriptor, err error)
 }
+
+// DigestResolver is an optional interface implemented by a Repository that
+// can compute the digest of a manifest with a digest algorithm other than
+// the one the manifest was resolved with.
+type DigestResolver interface {
+	// ResolveDigest returns the manifest descriptor desc with its digest
+	// computed using the digest algorithm alg.
+	ResolveDigest(ctx context.Context, desc ocispec.Descriptor, alg digest.Algorithm) (ocispec.Descriptor, error)
+}
@@ -22,6 +22,7 @@ import (
 	"os"
 
 	"github.com/notaryproject/notation-go/registry/internal/artifactspec"
+	"github.com/opencontainers/go-digest"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"oras.land/oras-go/v2"
 	"oras.land/oras-go/v2/content"
@@ -102,6 +103,31 @@ func (c *repositoryClient) Resolve(ctx context.Context, reference string) (ocisp
 	return c.GraphTarget.Resolve(ctx, reference)
 }
 
+// ResolveDigest returns the manifest descriptor desc with its digest computed
+// using the digest algorithm alg
+func (c *repositoryClient) ResolveDigest(ctx context.Context, desc ocispec.Descriptor, alg digest.Algorithm) (ocispec.Descriptor, error) {
+	if desc.Digest.Algorithm() == alg {
+		return desc, nil
+	}
+	if !alg.Available() {
+		return ocispec.Descriptor{}, fmt.Errorf("digest algorithm %q is not supported", alg)
+	}
+	if desc.Size > maxManifestSizeLimit {
+		return ocispec.Descriptor{}, fmt.Errorf("manifest too large: %d bytes", desc.Size)
+	}
+
+	var fetcher content.Fetcher = c.GraphTarget
+	if repo, ok := c.GraphTarget.(registry.Repository); ok {
+		fetcher = repo.Manifests()
+	}
+	manifestJSON, err := content.FetchAll(ctx, fetcher, desc)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	desc.Digest = alg.FromBytes(manifestJSON)
+	return desc, nil
+}
+
 // ListSignatures returns signature manifests filtered by fn given the
 // target artifact's manifest descriptor
 func (c *repositoryClient) ListSignatures(ctx context.Context, desc ocispec.Descriptor, fn func(signatureManifests []ocispec.Descriptor) error) error {
@@ -522,3 +522,36 @@ func TestOciLayoutRepositoryListAndFetchBlob(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestOciLayoutRepositoryResolveDigest(t *testing.T) {
+	repo, err := NewOCIRepository(ociLayoutPath, RepositoryOptions{})
+	if err != nil {
+		t.Fatalf("failed to create oci.Store as registry.Repository: %v", err)
+	}
+	resolver, ok := repo.(DigestResolver)
+	if !ok {
+		t.Fatal("expected repository to implement DigestResolver")
+	}
+	manifestJSON, err := os.ReadFile(filepath.Join(ociLayoutPath, "blobs", "sha256", expectedTargetDesc.Digest.Encoded()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	desc, err := resolver.ResolveDigest(context.Background(), expectedTargetDesc, digest.SHA512)
+	if err != nil {
+		t.Fatalf("failed to resolve digest: %v", err)
+	}
+	if want := digest.SHA512.FromBytes(manifestJSON); desc.Digest != want || desc.Size != expectedTargetDesc.Size || desc.MediaType != expectedTargetDesc.MediaType {
+		t.Fatalf("expected digest %v, but got descriptor %v", want, desc)
+	}
+
+	desc, err = resolver.ResolveDigest(context.Background(), expectedTargetDesc, digest.SHA256)
+	if err != nil || !content.Equal(desc, expectedTargetDesc) {
+		t.Fatalf("expected descriptor %v, but got %v, %v", expectedTargetDesc, desc, err)
+	}
+
+	_, err = resolver.ResolveDigest(context.Background(), expectedTargetDesc, digest.Algorithm("md5"))
+	if err == nil || err.Error() != `digest algorithm "md5" is not supported` {
+		t.Fatalf("expected unsupported digest algorithm error, but got %v", err)
+	}
+}
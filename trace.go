@@ -0,0 +1,125 @@
+// Copyright The Notary Project Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notation
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// NetworkRequest describes an outbound network request made during
+// verification.
+type NetworkRequest struct {
+	// URL is the URL of the request
+	URL string
+
+	// Method is the HTTP method of the request
+	Method string
+
+	// StatusCode is the HTTP status code of the response. It is zero if no
+	// response is received.
+	StatusCode int
+
+	// Duration is the time elapsed until the response is received
+	Duration time.Duration
+
+	// Error is set if the request failed without a response
+	Error error
+}
+
+// NetworkTrace accumulates the outbound network requests made during
+// verification, e.g. OCSP, CRL or registry requests. It is safe for
+// concurrent use.
+type NetworkTrace struct {
+	mu       sync.Mutex
+	requests []NetworkRequest
+}
+
+// Record records a network request in the trace.
+func (t *NetworkTrace) Record(req NetworkRequest) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.requests = append(t.requests, req)
+}
+
+// Requests returns the network requests recorded in the trace in the order
+// they completed.
+func (t *NetworkTrace) Requests() []NetworkRequest {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return append([]NetworkRequest(nil), t.requests...)
+}
+
+type networkTraceContextKey struct{}
+
+// WithNetworkTrace returns a context carrying the network trace. The network
+// requests made during verification with the returned context are recorded
+// in the trace.
+func WithNetworkTrace(ctx context.Context, trace *NetworkTrace) context.Context {
+	return context.WithValue(ctx, networkTraceContextKey{}, trace)
+}
+
+// NetworkTraceFromContext returns the network trace carried by ctx, or nil
+// if there is none.
+func NetworkTraceFromContext(ctx context.Context) *NetworkTrace {
+	trace, _ := ctx.Value(networkTraceContextKey{}).(*NetworkTrace)
+	return trace
+}
+
+// TraceTransport is an http.RoundTripper recording the requests it sends in
+// a network trace. It can be used by the HTTP clients of registries to record
+// registry requests.
+type TraceTransport struct {
+	// Base is the underlying http.RoundTripper. If nil,
+	// http.DefaultTransport is used.
+	Base http.RoundTripper
+
+	// Trace is the network trace to record the requests in. If nil, the
+	// network trace carried by the request context is used.
+	Trace *NetworkTrace
+}
+
+// RoundTrip sends the request using the base http.RoundTripper and records
+// it in the network trace.
+func (t *TraceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	trace := t.Trace
+	if trace == nil {
+		trace = NetworkTraceFromContext(req.Context())
+	}
+	if trace == nil {
+		return base.RoundTrip(req)
+	}
+
+	start := time.Now()
+	resp, err := base.RoundTrip(req)
+	record := NetworkRequest{
+		URL:      req.URL.String(),
+		Method:   req.Method,
+		Duration: time.Since(start),
+		Error:    err,
+	}
+	if resp != nil {
+		record.StatusCode = resp.StatusCode
+	}
+	trace.Record(record)
+	return resp, err
+}
@@ -0,0 +1,181 @@
+// Copyright The Notary Project Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/notaryproject/notation-core-go/signature"
+	"github.com/notaryproject/notation-go"
+	"github.com/notaryproject/notation-go/internal/lru"
+	"github.com/notaryproject/notation-go/verifier/trustpolicy"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// defaultOutcomeCacheSize is the default maximum number of cached
+// verification outcomes.
+const defaultOutcomeCacheSize = 1024
+
+type outcomeCacheEntry struct {
+	outcome *notation.VerificationOutcome
+	err     error
+	expiry  time.Time
+}
+
+// outcomeCache caches verification outcomes keyed by the digest of the
+// artifact, the signature, the applicable trust policy statement and the
+// verify options. Successful outcomes are cached for ttl, and failed ones for
+// failureTTL. It is safe for concurrent use.
+type outcomeCache struct {
+	ttl        time.Duration
+	failureTTL time.Duration
+	entries    *lru.Cache[digest.Digest, outcomeCacheEntry]
+}
+
+func newOutcomeCache(size int, ttl, failureTTL time.Duration) *outcomeCache {
+	if size <= 0 {
+		size = defaultOutcomeCacheSize
+	}
+	return &outcomeCache{
+		ttl:        ttl,
+		failureTTL: failureTTL,
+		entries:    lru.New[digest.Digest, outcomeCacheEntry](size),
+	}
+}
+
+// get returns the cached verification outcome and error for the key if they
+// have not expired. The returned outcome is a copy that the caller is free to
+// modify.
+func (c *outcomeCache) get(key digest.Digest) (outcomeCacheEntry, bool) {
+	entry, ok := c.entries.Get(key)
+	if !ok || !time.Now().Before(entry.expiry) {
+		return outcomeCacheEntry{}, false
+	}
+	entry.outcome = cloneVerificationOutcome(entry.outcome)
+	return entry, true
+}
+
+// add caches a copy of the verification outcome and error for the key. Inconclusive
+// verifications are not cached since they are caused by transient failures,
+// and neither are failures if failureTTL is not positive.
+func (c *outcomeCache) add(key digest.Digest, outcome *notation.VerificationOutcome, err error) {
+	ttl := c.ttl
+	if err != nil {
+		var errInconclusive notation.ErrorVerificationInconclusive
+		if errors.As(err, &errInconclusive) {
+			return
+		}
+		ttl = c.failureTTL
+	}
+	if ttl <= 0 {
+		return
+	}
+	c.entries.Add(key, outcomeCacheEntry{
+		outcome: cloneVerificationOutcome(outcome),
+		err:     err,
+		expiry:  time.Now().Add(ttl),
+	})
+}
+
+// outcomeCacheKey returns the cache key of the verification of the signature
+// against the artifact desc under the trust policy statement with opts. Any
+// change of the statement content results in a different key.
+func outcomeCacheKey(desc ocispec.Descriptor, signature []byte, statement *trustpolicy.TrustPolicy, opts notation.VerifierVerifyOptions) (digest.Digest, error) {
+	certChain := make([][]byte, 0, len(opts.CertificateChain))
+	for _, cert := range opts.CertificateChain {
+		certChain = append(certChain, cert.Raw)
+	}
+	keyBytes, err := json.Marshal(struct {
+		MediaType            string                   `json:"mediaType"`
+		Digest               digest.Digest            `json:"digest"`
+		Size                 int64                    `json:"size"`
+		Signature            digest.Digest            `json:"signature"`
+		Statement            *trustpolicy.TrustPolicy `json:"statement"`
+		ArtifactReference    string                   `json:"artifactReference"`
+		SignatureMediaType   string                   `json:"signatureMediaType"`
+		PluginConfig         map[string]string        `json:"pluginConfig"`
+		UserMetadata         map[string]string        `json:"userMetadata"`
+		ForcePolicyStatement string                   `json:"forcePolicyStatement"`
+		CertificateChain     [][]byte                 `json:"certificateChain"`
+	}{
+		MediaType:            desc.MediaType,
+		Digest:               desc.Digest,
+		Size:                 desc.Size,
+		Signature:            digest.FromBytes(signature),
+		Statement:            statement,
+		ArtifactReference:    opts.ArtifactReference,
+		SignatureMediaType:   opts.SignatureMediaType,
+		PluginConfig:         opts.PluginConfig,
+		UserMetadata:         opts.UserMetadata,
+		ForcePolicyStatement: opts.ForcePolicyStatement,
+		CertificateChain:     certChain,
+	})
+	if err != nil {
+		return "", err
+	}
+	return digest.FromBytes(keyBytes), nil
+}
+
+// cloneVerificationOutcome returns a deep copy of the verification outcome so
+// that cached outcomes are not shared with, and modified by, callers. The
+// certificates of the signer info are immutable and shared.
+func cloneVerificationOutcome(outcome *notation.VerificationOutcome) *notation.VerificationOutcome {
+	if outcome == nil {
+		return nil
+	}
+	clone := *outcome
+	clone.RawSignature = append([]byte(nil), outcome.RawSignature...)
+	if outcome.EnvelopeContent != nil {
+		envelopeContent := *outcome.EnvelopeContent
+		signerInfo := &envelopeContent.SignerInfo
+		signerInfo.SignedAttributes.ExtendedAttributes = append([]signature.Attribute(nil), signerInfo.SignedAttributes.ExtendedAttributes...)
+		signerInfo.UnsignedAttributes.TimestampSignature = append([]byte(nil), signerInfo.UnsignedAttributes.TimestampSignature...)
+		signerInfo.CertificateChain = append([]*x509.Certificate(nil), signerInfo.CertificateChain...)
+		signerInfo.Signature = append([]byte(nil), signerInfo.Signature...)
+		envelopeContent.Payload.Content = append([]byte(nil), envelopeContent.Payload.Content...)
+		clone.EnvelopeContent = &envelopeContent
+	}
+	if outcome.VerificationLevel != nil {
+		verificationLevel := &trustpolicy.VerificationLevel{Name: outcome.VerificationLevel.Name}
+		if outcome.VerificationLevel.Enforcement != nil {
+			verificationLevel.Enforcement = make(map[trustpolicy.ValidationType]trustpolicy.ValidationAction, len(outcome.VerificationLevel.Enforcement))
+			for validationType, action := range outcome.VerificationLevel.Enforcement {
+				verificationLevel.Enforcement[validationType] = action
+			}
+		}
+		clone.VerificationLevel = verificationLevel
+	}
+	clone.VerificationResults = nil
+	for _, result := range outcome.VerificationResults {
+		if result == nil {
+			clone.VerificationResults = append(clone.VerificationResults, nil)
+			continue
+		}
+		resultCopy := *result
+		clone.VerificationResults = append(clone.VerificationResults, &resultCopy)
+	}
+	clone.Warnings = append([]error(nil), outcome.Warnings...)
+	if outcome.Durations != nil {
+		clone.Durations = make(map[trustpolicy.ValidationType]time.Duration, len(outcome.Durations))
+		for validationType, duration := range outcome.Durations {
+			clone.Durations[validationType] = duration
+		}
+	}
+	clone.SignerGroups = append([]string(nil), outcome.SignerGroups...)
+	return &clone
+}
@@ -0,0 +1,92 @@
+// Copyright The Notary Project Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+// CertificatePolicyError is used when the signing certificate does not assert
+// the certificate policies required by the verifier
+type CertificatePolicyError struct {
+	Msg string
+}
+
+func (e CertificatePolicyError) Error() string {
+	if e.Msg != "" {
+		return e.Msg
+	}
+	return "signing certificate does not assert the required certificate policies"
+}
+
+// NonFIPSAlgorithmError is used when the signature uses an algorithm that is
+// not FIPS approved while the verifier is in FIPS-only mode
+type NonFIPSAlgorithmError struct {
+	Msg string
+}
+
+func (e NonFIPSAlgorithmError) Error() string {
+	if e.Msg != "" {
+		return e.Msg
+	}
+	return "signature uses an algorithm that is not FIPS approved"
+}
+
+// LeafIsCAError is used when the signing certificate is a CA certificate
+type LeafIsCAError struct {
+	Msg string
+}
+
+func (e LeafIsCAError) Error() string {
+	if e.Msg != "" {
+		return e.Msg
+	}
+	return "signing certificate is a CA certificate"
+}
+
+// ChainDepthError is used when the certificate path of the signature
+// validated against the trust store exceeds the maximum chain depth of the
+// verifier
+type ChainDepthError struct {
+	Msg string
+}
+
+func (e ChainDepthError) Error() string {
+	if e.Msg != "" {
+		return e.Msg
+	}
+	return "certificate chain exceeds the maximum chain depth"
+}
+
+// SandboxViolationError is used when the verification requires an operation
+// that is not allowed in sandboxed mode, such as network access
+type SandboxViolationError struct {
+	Msg string
+}
+
+func (e SandboxViolationError) Error() string {
+	if e.Msg != "" {
+		return e.Msg
+	}
+	return "operation is not allowed in sandboxed mode"
+}
+
+// CertificateBlockedError is used when the certificate chain of the signature
+// contains a certificate blocked by the verifier
+type CertificateBlockedError struct {
+	Msg string
+}
+
+func (e CertificateBlockedError) Error() string {
+	if e.Msg != "" {
+		return e.Msg
+	}
+	return "certificate chain contains a blocked certificate"
+}
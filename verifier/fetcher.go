@@ -0,0 +1,176 @@
+// Copyright The Notary Project Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// maxRevocationResponseSize is the maximum size in bytes of an OCSP response
+// or a CRL fetched by the HTTP revocation fetcher
+const maxRevocationResponseSize = 20 * 1024 * 1024
+
+// RevocationFetcher fetches revocation information of certificates, e.g.
+// over a custom transport or from a local mirror.
+type RevocationFetcher interface {
+	// FetchOCSP sends the DER encoded OCSP request to the OCSP responder at
+	// url and returns the DER encoded OCSP response.
+	FetchOCSP(ctx context.Context, url string, req []byte) ([]byte, error)
+
+	// FetchCRL fetches the DER encoded CRL from the CRL distribution point at
+	// url.
+	FetchCRL(ctx context.Context, url string) ([]byte, error)
+}
+
+// httpRevocationFetcher is the RevocationFetcher over HTTP.
+type httpRevocationFetcher struct {
+	client *http.Client
+}
+
+// NewHTTPRevocationFetcher returns a RevocationFetcher fetching revocation
+// information over HTTP with the client. If client is nil,
+// http.DefaultClient is used.
+func NewHTTPRevocationFetcher(client *http.Client) RevocationFetcher {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &httpRevocationFetcher{client: client}
+}
+
+// FetchOCSP sends the OCSP request to the responder with an HTTP POST request.
+func (f *httpRevocationFetcher) FetchOCSP(ctx context.Context, url string, req []byte) ([]byte, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(req))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/ocsp-request")
+	return f.do(httpReq)
+}
+
+// FetchCRL fetches the CRL with an HTTP GET request.
+func (f *httpRevocationFetcher) FetchCRL(ctx context.Context, url string) ([]byte, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return f.do(httpReq)
+}
+
+func (f *httpRevocationFetcher) do(req *http.Request) ([]byte, error) {
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("%s %q: response had status code %d", req.Method, req.URL, resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxRevocationResponseSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > maxRevocationResponseSize {
+		return nil, fmt.Errorf("%s %q: response exceeds the maximum size of %d bytes", req.Method, req.URL, maxRevocationResponseSize)
+	}
+	return body, nil
+}
+
+// fetcherTransport is an http.RoundTripper serving the HTTP requests of the
+// revocation client with a RevocationFetcher. OCSP requests are sent either
+// as the body of a POST request or base64 encoded as the last path segment of
+// a GET request, other GET requests fetch CRLs.
+type fetcherTransport struct {
+	fetcher RevocationFetcher
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *fetcherTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	var err error
+	switch {
+	case req.Method == http.MethodPost && req.Header.Get("Content-Type") == "application/ocsp-request":
+		var ocspReq []byte
+		if req.Body != nil {
+			defer req.Body.Close()
+			ocspReq, err = io.ReadAll(req.Body)
+			if err != nil {
+				return nil, err
+			}
+		}
+		body, err = t.fetcher.FetchOCSP(req.Context(), req.URL.String(), ocspReq)
+	case req.Method == http.MethodGet:
+		if server, ocspReq, ok := parseOCSPGetRequest(req.URL); ok {
+			body, err = t.fetcher.FetchOCSP(req.Context(), server, ocspReq)
+		} else {
+			body, err = t.fetcher.FetchCRL(req.Context(), req.URL.String())
+		}
+	default:
+		return nil, fmt.Errorf("unsupported revocation request %s %q", req.Method, req.URL)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        make(http.Header),
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// parseOCSPGetRequest parses an OCSP request sent with HTTP GET as defined by
+// RFC 6960 appendix A.1, returning the URL of the responder and the DER
+// encoded OCSP request.
+func parseOCSPGetRequest(u *url.URL) (string, []byte, bool) {
+	// the base64 encoding may contain "/", so the path is split before it is
+	// unescaped
+	dir, encodedReq := path.Split(u.EscapedPath())
+	if encodedReq == "" {
+		return "", nil, false
+	}
+	encodedReq, err := url.PathUnescape(encodedReq)
+	if err != nil {
+		return "", nil, false
+	}
+	ocspReq, err := base64.StdEncoding.DecodeString(encodedReq)
+	if err != nil || !isDERSequence(ocspReq) {
+		return "", nil, false
+	}
+	dir, err = url.PathUnescape(strings.TrimSuffix(dir, "/"))
+	if err != nil {
+		return "", nil, false
+	}
+	server := *u
+	server.Path = dir
+	server.RawPath = ""
+	return server.String(), ocspReq, true
+}
+
+// isDERSequence reports whether the data starts with a DER encoded SEQUENCE.
+func isDERSequence(data []byte) bool {
+	return len(data) > 0 && data[0] == 0x30
+}
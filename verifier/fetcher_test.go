@@ -0,0 +1,224 @@
+// Copyright The Notary Project Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/notaryproject/notation-core-go/testhelper"
+	"github.com/notaryproject/notation-go"
+	"github.com/notaryproject/notation-go/internal/mock"
+	"github.com/notaryproject/notation-go/signer"
+	"golang.org/x/crypto/ocsp"
+)
+
+// memoryRevocationFetcher is a RevocationFetcher returning canned OCSP
+// responses keyed by the certificate serial number.
+type memoryRevocationFetcher struct {
+	ocspResponses map[string][]byte
+	urls          []string
+}
+
+func (f *memoryRevocationFetcher) FetchOCSP(ctx context.Context, url string, req []byte) ([]byte, error) {
+	f.urls = append(f.urls, url)
+	ocspReq, err := ocsp.ParseRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	resp, ok := f.ocspResponses[ocspReq.SerialNumber.String()]
+	if !ok {
+		return nil, errors.New("no canned OCSP response")
+	}
+	return resp, nil
+}
+
+func (f *memoryRevocationFetcher) FetchCRL(ctx context.Context, url string) ([]byte, error) {
+	return nil, errors.New("no canned CRL")
+}
+
+// recordingTransport is an http.RoundTripper recording the requests and
+// replying with a fixed response.
+type recordingTransport struct {
+	response []byte
+	requests []*http.Request
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.requests = append(t.requests, req)
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(bytes.NewReader(t.response)),
+		Request:    req,
+	}, nil
+}
+
+func TestRevocationFetcher(t *testing.T) {
+	chain := testhelper.GetRevokableRSAChain(2)
+	internalSigner, err := signer.New(chain[0].PrivateKey, []*x509.Certificate{chain[0].Cert, chain[1].Cert})
+	if err != nil {
+		t.Fatalf("Unexpected error while creating signer: %v", err)
+	}
+	envelopeBlob, _, err := internalSigner.Sign(context.Background(), mock.ImageDescriptor, notation.SignerSignOptions{SignatureMediaType: "application/jose+json"})
+	if err != nil {
+		t.Fatalf("Unexpected error while generating blob: %v", err)
+	}
+	ocspResponse := func(status int) []byte {
+		t.Helper()
+		now := time.Now()
+		resp, err := ocsp.CreateResponse(chain[1].Cert, chain[1].Cert, ocsp.Response{
+			Status:       status,
+			SerialNumber: chain[0].Cert.SerialNumber,
+			ThisUpdate:   now.Add(-time.Hour),
+			NextUpdate:   now.Add(time.Hour),
+			RevokedAt:    now.Add(-time.Hour),
+		}, chain[1].PrivateKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	policyDoc := dummyPolicyDocument()
+	policyDoc.TrustPolicies[0].TrustedIdentities = []string{"*"}
+	opts := notation.VerifierVerifyOptions{ArtifactReference: mock.SampleArtifactUri, SignatureMediaType: "application/jose+json"}
+	verify := func(t *testing.T, fetcher RevocationFetcher) error {
+		t.Helper()
+		v, err := NewWithOptions(&policyDoc, staticTrustStore{chain[1].Cert}, mock.PluginManager{}, VerifierOptions{RevocationFetcher: fetcher})
+		if err != nil {
+			t.Fatalf("unexpected error while creating verifier: %v", err)
+		}
+		_, err = v.Verify(context.Background(), mock.ImageDescriptor, envelopeBlob, opts)
+		return err
+	}
+
+	t.Run("good status", func(t *testing.T) {
+		fetcher := &memoryRevocationFetcher{ocspResponses: map[string][]byte{chain[0].Cert.SerialNumber.String(): ocspResponse(ocsp.Good)}}
+		if err := verify(t, fetcher); err != nil {
+			t.Fatalf("expected verification to succeed, but got %v", err)
+		}
+		if len(fetcher.urls) != 1 || fetcher.urls[0] != chain[0].Cert.OCSPServer[0] {
+			t.Fatalf("expected OCSP request to %q, but got %v", chain[0].Cert.OCSPServer[0], fetcher.urls)
+		}
+	})
+
+	t.Run("revoked status", func(t *testing.T) {
+		fetcher := &memoryRevocationFetcher{ocspResponses: map[string][]byte{chain[0].Cert.SerialNumber.String(): ocspResponse(ocsp.Revoked)}}
+		if err := verify(t, fetcher); err == nil || !strings.Contains(err.Error(), "is revoked") {
+			t.Fatalf("expected revoked error, but got %v", err)
+		}
+	})
+
+	t.Run("default client sends OCSP GET requests", func(t *testing.T) {
+		v, err := NewWithOptions(&policyDoc, staticTrustStore{chain[1].Cert}, mock.PluginManager{}, VerifierOptions{})
+		if err != nil {
+			t.Fatalf("unexpected error while creating verifier: %v", err)
+		}
+		// the default revocation client sends its requests with the default
+		// HTTP transport as is
+		if tr := v.(*verifier).revocationHTTPClient.Transport; tr != nil {
+			t.Fatalf("expected default revocation client to use the default transport, but got %T", tr)
+		}
+		transport := &recordingTransport{response: ocspResponse(ocsp.Good)}
+		v.(*verifier).revocationHTTPClient.Transport = transport
+		if _, err := v.Verify(context.Background(), mock.ImageDescriptor, envelopeBlob, opts); err != nil {
+			t.Fatalf("expected verification to succeed, but got %v", err)
+		}
+		if len(transport.requests) != 1 || transport.requests[0].Method != http.MethodGet || !strings.HasPrefix(transport.requests[0].URL.String(), chain[0].Cert.OCSPServer[0]) {
+			t.Fatalf("expected an OCSP GET request to %q, but got %v", chain[0].Cert.OCSPServer[0], transport.requests)
+		}
+	})
+
+	t.Run("fetch failure", func(t *testing.T) {
+		fetcher := &memoryRevocationFetcher{}
+		if err := verify(t, fetcher); err == nil {
+			t.Fatal("expected verification to fail")
+		}
+	})
+}
+
+func TestFetcherTransport(t *testing.T) {
+	ocspReq := []byte{0x30, 0x03, 0x02, 0x01, 0x01}
+	fetcher := &memoryRevocationFetcher{ocspResponses: map[string][]byte{big.NewInt(1).String(): []byte("response")}}
+	client := &http.Client{Transport: &fetcherTransport{fetcher: fetcher}}
+
+	t.Run("CRL request", func(t *testing.T) {
+		_, err := client.Get("http://crl.example.com/ca.crl")
+		if err == nil || !strings.Contains(err.Error(), "no canned CRL") {
+			t.Fatalf("expected CRL fetch error, but got %v", err)
+		}
+	})
+
+	t.Run("OCSP GET request", func(t *testing.T) {
+		server, req, ok := parseOCSPGetRequest(mustParseURL(t, "http://ocsp.example.com/ocsp/MAMCAQE%3D"))
+		if !ok || server != "http://ocsp.example.com/ocsp" || string(req) != string(ocspReq) {
+			t.Fatalf("unexpected OCSP request %q to %q", req, server)
+		}
+	})
+
+	t.Run("unsupported request", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPut, "http://ocsp.example.com", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := client.Do(req); err == nil {
+			t.Fatal("expected unsupported request error")
+		}
+	})
+}
+
+func TestHTTPRevocationFetcher(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.Header.Get("Content-Type") == "application/ocsp-request":
+			w.Write([]byte("ocsp"))
+		case r.Method == http.MethodGet && r.URL.Path == "/ca.crl":
+			w.Write([]byte("crl"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	fetcher := NewHTTPRevocationFetcher(nil)
+	if resp, err := fetcher.FetchOCSP(context.Background(), server.URL, []byte("request")); err != nil || string(resp) != "ocsp" {
+		t.Fatalf("unexpected OCSP response %q, error: %v", resp, err)
+	}
+	if resp, err := fetcher.FetchCRL(context.Background(), server.URL+"/ca.crl"); err != nil || string(resp) != "crl" {
+		t.Fatalf("unexpected CRL %q, error: %v", resp, err)
+	}
+	if _, err := fetcher.FetchCRL(context.Background(), server.URL+"/missing.crl"); err == nil || !strings.Contains(err.Error(), "status code 404") {
+		t.Fatalf("expected status code error, but got %v", err)
+	}
+
+}
+
+func mustParseURL(t *testing.T, rawURL string) *url.URL {
+	t.Helper()
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
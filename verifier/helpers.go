@@ -14,14 +14,22 @@
 package verifier
 
 import (
+	"bytes"
 	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
 	"crypto/x509"
+	"encoding/asn1"
 	"errors"
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/notaryproject/notation-core-go/signature"
+	corex509 "github.com/notaryproject/notation-core-go/x509"
 	"github.com/notaryproject/notation-go"
 	set "github.com/notaryproject/notation-go/internal/container"
 	"github.com/notaryproject/notation-go/internal/slices"
@@ -47,6 +55,10 @@ var VerificationPluginHeaders = []string{
 
 var errExtendedAttributeNotExist = errors.New("extended attribute not exist")
 
+// oidAnyPolicy is the special anyPolicy certificate policy identifier defined
+// in RFC 5280 section 4.2.1.4
+var oidAnyPolicy = asn1.ObjectIdentifier{2, 5, 29, 32, 0}
+
 // semVerRegEx is takenfrom https://semver.org/#is-there-a-suggested-regular-expression-regex-to-check-a-semver-string
 var semVerRegEx = regexp.MustCompile(`^(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)(?:-((?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\.(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?(?:\+([0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*))?$`)
 
@@ -60,7 +72,12 @@ func loadX509TrustStores(ctx context.Context, scheme signature.SigningScheme, po
 	default:
 		return nil, truststore.TrustStoreError{Msg: fmt.Sprintf("error while loading the trust store, unrecognized signing scheme %q", scheme)}
 	}
+	return loadX509TrustStoresOfType(ctx, typeToLoad, policy, x509TrustStore)
+}
 
+// loadX509TrustStoresOfType loads the certificates of the trust stores of
+// typeToLoad referenced by the trust policy.
+func loadX509TrustStoresOfType(ctx context.Context, typeToLoad truststore.Type, policy *trustpolicy.TrustPolicy, x509TrustStore truststore.X509TrustStore) ([]*x509.Certificate, error) {
 	processedStoreSet := set.New[string]()
 	var certificates []*x509.Certificate
 	for _, trustStore := range policy.TrustStores {
@@ -158,6 +175,198 @@ func getVerificationPluginMinVersion(signerInfo *signature.SignerInfo) (string,
 	return version, nil
 }
 
+// parseObjectIdentifier parses an object identifier in dotted decimal form,
+// e.g. "2.5.29.32.0"
+func parseObjectIdentifier(s string) (asn1.ObjectIdentifier, error) {
+	parts := strings.Split(s, ".")
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("%q is not a valid object identifier", s)
+	}
+	oid := make(asn1.ObjectIdentifier, 0, len(parts))
+	for _, part := range parts {
+		arc, err := strconv.Atoi(part)
+		if err != nil || arc < 0 {
+			return nil, fmt.Errorf("%q is not a valid object identifier", s)
+		}
+		oid = append(oid, arc)
+	}
+	return oid, nil
+}
+
 func isVersionSemverValid(version string) bool {
 	return semVerRegEx.MatchString(version)
 }
+
+// verifySuppliedCertificateChain verifies that the certificate chain supplied
+// by the caller is a valid code signing certificate chain and its leaf
+// certificate holds the same key as the leaf certificate embedded in the
+// signature envelope, which the signature has been verified against.
+func verifySuppliedCertificateChain(embeddedChain, suppliedChain []*x509.Certificate) error {
+	if len(suppliedChain) > 0 {
+		if err := verifyLeafIsNotCA(suppliedChain[0]); err != nil {
+			return err
+		}
+	}
+	if err := corex509.ValidateCodeSigningCertChain(suppliedChain, nil); err != nil {
+		return &signature.InvalidSignatureError{Msg: fmt.Sprintf("supplied certificate chain is invalid, %s", err)}
+	}
+	if len(embeddedChain) == 0 {
+		return &signature.InvalidSignatureError{Msg: "certificate chain is not present in the signature envelope"}
+	}
+	leafKey, ok := suppliedChain[0].PublicKey.(interface {
+		Equal(crypto.PublicKey) bool
+	})
+	if !ok || !leafKey.Equal(embeddedChain[0].PublicKey) {
+		return &signature.InvalidSignatureError{Msg: fmt.Sprintf("the key of the supplied leaf certificate with subject %q does not match the signature", suppliedChain[0].Subject)}
+	}
+	return nil
+}
+
+// verifyCertificateChainOrder verifies that the certificate chain is ordered
+// leaf-first with each certificate issued by the next one.
+func verifyCertificateChainOrder(certChain []*x509.Certificate) error {
+	for i := 0; i < len(certChain)-1; i++ {
+		if !isIssuedBy(certChain[i], certChain[i+1]) {
+			return &signature.InvalidSignatureError{Msg: fmt.Sprintf("certificate chain is not ordered leaf-first with each certificate issued by the next one, certificate %q is not issued by certificate %q", certChain[i].Subject, certChain[i+1].Subject)}
+		}
+	}
+	return nil
+}
+
+// orderCertificateChain returns the certificates of the chain ordered
+// leaf-first with each certificate issued by the next one. It fails if the
+// certificates do not form a single chain, e.g. if an unrelated certificate
+// is present or an intermediate certificate is missing.
+func orderCertificateChain(certChain []*x509.Certificate) ([]*x509.Certificate, error) {
+	// the leaf certificate is the only certificate that issues no other
+	// certificate of the chain
+	var leaf *x509.Certificate
+	for i, cert := range certChain {
+		issuesOther := false
+		for j, other := range certChain {
+			if i != j && isIssuedBy(other, cert) {
+				issuesOther = true
+				break
+			}
+		}
+		if !issuesOther {
+			if leaf != nil {
+				return nil, &signature.InvalidSignatureError{Msg: fmt.Sprintf("certificate chain cannot be ordered, certificates %q and %q do not belong to a single chain", leaf.Subject, cert.Subject)}
+			}
+			leaf = cert
+		}
+	}
+	if leaf == nil {
+		return nil, &signature.InvalidSignatureError{Msg: "certificate chain cannot be ordered, it has no leaf certificate"}
+	}
+
+	ordered := []*x509.Certificate{leaf}
+	used := map[*x509.Certificate]bool{leaf: true}
+	for current := leaf; len(ordered) < len(certChain); {
+		var issuer *x509.Certificate
+		for _, cert := range certChain {
+			if !used[cert] && isIssuedBy(current, cert) {
+				issuer = cert
+				break
+			}
+		}
+		if issuer == nil {
+			return nil, &signature.InvalidSignatureError{Msg: fmt.Sprintf("certificate chain cannot be ordered, the issuer of certificate %q is missing", current.Subject)}
+		}
+		ordered = append(ordered, issuer)
+		used[issuer] = true
+		current = issuer
+	}
+	return ordered, nil
+}
+
+// isIssuedBy reports whether cert is issued and signed by issuer.
+func isIssuedBy(cert, issuer *x509.Certificate) bool {
+	return bytes.Equal(cert.RawIssuer, issuer.RawSubject) && cert.CheckSignatureFrom(issuer) == nil
+}
+
+// fipsSignatureAlgorithms are the FIPS approved signature algorithms of
+// signature envelopes.
+var fipsSignatureAlgorithms = []signature.Algorithm{
+	signature.AlgorithmPS256,
+	signature.AlgorithmPS384,
+	signature.AlgorithmPS512,
+	signature.AlgorithmES256,
+	signature.AlgorithmES384,
+	signature.AlgorithmES512,
+}
+
+// fipsCertificateSignatureAlgorithms are the FIPS approved signature
+// algorithms of certificates.
+var fipsCertificateSignatureAlgorithms = []x509.SignatureAlgorithm{
+	x509.SHA256WithRSA,
+	x509.SHA384WithRSA,
+	x509.SHA512WithRSA,
+	x509.SHA256WithRSAPSS,
+	x509.SHA384WithRSAPSS,
+	x509.SHA512WithRSAPSS,
+	x509.ECDSAWithSHA256,
+	x509.ECDSAWithSHA384,
+	x509.ECDSAWithSHA512,
+}
+
+// verifyFIPSAlgorithms verifies that the signature algorithm, and the
+// signature algorithms and public keys of the certificate chain are FIPS
+// approved.
+func verifyFIPSAlgorithms(signerInfo *signature.SignerInfo) error {
+	if !slices.Contains(fipsSignatureAlgorithms, signerInfo.SignatureAlgorithm) {
+		return NonFIPSAlgorithmError{Msg: fmt.Sprintf("signature algorithm %q is not FIPS approved", signerInfo.SignatureAlgorithm)}
+	}
+	for _, cert := range signerInfo.CertificateChain {
+		if !slices.Contains(fipsCertificateSignatureAlgorithms, cert.SignatureAlgorithm) {
+			return NonFIPSAlgorithmError{Msg: fmt.Sprintf("certificate with subject %q uses signature algorithm %s that is not FIPS approved", cert.Subject, cert.SignatureAlgorithm)}
+		}
+		if !isFIPSPublicKey(cert.PublicKey) {
+			return NonFIPSAlgorithmError{Msg: fmt.Sprintf("certificate with subject %q has a public key of type %s that is not FIPS approved", cert.Subject, cert.PublicKeyAlgorithm)}
+		}
+	}
+	return nil
+}
+
+// isFIPSPublicKey reports whether key is an RSA key of at least 2048 bits or
+// an ECDSA key on a NIST P-256, P-384 or P-521 curve.
+func isFIPSPublicKey(key any) bool {
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		return key.N.BitLen() >= 2048
+	case *ecdsa.PublicKey:
+		switch key.Curve {
+		case elliptic.P256(), elliptic.P384(), elliptic.P521():
+			return true
+		}
+	}
+	return false
+}
+
+// verifyLeafIsNotCA verifies that the signing certificate is not a CA
+// certificate, regardless of whether the CA certificate is trusted.
+func verifyLeafIsNotCA(leaf *x509.Certificate) error {
+	if leaf.BasicConstraintsValid && leaf.IsCA {
+		return LeafIsCAError{Msg: fmt.Sprintf("signing certificate with subject %q is a CA certificate, a CA certificate cannot be used as the signing certificate", leaf.Subject)}
+	}
+	return nil
+}
+
+// validateCheckOrder validates that the check order contains each
+// configurable check exactly once.
+func validateCheckOrder(checkOrder []Check) error {
+	if len(checkOrder) != len(defaultCheckOrder) {
+		return fmt.Errorf("invalid check order %v, it must contain each of the checks %v exactly once", checkOrder, defaultCheckOrder)
+	}
+	seen := set.New[Check]()
+	for _, check := range checkOrder {
+		if !slices.Contains(defaultCheckOrder, check) {
+			return fmt.Errorf("invalid check order %v, unknown check %q", checkOrder, check)
+		}
+		if seen.Contains(check) {
+			return fmt.Errorf("invalid check order %v, check %q is listed more than once", checkOrder, check)
+		}
+		seen.Add(check)
+	}
+	return nil
+}
@@ -80,7 +80,8 @@ func TestLoadX509TrustStore(t *testing.T) {
 	caStore := "ca:valid-trust-store"
 	signingAuthorityStore := "signingAuthority:valid-trust-store"
 	dummyPolicy := dummyPolicyStatement()
-	dummyPolicy.TrustStores = []string{caStore, signingAuthorityStore}
+	// tsa trust stores are not loaded to verify signing certificates
+	dummyPolicy.TrustStores = []string{caStore, signingAuthorityStore, "tsa:non-existent-store"}
 	dir.UserConfigDir = "testdata"
 	x509truststore := truststore.NewX509TrustStore(dir.ConfigFS())
 	caCerts, err := loadX509TrustStores(context.Background(), signature.SigningSchemeX509, &dummyPolicy, x509truststore)
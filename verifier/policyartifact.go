@@ -0,0 +1,147 @@
+// Copyright The Notary Project Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+
+	"github.com/notaryproject/notation-go"
+	"github.com/notaryproject/notation-go/log"
+	"github.com/notaryproject/notation-go/registry"
+	"github.com/notaryproject/notation-go/verifier/trustpolicy"
+	"github.com/notaryproject/notation-go/verifier/truststore"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	orasRegistry "oras.land/oras-go/v2/registry"
+)
+
+// bootstrapTrustStoreName is the name of the trust store holding the
+// bootstrap roots when verifying a trust policy artifact.
+const bootstrapTrustStoreName = "bootstrap"
+
+// TrustPolicyArtifactOptions contains parameters for
+// LoadTrustPolicyFromArtifact.
+type TrustPolicyArtifactOptions struct {
+	// ArtifactReference is the reference of the OCI artifact containing the
+	// trust policy. It must be a full reference.
+	ArtifactReference string
+
+	// BootstrapRoots are the root certificates trusted for verifying the
+	// signature of the trust policy artifact. Since the trust policy cannot
+	// vouch for itself, the trust in its signer must be established
+	// explicitly. If not set, the signature of the trust policy artifact is
+	// not verified.
+	BootstrapRoots []*x509.Certificate
+
+	// BootstrapTrustedIdentities are the trusted identities of the signer of
+	// the trust policy artifact, in the format of the trustedIdentities of a
+	// trust policy statement. Required if BootstrapRoots is set.
+	BootstrapTrustedIdentities []string
+
+	// MaxSignatureAttempts is the maximum number of signatures of the trust
+	// policy artifact to attempt verifying. Defaults to 50 if not set.
+	MaxSignatureAttempts int
+}
+
+// LoadTrustPolicyFromArtifact loads the trust policy document contained in
+// the single layer of the OCI artifact referenced by opts.ArtifactReference
+// in repo, and parses and validates it as trustpolicy.ParsePolicyDocument
+// does. If bootstrap roots are provided, the signature
+// of the trust policy artifact is verified against them before the trust
+// policy is loaded.
+func LoadTrustPolicyFromArtifact(ctx context.Context, repo registry.Repository, opts TrustPolicyArtifactOptions) (*trustpolicy.Document, error) {
+	logger := log.GetLogger(ctx)
+
+	if repo == nil {
+		return nil, errors.New("repo cannot be nil")
+	}
+	ref, err := orasRegistry.ParseReference(opts.ArtifactReference)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trust policy artifact reference %q: %w", opts.ArtifactReference, err)
+	}
+	if ref.Reference == "" {
+		return nil, fmt.Errorf("invalid trust policy artifact reference %q: reference is missing digest or tag", opts.ArtifactReference)
+	}
+
+	var artifactDesc ocispec.Descriptor
+	if len(opts.BootstrapRoots) > 0 {
+		bootstrapVerifier, err := newBootstrapVerifier(ref, opts)
+		if err != nil {
+			return nil, err
+		}
+		maxSignatureAttempts := opts.MaxSignatureAttempts
+		if maxSignatureAttempts <= 0 {
+			maxSignatureAttempts = 50
+		}
+		logger.Debugf("Verifying the signature of trust policy artifact %s", opts.ArtifactReference)
+		artifactDesc, _, err = notation.Verify(ctx, bootstrapVerifier, repo, notation.VerifyOptions{
+			ArtifactReference:    opts.ArtifactReference,
+			MaxSignatureAttempts: maxSignatureAttempts,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to verify trust policy artifact %q: %w", opts.ArtifactReference, err)
+		}
+	} else {
+		logger.Warnf("Signature of trust policy artifact %s is not verified since no bootstrap root is provided", opts.ArtifactReference)
+		artifactDesc, err = repo.Resolve(ctx, opts.ArtifactReference)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve trust policy artifact %q: %w", opts.ArtifactReference, err)
+		}
+	}
+
+	policyBlob, _, err := repo.FetchSignatureBlob(ctx, artifactDesc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch trust policy from artifact %q: %w", opts.ArtifactReference, err)
+	}
+	policyDocument, err := trustpolicy.ParsePolicyDocument(bytes.NewReader(policyBlob))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load trust policy from artifact %q: %w", opts.ArtifactReference, err)
+	}
+	return policyDocument, nil
+}
+
+// newBootstrapVerifier returns a verifier trusting the bootstrap roots and
+// identities for the repository of the trust policy artifact only.
+func newBootstrapVerifier(ref orasRegistry.Reference, opts TrustPolicyArtifactOptions) (notation.Verifier, error) {
+	if len(opts.BootstrapTrustedIdentities) == 0 {
+		return nil, errors.New("bootstrap trusted identities must be specified along with bootstrap roots")
+	}
+	policyDocument := &trustpolicy.Document{
+		Version: "1.0",
+		TrustPolicies: []trustpolicy.TrustPolicy{
+			{
+				Name:                  "trust-policy-artifact",
+				RegistryScopes:        []string{ref.Registry + "/" + ref.Repository},
+				SignatureVerification: trustpolicy.SignatureVerification{VerificationLevel: trustpolicy.LevelStrict.Name},
+				TrustStores:           []string{string(truststore.TypeCA) + ":" + bootstrapTrustStoreName},
+				TrustedIdentities:     opts.BootstrapTrustedIdentities,
+			},
+		},
+	}
+	return NewWithOptions(policyDocument, bootstrapTrustStore(opts.BootstrapRoots), nil, VerifierOptions{})
+}
+
+// bootstrapTrustStore is a truststore.X509TrustStore containing the bootstrap
+// roots in the "ca:bootstrap" trust store.
+type bootstrapTrustStore []*x509.Certificate
+
+func (s bootstrapTrustStore) GetCertificates(ctx context.Context, storeType truststore.Type, namedStore string) ([]*x509.Certificate, error) {
+	if storeType != truststore.TypeCA || namedStore != bootstrapTrustStoreName {
+		return nil, fmt.Errorf("trust store %s:%s does not exist", storeType, namedStore)
+	}
+	return s, nil
+}
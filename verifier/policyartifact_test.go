@@ -0,0 +1,147 @@
+// Copyright The Notary Project Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/notaryproject/notation-core-go/testhelper"
+	"github.com/notaryproject/notation-go/dir"
+	"github.com/notaryproject/notation-go/internal/mock"
+	"github.com/notaryproject/notation-go/verifier/trustpolicy"
+	"github.com/notaryproject/notation-go/verifier/truststore"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+)
+
+// policyRepository is a mock repository serving a trust policy blob as the
+// single layer of the artifact mock.ImageDescriptor.
+type policyRepository struct {
+	mock.Repository
+	policyBlob []byte
+}
+
+func (r policyRepository) FetchSignatureBlob(ctx context.Context, desc ocispec.Descriptor) ([]byte, ocispec.Descriptor, error) {
+	if content.Equal(desc, mock.ImageDescriptor) {
+		return r.policyBlob, ocispec.Descriptor{}, nil
+	}
+	return r.Repository.FetchSignatureBlob(ctx, desc)
+}
+
+func TestLoadTrustPolicyFromArtifact(t *testing.T) {
+	policyDocument := dummyPolicyDocument()
+	policyBlob, err := json.Marshal(policyDocument)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := policyRepository{Repository: mock.NewRepository(), policyBlob: policyBlob}
+
+	dir.UserConfigDir = "testdata"
+	roots, err := truststore.NewX509TrustStore(dir.ConfigFS()).GetCertificates(context.Background(), truststore.TypeCA, "valid-trust-store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	trustedIdentities := []string{"x509.subject:CN=Notation Test Root,O=Notary,L=Seattle,ST=WA,C=US"}
+
+	t.Run("without signature verification", func(t *testing.T) {
+		doc, err := LoadTrustPolicyFromArtifact(context.Background(), repo, TrustPolicyArtifactOptions{ArtifactReference: mock.SampleArtifactUri})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if doc.TrustPolicies[0].Name != policyDocument.TrustPolicies[0].Name {
+			t.Fatalf("unexpected trust policy document %+v", doc)
+		}
+	})
+
+	t.Run("signature verified against bootstrap root", func(t *testing.T) {
+		doc, err := LoadTrustPolicyFromArtifact(context.Background(), repo, TrustPolicyArtifactOptions{
+			ArtifactReference:          mock.SampleArtifactUri,
+			BootstrapRoots:             roots,
+			BootstrapTrustedIdentities: trustedIdentities,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(doc.TrustPolicies) != 1 {
+			t.Fatalf("unexpected trust policy document %+v", doc)
+		}
+	})
+
+	t.Run("signature not trusted by bootstrap root", func(t *testing.T) {
+		_, err := LoadTrustPolicyFromArtifact(context.Background(), repo, TrustPolicyArtifactOptions{
+			ArtifactReference:          mock.SampleArtifactUri,
+			BootstrapRoots:             []*x509.Certificate{testhelper.GetRSARootCertificate().Cert},
+			BootstrapTrustedIdentities: trustedIdentities,
+		})
+		if err == nil || !strings.HasPrefix(err.Error(), "failed to verify trust policy artifact") {
+			t.Fatalf("expected verification failure, but got %v", err)
+		}
+	})
+
+	t.Run("bootstrap root without trusted identities", func(t *testing.T) {
+		_, err := LoadTrustPolicyFromArtifact(context.Background(), repo, TrustPolicyArtifactOptions{
+			ArtifactReference: mock.SampleArtifactUri,
+			BootstrapRoots:    roots,
+		})
+		if err == nil {
+			t.Fatal("expected error for missing bootstrap trusted identities")
+		}
+	})
+
+	t.Run("invalid trust policy", func(t *testing.T) {
+		invalidDocument := dummyPolicyDocument()
+		invalidDocument.Version = "2.0"
+		invalidBlob, err := json.Marshal(invalidDocument)
+		if err != nil {
+			t.Fatal(err)
+		}
+		invalidRepo := policyRepository{Repository: mock.NewRepository(), policyBlob: invalidBlob}
+		_, err = LoadTrustPolicyFromArtifact(context.Background(), invalidRepo, TrustPolicyArtifactOptions{ArtifactReference: mock.SampleArtifactUri})
+		var invalidErr trustpolicy.InvalidDocumentError
+		if !errors.As(err, &invalidErr) {
+			t.Fatalf("expected invalid trust policy error, but got %v", err)
+		}
+	})
+
+	t.Run("malformed trust policy", func(t *testing.T) {
+		malformedRepo := policyRepository{Repository: mock.NewRepository(), policyBlob: []byte("{")}
+		_, err := LoadTrustPolicyFromArtifact(context.Background(), malformedRepo, TrustPolicyArtifactOptions{ArtifactReference: mock.SampleArtifactUri})
+		var malformedErr trustpolicy.MalformedDocumentError
+		if !errors.As(err, &malformedErr) {
+			t.Fatalf("expected malformed trust policy error, but got %v", err)
+		}
+	})
+
+	t.Run("trust policy with unknown field", func(t *testing.T) {
+		unknownFieldBlob := bytes.Replace(policyBlob, []byte(`"version"`), []byte(`"trustPolicy":[],"version"`), 1)
+		unknownFieldRepo := policyRepository{Repository: mock.NewRepository(), policyBlob: unknownFieldBlob}
+		_, err := LoadTrustPolicyFromArtifact(context.Background(), unknownFieldRepo, TrustPolicyArtifactOptions{ArtifactReference: mock.SampleArtifactUri})
+		var malformedErr trustpolicy.MalformedDocumentError
+		if !errors.As(err, &malformedErr) || !strings.Contains(err.Error(), "unknown field") {
+			t.Fatalf("expected unknown field error, but got %v", err)
+		}
+	})
+
+	t.Run("reference without digest or tag", func(t *testing.T) {
+		if _, err := LoadTrustPolicyFromArtifact(context.Background(), repo, TrustPolicyArtifactOptions{ArtifactReference: "registry.acme-rockets.io/policies"}); err == nil {
+			t.Fatal("expected error for reference without digest or tag")
+		}
+	})
+}
@@ -0,0 +1,82 @@
+// Copyright The Notary Project Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"context"
+	"time"
+
+	"github.com/notaryproject/notation-go/internal/lru"
+	"github.com/notaryproject/notation-go/verifier/trustpolicy"
+)
+
+// TrustedIdentityResolver resolves the trusted identities of a trust policy
+// statement dynamically, e.g. from a central service managing the authorized
+// signers of an organization.
+type TrustedIdentityResolver interface {
+	// Resolve returns the trusted identities that apply to the artifact
+	// referenced as `artifactReference` under the trust policy statement
+	// `statement`. The returned identities follow the format of the
+	// `trustedIdentities` field of a trust policy statement and override it.
+	Resolve(ctx context.Context, statement *trustpolicy.TrustPolicy, artifactReference string) ([]string, error)
+}
+
+// trustedIdentityCacheKey identifies resolved trusted identities by the trust
+// policy statement and the artifact reference they were resolved for.
+type trustedIdentityCacheKey struct {
+	statement         string
+	artifactReference string
+}
+
+// defaultTrustedIdentityCacheSize is the maximum number of cached resolved
+// trusted identities.
+const defaultTrustedIdentityCacheSize = 1024
+
+type trustedIdentityCacheEntry struct {
+	identities []string
+	expiry     time.Time
+}
+
+// trustedIdentityCache caches resolved trusted identities for a fixed
+// duration. When the cache is full, the least recently used entry is
+// evicted. It is safe for concurrent use.
+type trustedIdentityCache struct {
+	ttl     time.Duration
+	entries *lru.Cache[trustedIdentityCacheKey, trustedIdentityCacheEntry]
+}
+
+func newTrustedIdentityCache(ttl time.Duration) *trustedIdentityCache {
+	return &trustedIdentityCache{
+		ttl:     ttl,
+		entries: lru.New[trustedIdentityCacheKey, trustedIdentityCacheEntry](defaultTrustedIdentityCacheSize),
+	}
+}
+
+// get returns the cached trusted identities for the key if they have not
+// expired.
+func (c *trustedIdentityCache) get(key trustedIdentityCacheKey) ([]string, bool) {
+	entry, ok := c.entries.Get(key)
+	if !ok || !time.Now().Before(entry.expiry) {
+		return nil, false
+	}
+	return append([]string(nil), entry.identities...), true
+}
+
+// add caches the trusted identities for the key.
+func (c *trustedIdentityCache) add(key trustedIdentityCacheKey, identities []string) {
+	c.entries.Add(key, trustedIdentityCacheEntry{
+		identities: append([]string(nil), identities...),
+		expiry:     time.Now().Add(c.ttl),
+	})
+}
@@ -0,0 +1,71 @@
+// Copyright The Notary Project Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+
+	"github.com/notaryproject/notation-go/verifier/trustpolicy"
+	"github.com/notaryproject/notation-go/verifier/truststore"
+)
+
+// preloadedTrustStore is a truststore.X509TrustStore serving certificates
+// loaded in advance, keyed by "<TrustStoreType>:<TrustStoreName>".
+type preloadedTrustStore map[string][]*x509.Certificate
+
+// GetCertificates returns the pre-loaded certificates of the named store.
+func (s preloadedTrustStore) GetCertificates(ctx context.Context, storeType truststore.Type, namedStore string) ([]*x509.Certificate, error) {
+	trustStore := string(storeType) + ":" + namedStore
+	certs, ok := s[trustStore]
+	if !ok {
+		return nil, SandboxViolationError{Msg: fmt.Sprintf("trust store %q is not pre-loaded, loading trust stores is not allowed in sandboxed mode", trustStore)}
+	}
+	return certs, nil
+}
+
+// preloadTrustStores loads the certificates of every trust store referenced
+// by the trust policy document from trustStore.
+func preloadTrustStores(ctx context.Context, trustPolicyDoc *trustpolicy.Document, trustStore truststore.X509TrustStore) (preloadedTrustStore, error) {
+	preloaded := make(preloadedTrustStore)
+	load := func(trustStores []string) error {
+		for _, ts := range trustStores {
+			if _, ok := preloaded[ts]; ok {
+				continue
+			}
+			storeType, namedStore, err := trustpolicy.ParseTrustStore(ts)
+			if err != nil {
+				return err
+			}
+			certs, err := trustStore.GetCertificates(ctx, storeType, namedStore)
+			if err != nil {
+				return fmt.Errorf("failed to pre-load trust store %q: %w", ts, err)
+			}
+			preloaded[ts] = certs
+		}
+		return nil
+	}
+	for _, statement := range trustPolicyDoc.TrustPolicies {
+		if err := load(statement.TrustStores); err != nil {
+			return nil, err
+		}
+		for _, group := range statement.RequiredSignerGroups {
+			if err := load(group.TrustStores); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return preloaded, nil
+}
@@ -0,0 +1,123 @@
+// Copyright The Notary Project Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/notaryproject/notation-core-go/testhelper"
+	"github.com/notaryproject/notation-go"
+	"github.com/notaryproject/notation-go/dir"
+	"github.com/notaryproject/notation-go/internal/mock"
+	"github.com/notaryproject/notation-go/signer"
+	"github.com/notaryproject/notation-go/verifier/trustpolicy"
+	"github.com/notaryproject/notation-go/verifier/truststore"
+)
+
+// countingTrustStore is a trust store counting the certificate loads.
+type countingTrustStore struct {
+	truststore.X509TrustStore
+	loads int
+}
+
+func (s *countingTrustStore) GetCertificates(ctx context.Context, storeType truststore.Type, namedStore string) ([]*x509.Certificate, error) {
+	s.loads++
+	return s.X509TrustStore.GetCertificates(ctx, storeType, namedStore)
+}
+
+func TestSandboxed(t *testing.T) {
+	dir.UserConfigDir = "testdata"
+	opts := notation.VerifierVerifyOptions{ArtifactReference: mock.SampleArtifactUri, SignatureMediaType: "application/jose+json"}
+
+	t.Run("online revocation check fails fast", func(t *testing.T) {
+		revokableChain := testhelper.GetRevokableRSAChain(2)
+		internalSigner, err := signer.New(revokableChain[0].PrivateKey, []*x509.Certificate{revokableChain[0].Cert, revokableChain[1].Cert})
+		if err != nil {
+			t.Fatalf("Unexpected error while creating signer: %v", err)
+		}
+		envelopeBlob, _, err := internalSigner.Sign(context.Background(), mock.ImageDescriptor, notation.SignerSignOptions{ExpiryDuration: 24 * time.Hour, SignatureMediaType: "application/jose+json"})
+		if err != nil {
+			t.Fatalf("Unexpected error while generating blob: %v", err)
+		}
+		policyDoc := dummyPolicyDocument()
+		policyDoc.TrustPolicies[0].TrustedIdentities = []string{"*"}
+		v, err := NewWithOptions(&policyDoc, staticTrustStore{revokableChain[1].Cert}, mock.PluginManager{}, VerifierOptions{Sandboxed: true})
+		if err != nil {
+			t.Fatalf("unexpected error while creating verifier: %v", err)
+		}
+
+		trace := &notation.NetworkTrace{}
+		_, err = v.Verify(notation.WithNetworkTrace(context.Background(), trace), mock.ImageDescriptor, envelopeBlob, opts)
+		var sandboxErr SandboxViolationError
+		if !errors.As(err, &sandboxErr) {
+			t.Fatalf("expected SandboxViolationError, but got %v", err)
+		}
+		if requests := trace.Requests(); len(requests) != 0 {
+			t.Fatalf("expected no network requests, but got %v", requests)
+		}
+	})
+
+	t.Run("trust stores are pre-loaded", func(t *testing.T) {
+		policyDoc := dummyPolicyDocument()
+		policyDoc.TrustPolicies[0].SignatureVerification.Override = map[trustpolicy.ValidationType]trustpolicy.ValidationAction{
+			trustpolicy.TypeRevocation: trustpolicy.ActionSkip,
+		}
+		trustStore := &countingTrustStore{X509TrustStore: truststore.NewX509TrustStore(dir.ConfigFS())}
+		v, err := NewWithOptions(&policyDoc, trustStore, mock.PluginManager{}, VerifierOptions{Sandboxed: true})
+		if err != nil {
+			t.Fatalf("unexpected error while creating verifier: %v", err)
+		}
+		loads := trustStore.loads
+		if loads != len(policyDoc.TrustPolicies[0].TrustStores) {
+			t.Fatalf("expected %d trust store loads, but got %d", len(policyDoc.TrustPolicies[0].TrustStores), loads)
+		}
+		if _, err := v.Verify(context.Background(), mock.ImageDescriptor, mock.MockCaValidSigEnv, opts); err != nil {
+			t.Fatalf("expected verification to succeed, but got %v", err)
+		}
+		if trustStore.loads != loads {
+			t.Fatalf("expected no trust store loads during verification, but got %d", trustStore.loads-loads)
+		}
+	})
+
+	t.Run("verification plugin is not allowed", func(t *testing.T) {
+		policyDoc := dummyPolicyDocument()
+		policyDoc.TrustPolicies[0].SignatureVerification.Override = map[trustpolicy.ValidationType]trustpolicy.ValidationAction{
+			trustpolicy.TypeRevocation: trustpolicy.ActionSkip,
+		}
+		v, err := NewWithOptions(&policyDoc, truststore.NewX509TrustStore(dir.ConfigFS()), mock.PluginManager{}, VerifierOptions{Sandboxed: true})
+		if err != nil {
+			t.Fatalf("unexpected error while creating verifier: %v", err)
+		}
+		_, err = v.Verify(context.Background(), mock.ImageDescriptor, mock.MockCaPluginSigEnv, opts)
+		var sandboxErr SandboxViolationError
+		if !errors.As(err, &sandboxErr) {
+			t.Fatalf("expected SandboxViolationError, but got %v", err)
+		}
+	})
+}
+
+func TestPreloadedTrustStore(t *testing.T) {
+	trustStore := preloadedTrustStore{"ca:valid-trust-store": nil}
+	if _, err := trustStore.GetCertificates(context.Background(), truststore.TypeCA, "valid-trust-store"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err := trustStore.GetCertificates(context.Background(), truststore.TypeCA, "other-trust-store")
+	if err == nil || err.Error() != `trust store "ca:other-trust-store" is not pre-loaded, loading trust stores is not allowed in sandboxed mode` {
+		t.Fatalf("expected not pre-loaded error, but got %v", err)
+	}
+}
@@ -0,0 +1,367 @@
+// Copyright The Notary Project Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/notaryproject/notation-core-go/signature"
+)
+
+var (
+	// oidSignedData is the content type of CMS SignedData as defined in
+	// RFC 5652.
+	oidSignedData = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+
+	// oidTSTInfo is the content type of the TSTInfo as defined in RFC 3161.
+	oidTSTInfo = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 1, 4}
+
+	// oidContentType and oidMessageDigest are the CMS signed attributes
+	// as defined in RFC 5652 section 11.
+	oidContentType   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 3}
+	oidMessageDigest = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 4}
+
+	// oidRSASSAPSS is the RSASSA-PSS signature algorithm as defined in
+	// RFC 4055.
+	oidRSASSAPSS = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 10}
+)
+
+// timestampHashAlgorithms maps the OIDs of the hash algorithms that may be
+// used by timestamp tokens to the hash functions.
+var timestampHashAlgorithms = map[string]crypto.Hash{
+	"1.2.840.113549.2.5":      crypto.MD5,
+	"1.3.14.3.2.26":           crypto.SHA1,
+	"2.16.840.1.101.3.4.2.4":  crypto.SHA224,
+	"2.16.840.1.101.3.4.2.1":  crypto.SHA256,
+	"2.16.840.1.101.3.4.2.2":  crypto.SHA384,
+	"2.16.840.1.101.3.4.2.3":  crypto.SHA512,
+	"2.16.840.1.101.3.4.2.8":  crypto.SHA3_256,
+	"2.16.840.1.101.3.4.2.9":  crypto.SHA3_384,
+	"2.16.840.1.101.3.4.2.10": crypto.SHA3_512,
+}
+
+// contentInfo is the CMS ContentInfo as defined in RFC 5652 section 3.
+type contentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	// Content is the [0] EXPLICIT wrapper of the content
+	Content asn1.RawValue `asn1:"tag:0"`
+}
+
+// signedData is the CMS SignedData as defined in RFC 5652 section 5.1.
+type signedData struct {
+	Version          int
+	DigestAlgorithms []pkix.AlgorithmIdentifier `asn1:"set"`
+	EncapContentInfo encapsulatedContentInfo
+	Certificates     asn1.RawValue   `asn1:"optional,tag:0"`
+	CRLs             asn1.RawValue   `asn1:"optional,tag:1"`
+	SignerInfos      []cmsSignerInfo `asn1:"set"`
+}
+
+// encapsulatedContentInfo is the CMS EncapsulatedContentInfo as defined in
+// RFC 5652 section 5.2.
+type encapsulatedContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     []byte `asn1:"explicit,optional,tag:0"`
+}
+
+// cmsSignerInfo is the CMS SignerInfo as defined in RFC 5652 section 5.3.
+type cmsSignerInfo struct {
+	Version            int
+	SID                asn1.RawValue
+	DigestAlgorithm    pkix.AlgorithmIdentifier
+	SignedAttrs        asn1.RawValue `asn1:"optional,tag:0"`
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	Signature          []byte
+	UnsignedAttrs      asn1.RawValue `asn1:"optional,tag:1"`
+}
+
+// tstInfo is the leading part of the TSTInfo as defined in RFC 3161
+// section 2.4.2.
+type tstInfo struct {
+	Version        int
+	Policy         asn1.ObjectIdentifier
+	MessageImprint messageImprint
+	SerialNumber   *big.Int
+	GenTime        time.Time `asn1:"generalized"`
+}
+
+// messageImprint is the MessageImprint as defined in RFC 3161 section 2.4.1.
+type messageImprint struct {
+	HashAlgorithm pkix.AlgorithmIdentifier
+	HashedMessage []byte
+}
+
+// issuerAndSerialNumber identifies a certificate as defined in RFC 5652
+// section 10.2.4.
+type issuerAndSerialNumber struct {
+	Issuer       asn1.RawValue
+	SerialNumber *big.Int
+}
+
+// attribute is a CMS attribute as defined in RFC 5652 section 5.3.
+type attribute struct {
+	Type   asn1.ObjectIdentifier
+	Values asn1.RawValue `asn1:"set"`
+}
+
+// parseTimestampToken parses the signed data and the TSTInfo of the RFC 3161
+// timestamp token.
+func parseTimestampToken(token []byte) (*signedData, *tstInfo, error) {
+	var info contentInfo
+	if rest, err := asn1.Unmarshal(token, &info); err != nil {
+		return nil, nil, fmt.Errorf("malformed timestamp token: %w", err)
+	} else if len(rest) > 0 {
+		return nil, nil, errors.New("malformed timestamp token: trailing data")
+	}
+	if !info.ContentType.Equal(oidSignedData) {
+		return nil, nil, fmt.Errorf("malformed timestamp token: unexpected content type %s", info.ContentType)
+	}
+	var sd signedData
+	if _, err := asn1.Unmarshal(info.Content.Bytes, &sd); err != nil {
+		return nil, nil, fmt.Errorf("malformed timestamp token: %w", err)
+	}
+	var tst tstInfo
+	if _, err := asn1.Unmarshal(sd.EncapContentInfo.Content, &tst); err != nil {
+		return nil, nil, fmt.Errorf("malformed timestamp token info: %w", err)
+	}
+	if len(sd.SignerInfos) == 0 {
+		return nil, nil, errors.New("malformed timestamp token: signer info is not present")
+	}
+	return &sd, &tst, nil
+}
+
+// timestampHash returns the weakest hash algorithm used by the RFC 3161
+// timestamp token, among the hash algorithm of the message imprint and the
+// digest algorithms of the signers of the token.
+func timestampHash(token []byte) (crypto.Hash, error) {
+	sd, tst, err := parseTimestampToken(token)
+	if err != nil {
+		return 0, err
+	}
+
+	weakest, err := lookupTimestampHash(tst.MessageImprint.HashAlgorithm)
+	if err != nil {
+		return 0, err
+	}
+	for _, signer := range sd.SignerInfos {
+		hash, err := lookupTimestampHash(signer.DigestAlgorithm)
+		if err != nil {
+			return 0, err
+		}
+		if hash.Size() < weakest.Size() {
+			weakest = hash
+		}
+	}
+	return weakest, nil
+}
+
+func lookupTimestampHash(alg pkix.AlgorithmIdentifier) (crypto.Hash, error) {
+	hash, ok := timestampHashAlgorithms[alg.Algorithm.String()]
+	if !ok {
+		return 0, fmt.Errorf("timestamp token uses an unknown hash algorithm %s", alg.Algorithm)
+	}
+	return hash, nil
+}
+
+// verifyTimestampAlgorithmStrength verifies that the hash algorithms used by
+// the timestamp token of the signature are at least as strong as the hash
+// algorithm of the signature algorithm. It does nothing if the signature is
+// not timestamped.
+func verifyTimestampAlgorithmStrength(signerInfo *signature.SignerInfo) error {
+	token := signerInfo.UnsignedAttributes.TimestampSignature
+	if len(token) == 0 {
+		return nil
+	}
+	tsHash, err := timestampHash(token)
+	if err != nil {
+		return err
+	}
+	sigHash := signerInfo.SignatureAlgorithm.Hash()
+	if tsHash.Size() < sigHash.Size() {
+		return fmt.Errorf("timestamp token uses hash algorithm %s which is weaker than the hash algorithm %s of the signature algorithm", tsHash, sigHash)
+	}
+	return nil
+}
+
+// verifyTimestampToken verifies the RFC 3161 timestamp token over the
+// signature value and returns the time at which the token was generated.
+// The token must be signed by a timestamping certificate that chains up to
+// one of the tsaRoots and is valid at the generation time.
+func verifyTimestampToken(token, signatureValue []byte, tsaRoots []*x509.Certificate) (time.Time, error) {
+	sd, tst, err := parseTimestampToken(token)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !sd.EncapContentInfo.ContentType.Equal(oidTSTInfo) {
+		return time.Time{}, fmt.Errorf("timestamp token has an unexpected content type %s", sd.EncapContentInfo.ContentType)
+	}
+	if len(sd.SignerInfos) != 1 {
+		return time.Time{}, fmt.Errorf("timestamp token has %d signers, it must have exactly one", len(sd.SignerInfos))
+	}
+
+	// the message imprint must be the hash of the signature value
+	imprintHash, err := lookupSecureTimestampHash(tst.MessageImprint.HashAlgorithm)
+	if err != nil {
+		return time.Time{}, err
+	}
+	h := imprintHash.New()
+	h.Write(signatureValue)
+	if !bytes.Equal(h.Sum(nil), tst.MessageImprint.HashedMessage) {
+		return time.Time{}, errors.New("timestamp token message imprint does not match the signature")
+	}
+
+	// the timestamping certificate must be included in the token
+	certs, err := x509.ParseCertificates(sd.Certificates.Bytes)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("malformed timestamp token certificates: %w", err)
+	}
+	signer := sd.SignerInfos[0]
+	tsaCert, err := findTimestampSigner(signer.SID, certs)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if err := verifyTimestampSignerInfo(&signer, sd.EncapContentInfo.Content, tsaCert); err != nil {
+		return time.Time{}, err
+	}
+
+	// the timestamping certificate must be trusted at the generation time
+	roots, intermediates := x509.NewCertPool(), x509.NewCertPool()
+	for _, cert := range tsaRoots {
+		roots.AddCert(cert)
+	}
+	for _, cert := range certs {
+		intermediates.AddCert(cert)
+	}
+	if _, err := tsaCert.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   tst.GenTime,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageTimeStamping},
+	}); err != nil {
+		return time.Time{}, fmt.Errorf("timestamping certificate %q is not trusted: %w", tsaCert.Subject, err)
+	}
+	return tst.GenTime, nil
+}
+
+// findTimestampSigner returns the certificate identified by the signer
+// identifier of the timestamp token.
+func findTimestampSigner(sid asn1.RawValue, certs []*x509.Certificate) (*x509.Certificate, error) {
+	switch {
+	case sid.Class == asn1.ClassUniversal && sid.Tag == asn1.TagSequence:
+		var ias issuerAndSerialNumber
+		if _, err := asn1.Unmarshal(sid.FullBytes, &ias); err != nil {
+			return nil, fmt.Errorf("malformed timestamp token signer identifier: %w", err)
+		}
+		for _, cert := range certs {
+			if bytes.Equal(cert.RawIssuer, ias.Issuer.FullBytes) && cert.SerialNumber.Cmp(ias.SerialNumber) == 0 {
+				return cert, nil
+			}
+		}
+	case sid.Class == asn1.ClassContextSpecific && sid.Tag == 0:
+		for _, cert := range certs {
+			if len(cert.SubjectKeyId) > 0 && bytes.Equal(cert.SubjectKeyId, sid.Bytes) {
+				return cert, nil
+			}
+		}
+	default:
+		return nil, errors.New("malformed timestamp token signer identifier")
+	}
+	return nil, errors.New("timestamping certificate is not present in the timestamp token")
+}
+
+// verifyTimestampSignerInfo verifies the signed attributes and the signature
+// of the signer of the timestamp token over the TSTInfo content.
+func verifyTimestampSignerInfo(signer *cmsSignerInfo, content []byte, cert *x509.Certificate) error {
+	if len(signer.SignedAttrs.FullBytes) == 0 {
+		return errors.New("timestamp token signed attributes are not present")
+	}
+	hash, err := lookupSecureTimestampHash(signer.DigestAlgorithm)
+	if err != nil {
+		return err
+	}
+
+	var contentType asn1.ObjectIdentifier
+	var messageDigest []byte
+	for rest := signer.SignedAttrs.Bytes; len(rest) > 0; {
+		var attr attribute
+		if rest, err = asn1.Unmarshal(rest, &attr); err != nil {
+			return fmt.Errorf("malformed timestamp token signed attributes: %w", err)
+		}
+		switch {
+		case attr.Type.Equal(oidContentType):
+			_, err = asn1.Unmarshal(attr.Values.Bytes, &contentType)
+		case attr.Type.Equal(oidMessageDigest):
+			_, err = asn1.Unmarshal(attr.Values.Bytes, &messageDigest)
+		}
+		if err != nil {
+			return fmt.Errorf("malformed timestamp token signed attribute %s: %w", attr.Type, err)
+		}
+	}
+	if !contentType.Equal(oidTSTInfo) {
+		return errors.New("timestamp token content type attribute does not match the content")
+	}
+	h := hash.New()
+	h.Write(content)
+	if !bytes.Equal(h.Sum(nil), messageDigest) {
+		return errors.New("timestamp token message digest attribute does not match the content")
+	}
+
+	// the signature is computed over the DER encoding of the signed
+	// attributes as a SET OF, rather than the [0] IMPLICIT tag
+	signedAttrs := append([]byte{asn1.TagSet | 0x20}, signer.SignedAttrs.FullBytes[1:]...)
+	h = hash.New()
+	h.Write(signedAttrs)
+	digest := h.Sum(nil)
+	switch pub := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		if signer.SignatureAlgorithm.Algorithm.Equal(oidRSASSAPSS) {
+			err = rsa.VerifyPSS(pub, hash, digest, signer.Signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthAuto})
+		} else {
+			err = rsa.VerifyPKCS1v15(pub, hash, digest, signer.Signature)
+		}
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(pub, digest, signer.Signature) {
+			err = errors.New("ECDSA verification failure")
+		}
+	default:
+		err = fmt.Errorf("unsupported public key type %T", pub)
+	}
+	if err != nil {
+		return fmt.Errorf("timestamp token signature is invalid: %w", err)
+	}
+	return nil
+}
+
+// lookupSecureTimestampHash returns the hash function of the hash algorithm
+// used to verify a timestamp token, rejecting the broken ones.
+func lookupSecureTimestampHash(alg pkix.AlgorithmIdentifier) (crypto.Hash, error) {
+	hash, err := lookupTimestampHash(alg)
+	if err != nil {
+		return 0, err
+	}
+	if hash == crypto.MD5 || hash == crypto.SHA1 || !hash.Available() {
+		return 0, fmt.Errorf("timestamp token uses an unsupported hash algorithm %s", hash)
+	}
+	return hash, nil
+}
@@ -0,0 +1,300 @@
+// Copyright The Notary Project Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/notaryproject/notation-core-go/signature"
+	"github.com/notaryproject/notation-core-go/testhelper"
+)
+
+var (
+	oidSHA1   = asn1.ObjectIdentifier{1, 3, 14, 3, 2, 26}
+	oidSHA256 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidSHA384 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 2}
+)
+
+// createTimestampToken creates a minimal RFC 3161 timestamp token with the
+// given message imprint and signer digest algorithms. The token is not
+// signed.
+func createTimestampToken(t *testing.T, imprintAlg, digestAlg asn1.ObjectIdentifier) []byte {
+	t.Helper()
+	tst, err := asn1.Marshal(tstInfo{
+		Version: 1,
+		Policy:  asn1.ObjectIdentifier{1, 2, 3},
+		MessageImprint: messageImprint{
+			HashAlgorithm: pkix.AlgorithmIdentifier{Algorithm: imprintAlg},
+			HashedMessage: []byte("hashed message"),
+		},
+		SerialNumber: big.NewInt(1),
+		GenTime:      time.Now().UTC().Truncate(time.Second),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sid, err := asn1.Marshal(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sd, err := asn1.Marshal(signedData{
+		Version:          3,
+		DigestAlgorithms: []pkix.AlgorithmIdentifier{{Algorithm: digestAlg}},
+		EncapContentInfo: encapsulatedContentInfo{
+			ContentType: oidTSTInfo,
+			Content:     tst,
+		},
+		SignerInfos: []cmsSignerInfo{{
+			Version:            1,
+			SID:                asn1.RawValue{FullBytes: sid},
+			DigestAlgorithm:    pkix.AlgorithmIdentifier{Algorithm: digestAlg},
+			SignatureAlgorithm: pkix.AlgorithmIdentifier{Algorithm: asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 1}},
+			Signature:          []byte("signature"),
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := asn1.Marshal(contentInfo{
+		ContentType: oidSignedData,
+		Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: sd},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+// createTimestampingCertificate creates a timestamping certificate issued by
+// the root certificate.
+func createTimestampingCertificate(t *testing.T, root testhelper.RSACertTuple) testhelper.RSACertTuple {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 3072)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "Timestamp Authority", Organization: []string{"Notary"}, Province: []string{"WA"}, Country: []string{"US"}},
+		NotBefore:    root.Cert.NotBefore,
+		NotAfter:     root.Cert.NotAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageTimeStamping},
+		SubjectKeyId: []byte("timestamp authority"),
+	}
+	certBytes, err := x509.CreateCertificate(rand.Reader, template, root.Cert, &key.PublicKey, root.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(certBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testhelper.RSACertTuple{Cert: cert, PrivateKey: key}
+}
+
+// createSignedTimestampToken creates an RFC 3161 timestamp token over the
+// signature value, generated at genTime and signed by the timestamping
+// certificate.
+func createSignedTimestampToken(t *testing.T, signatureValue []byte, genTime time.Time, tsa testhelper.RSACertTuple) []byte {
+	t.Helper()
+	imprint := crypto.SHA256.New()
+	imprint.Write(signatureValue)
+	tst, err := asn1.Marshal(tstInfo{
+		Version: 1,
+		Policy:  asn1.ObjectIdentifier{1, 2, 3},
+		MessageImprint: messageImprint{
+			HashAlgorithm: pkix.AlgorithmIdentifier{Algorithm: oidSHA256},
+			HashedMessage: imprint.Sum(nil),
+		},
+		SerialNumber: big.NewInt(1),
+		GenTime:      genTime.UTC().Truncate(time.Second),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// sign the content type and the message digest of the TSTInfo
+	contentDigest := crypto.SHA256.New()
+	contentDigest.Write(tst)
+	var attrs []byte
+	for _, attr := range []struct {
+		oid   asn1.ObjectIdentifier
+		value any
+	}{
+		{oidContentType, oidTSTInfo},
+		{oidMessageDigest, contentDigest.Sum(nil)},
+	} {
+		value, err := asn1.Marshal(attr.value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		encoded, err := asn1.Marshal(attribute{
+			Type:   attr.oid,
+			Values: asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true, Bytes: value},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		attrs = append(attrs, encoded...)
+	}
+	signedAttrs, err := asn1.Marshal(asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true, Bytes: attrs})
+	if err != nil {
+		t.Fatal(err)
+	}
+	attrsDigest := crypto.SHA256.New()
+	attrsDigest.Write(signedAttrs)
+	sig, err := rsa.SignPKCS1v15(rand.Reader, tsa.PrivateKey, crypto.SHA256, attrsDigest.Sum(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sd, err := asn1.Marshal(signedData{
+		Version:          3,
+		DigestAlgorithms: []pkix.AlgorithmIdentifier{{Algorithm: oidSHA256}},
+		EncapContentInfo: encapsulatedContentInfo{
+			ContentType: oidTSTInfo,
+			Content:     tst,
+		},
+		Certificates: asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: tsa.Cert.Raw},
+		SignerInfos: []cmsSignerInfo{{
+			Version:            3,
+			SID:                asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, Bytes: tsa.Cert.SubjectKeyId},
+			DigestAlgorithm:    pkix.AlgorithmIdentifier{Algorithm: oidSHA256},
+			SignedAttrs:        asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: attrs},
+			SignatureAlgorithm: pkix.AlgorithmIdentifier{Algorithm: asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 1}},
+			Signature:          sig,
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := asn1.Marshal(contentInfo{
+		ContentType: oidSignedData,
+		Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: sd},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestVerifyTimestampToken(t *testing.T) {
+	now := time.Now()
+	tsaRoot := createRotationCertificate(t, "Timestamp Authority Root", now.AddDate(-1, 0, 0), now.AddDate(1, 0, 0), nil)
+	tsa := createTimestampingCertificate(t, tsaRoot)
+	signatureValue := []byte("signature value")
+	token := createSignedTimestampToken(t, signatureValue, now, tsa)
+
+	genTime, err := verifyTimestampToken(token, signatureValue, []*x509.Certificate{tsaRoot.Cert})
+	if err != nil {
+		t.Fatalf("expected timestamp token to be verified, but got %v", err)
+	}
+	if want := now.UTC().Truncate(time.Second); !genTime.Equal(want) {
+		t.Fatalf("expected generation time %v, but got %v", want, genTime)
+	}
+
+	// tamper the policy of the TSTInfo
+	tampered := bytes.Replace(token, []byte{0x06, 0x02, 0x2a, 0x03}, []byte{0x06, 0x02, 0x2a, 0x04}, 1)
+	tests := []struct {
+		name           string
+		token          []byte
+		signatureValue []byte
+		roots          []*x509.Certificate
+		wantErr        string
+	}{
+		{"different signature", token, []byte("other signature value"), []*x509.Certificate{tsaRoot.Cert}, "message imprint does not match"},
+		{"untrusted timestamping certificate", token, signatureValue, []*x509.Certificate{testhelper.GetECRootCertificate().Cert}, "is not trusted"},
+		{"unsigned token", createTimestampToken(t, oidSHA256, oidSHA256), signatureValue, []*x509.Certificate{tsaRoot.Cert}, "message imprint does not match"},
+		{"tampered token", tampered, signatureValue, []*x509.Certificate{tsaRoot.Cert}, "message digest attribute does not match"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := verifyTimestampToken(tt.token, tt.signatureValue, tt.roots)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, but got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestVerifyTimestampAlgorithmStrength(t *testing.T) {
+	tests := []struct {
+		name      string
+		algorithm signature.Algorithm
+		token     []byte
+		wantErr   bool
+	}{
+		{
+			name:      "not timestamped",
+			algorithm: signature.AlgorithmPS384,
+		},
+		{
+			name:      "matched strength",
+			algorithm: signature.AlgorithmPS256,
+			token:     createTimestampToken(t, oidSHA256, oidSHA256),
+		},
+		{
+			name:      "stronger timestamp",
+			algorithm: signature.AlgorithmES256,
+			token:     createTimestampToken(t, oidSHA384, oidSHA384),
+		},
+		{
+			name:      "downgraded message imprint",
+			algorithm: signature.AlgorithmPS384,
+			token:     createTimestampToken(t, oidSHA256, oidSHA384),
+			wantErr:   true,
+		},
+		{
+			name:      "downgraded signer digest",
+			algorithm: signature.AlgorithmPS256,
+			token:     createTimestampToken(t, oidSHA256, oidSHA1),
+			wantErr:   true,
+		},
+		{
+			name:      "unknown hash algorithm",
+			algorithm: signature.AlgorithmPS256,
+			token:     createTimestampToken(t, asn1.ObjectIdentifier{1, 2, 3, 4}, oidSHA256),
+			wantErr:   true,
+		},
+		{
+			name:      "malformed token",
+			algorithm: signature.AlgorithmPS256,
+			token:     []byte("malformed"),
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signerInfo := &signature.SignerInfo{
+				SignatureAlgorithm: tt.algorithm,
+				UnsignedAttributes: signature.UnsignedAttributes{TimestampSignature: tt.token},
+			}
+			err := verifyTimestampAlgorithmStrength(signerInfo)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("verifyTimestampAlgorithmStrength() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+// Copyright The Notary Project Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trustpolicy
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/notaryproject/notation-go/internal/pkix"
+	"github.com/notaryproject/notation-go/internal/slices"
+	"github.com/notaryproject/notation-go/internal/trustpolicy"
+)
+
+// actionStrength ranks the validation actions from the most to the least
+// permissive.
+var actionStrength = map[ValidationAction]int{
+	ActionSkip:    0,
+	ActionLog:     1,
+	ActionEnforce: 2,
+}
+
+// Violation describes where a trust policy statement is more permissive than
+// the baseline trust policy statement applicable to the same registry scope.
+type Violation struct {
+	// RegistryScope is the registry scope both statements apply to
+	RegistryScope string
+
+	// Statement is the name of the candidate trust policy statement
+	Statement string
+
+	// BaselineStatement is the name of the baseline trust policy statement
+	BaselineStatement string
+
+	// Reason describes how the candidate statement is more permissive
+	Reason string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("trust policy statement %q is more permissive than baseline statement %q for registry scope %q: %s", v.Statement, v.BaselineStatement, v.RegistryScope, v.Reason)
+}
+
+// PolicyViolatesBaseline reports where the candidate trust policy document is
+// more permissive than the baseline trust policy document, i.e. where the
+// statement applicable to a registry scope has a weaker verification level,
+// broader trusted identities or extra trust stores than the baseline
+// statement applicable to the same scope. Both documents are expected to be
+// valid. It returns nil if the candidate does not exceed the baseline.
+func PolicyViolatesBaseline(candidate, baseline *Document) []Violation {
+	if candidate == nil || baseline == nil {
+		return nil
+	}
+
+	scopes := make(map[string]struct{})
+	for _, doc := range []*Document{candidate, baseline} {
+		for _, statement := range doc.TrustPolicies {
+			for _, scope := range statement.RegistryScopes {
+				scopes[scope] = struct{}{}
+			}
+		}
+	}
+	sortedScopes := make([]string, 0, len(scopes))
+	for scope := range scopes {
+		sortedScopes = append(sortedScopes, scope)
+	}
+	sort.Strings(sortedScopes)
+
+	var violations []Violation
+	for _, scope := range sortedScopes {
+		candidateStatement := applicableStatement(candidate, scope)
+		baselineStatement := applicableStatement(baseline, scope)
+		if candidateStatement == nil || baselineStatement == nil {
+			continue
+		}
+		for _, reason := range compareWithBaseline(candidateStatement, baselineStatement) {
+			violations = append(violations, Violation{
+				RegistryScope:     scope,
+				Statement:         candidateStatement.Name,
+				BaselineStatement: baselineStatement.Name,
+				Reason:            reason,
+			})
+		}
+	}
+	return violations
+}
+
+// applicableStatement returns the trust policy statement of the document
+// applicable to the registry scope, see applicableStatementIndex for the
+// precedence rules.
+func applicableStatement(doc *Document, scope string) *TrustPolicy {
+	if i := applicableStatementIndex(doc.TrustPolicies, scope); i >= 0 {
+		return &doc.TrustPolicies[i]
+	}
+	return nil
+}
+
+// compareWithBaseline returns the reasons why the candidate statement is more
+// permissive than the baseline statement.
+func compareWithBaseline(candidate, baseline *TrustPolicy) []string {
+	candidateLevel, err := candidate.SignatureVerification.GetVerificationLevel()
+	if err != nil {
+		return []string{fmt.Sprintf("invalid signatureVerification: %v", err)}
+	}
+	baselineLevel, err := baseline.SignatureVerification.GetVerificationLevel()
+	if err != nil {
+		return nil
+	}
+
+	var reasons []string
+	for _, validationType := range ValidationTypes {
+		candidateAction := candidateLevel.Enforcement[validationType]
+		baselineAction := baselineLevel.Enforcement[validationType]
+		if actionStrength[candidateAction] < actionStrength[baselineAction] {
+			reasons = append(reasons, fmt.Sprintf("%s validation is %q, which is weaker than %q", validationType, candidateAction, baselineAction))
+		}
+	}
+	if baselineLevel.Name == LevelSkip.Name || candidateLevel.Name == LevelSkip.Name {
+		// trust stores and trusted identities are not used
+		return reasons
+	}
+
+	baselineTrustStores := statementTrustStores(baseline)
+	for _, trustStore := range statementTrustStores(candidate) {
+		if !slices.Contains(baselineTrustStores, trustStore) {
+			reasons = append(reasons, fmt.Sprintf("trust store %q is not in the baseline", trustStore))
+		}
+	}
+
+	baselineIdentities := statementTrustedIdentities(baseline)
+	for _, identity := range statementTrustedIdentities(candidate) {
+		if !identityCoveredBy(identity, baselineIdentities) {
+			reasons = append(reasons, fmt.Sprintf("trusted identity %q is broader than the trusted identities of the baseline", identity))
+		}
+	}
+	return reasons
+}
+
+// identityCoveredBy reports whether the trusted identity trusts no more
+// signers than the baseline identities, i.e. the baseline has a wildcard, the
+// same identity, or an x509.subject identity whose attributes are a subset of
+// the attributes of the identity.
+func identityCoveredBy(identity string, baselineIdentities []string) bool {
+	if slices.Contains(baselineIdentities, trustpolicy.Wildcard) || slices.Contains(baselineIdentities, identity) {
+		return true
+	}
+	prefix, value, _ := strings.Cut(identity, ":")
+	if prefix != trustpolicy.X509Subject {
+		return false
+	}
+	dn, err := pkix.ParseDistinguishedName(value)
+	if err != nil {
+		return false
+	}
+	for _, baselineIdentity := range baselineIdentities {
+		baselinePrefix, baselineValue, _ := strings.Cut(baselineIdentity, ":")
+		if baselinePrefix != trustpolicy.X509Subject {
+			continue
+		}
+		baselineDN, err := pkix.ParseDistinguishedName(baselineValue)
+		if err == nil && pkix.IsSubsetDN(baselineDN, dn) {
+			return true
+		}
+	}
+	return false
+}
+
+// statementTrustStores returns the trust stores of the statement and its
+// required signer groups.
+func statementTrustStores(statement *TrustPolicy) []string {
+	trustStores := append([]string(nil), statement.TrustStores...)
+	for _, group := range statement.RequiredSignerGroups {
+		trustStores = append(trustStores, group.TrustStores...)
+	}
+	return trustStores
+}
+
+// statementTrustedIdentities returns the trusted identities of the statement
+// and its required signer groups.
+func statementTrustedIdentities(statement *TrustPolicy) []string {
+	identities := append([]string(nil), statement.TrustedIdentities...)
+	for _, group := range statement.RequiredSignerGroups {
+		identities = append(identities, group.TrustedIdentities...)
+	}
+	return identities
+}
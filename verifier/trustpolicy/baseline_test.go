@@ -0,0 +1,126 @@
+// Copyright The Notary Project Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trustpolicy
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPolicyViolatesBaseline(t *testing.T) {
+	baseline := dummyPolicyDocument()
+	baselineWildcard := dummyPolicyStatement()
+	baselineWildcard.Name = "baseline-wildcard"
+	baselineWildcard.RegistryScopes = []string{"*"}
+	baselineWildcard.SignatureVerification = SignatureVerification{VerificationLevel: "permissive"}
+	baseline.TrustPolicies = append(baseline.TrustPolicies, baselineWildcard)
+
+	t.Run("same policy", func(t *testing.T) {
+		candidate := dummyPolicyDocument()
+		if violations := PolicyViolatesBaseline(&candidate, &baseline); len(violations) != 0 {
+			t.Fatalf("expected no violations, but got %v", violations)
+		}
+	})
+
+	t.Run("stricter policy", func(t *testing.T) {
+		candidate := dummyPolicyDocument()
+		candidate.TrustPolicies[0].TrustStores = []string{"ca:valid-trust-store"}
+		candidate.TrustPolicies[0].TrustedIdentities = []string{"x509.subject:CN=Notation Test Root,O=Notary,L=Seattle,ST=WA,C=US,OU=Build"}
+		if violations := PolicyViolatesBaseline(&candidate, &baseline); len(violations) != 0 {
+			t.Fatalf("expected no violations, but got %v", violations)
+		}
+	})
+
+	t.Run("strict scope downgraded to permissive", func(t *testing.T) {
+		candidate := dummyPolicyDocument()
+		candidate.TrustPolicies[0].Name = "team-statement"
+		candidate.TrustPolicies[0].SignatureVerification = SignatureVerification{VerificationLevel: "permissive"}
+		want := []Violation{
+			{
+				RegistryScope:     "registry.acme-rockets.io/software/net-monitor",
+				Statement:         "team-statement",
+				BaselineStatement: "test-statement-name",
+				Reason:            `authenticTimestamp validation is "log", which is weaker than "enforce"`,
+			},
+			{
+				RegistryScope:     "registry.acme-rockets.io/software/net-monitor",
+				Statement:         "team-statement",
+				BaselineStatement: "test-statement-name",
+				Reason:            `expiry validation is "log", which is weaker than "enforce"`,
+			},
+			{
+				RegistryScope:     "registry.acme-rockets.io/software/net-monitor",
+				Statement:         "team-statement",
+				BaselineStatement: "test-statement-name",
+				Reason:            `revocation validation is "log", which is weaker than "enforce"`,
+			},
+		}
+		if violations := PolicyViolatesBaseline(&candidate, &baseline); !reflect.DeepEqual(violations, want) {
+			t.Fatalf("expected violations %v, but got %v", want, violations)
+		}
+		if got, want := want[0].String(), `trust policy statement "team-statement" is more permissive than baseline statement "test-statement-name" for registry scope "registry.acme-rockets.io/software/net-monitor": authenticTimestamp validation is "log", which is weaker than "enforce"`; got != want {
+			t.Fatalf("expected %q, but got %q", want, got)
+		}
+	})
+
+	t.Run("wildcard scope checked against baseline wildcard", func(t *testing.T) {
+		candidate := dummyPolicyDocument()
+		candidate.TrustPolicies[0].RegistryScopes = []string{"*"}
+		candidate.TrustPolicies[0].SignatureVerification = SignatureVerification{VerificationLevel: "skip"}
+		candidate.TrustPolicies[0].TrustStores = nil
+		candidate.TrustPolicies[0].TrustedIdentities = nil
+		violations := PolicyViolatesBaseline(&candidate, &baseline)
+		// the scope of the baseline strict statement falls back to the
+		// candidate wildcard statement
+		scopes := make(map[string]int)
+		for _, violation := range violations {
+			scopes[violation.RegistryScope]++
+		}
+		if scopes["*"] != 5 || scopes["registry.acme-rockets.io/software/net-monitor"] != 5 {
+			t.Fatalf("unexpected violations %v", violations)
+		}
+	})
+
+	t.Run("broader identities and extra trust stores", func(t *testing.T) {
+		candidate := dummyPolicyDocument()
+		candidate.TrustPolicies[0].TrustStores = []string{"ca:valid-trust-store", "ca:team-trust-store"}
+		candidate.TrustPolicies[0].TrustedIdentities = []string{"x509.subject:O=Notary,ST=WA,C=US"}
+		want := []Violation{
+			{
+				RegistryScope:     "registry.acme-rockets.io/software/net-monitor",
+				Statement:         "test-statement-name",
+				BaselineStatement: "test-statement-name",
+				Reason:            `trust store "ca:team-trust-store" is not in the baseline`,
+			},
+			{
+				RegistryScope:     "registry.acme-rockets.io/software/net-monitor",
+				Statement:         "test-statement-name",
+				BaselineStatement: "test-statement-name",
+				Reason:            `trusted identity "x509.subject:O=Notary,ST=WA,C=US" is broader than the trusted identities of the baseline`,
+			},
+		}
+		if violations := PolicyViolatesBaseline(&candidate, &baseline); !reflect.DeepEqual(violations, want) {
+			t.Fatalf("expected violations %v, but got %v", want, violations)
+		}
+	})
+
+	t.Run("wildcard identity", func(t *testing.T) {
+		candidate := dummyPolicyDocument()
+		candidate.TrustPolicies[0].TrustedIdentities = []string{"*"}
+		violations := PolicyViolatesBaseline(&candidate, &baseline)
+		if len(violations) != 1 || violations[0].Reason != `trusted identity "*" is broader than the trusted identities of the baseline` {
+			t.Fatalf("unexpected violations %v", violations)
+		}
+	})
+}
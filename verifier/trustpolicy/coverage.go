@@ -0,0 +1,123 @@
+// Copyright The Notary Project Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trustpolicy
+
+import (
+	"strings"
+
+	"github.com/notaryproject/notation-go/internal/slices"
+	"github.com/notaryproject/notation-go/internal/trustpolicy"
+)
+
+// Coverage describes how an artifact reference is covered by the trust policy
+// statements of a document.
+type Coverage string
+
+const (
+	// CoverageStatement means the reference is covered by a statement naming
+	// its registry scope.
+	CoverageStatement Coverage = "statement"
+
+	// CoverageWildcard means the reference is covered by the wildcard (*)
+	// statement.
+	CoverageWildcard Coverage = "wildcard"
+
+	// CoverageNone means no statement enforces or logs verification for the
+	// reference, i.e. there is no applicable statement or the applicable
+	// statement skips verification.
+	CoverageNone Coverage = "uncovered"
+)
+
+// ReferenceCoverage is the coverage of a single artifact reference.
+type ReferenceCoverage struct {
+	// Reference is the artifact reference as given
+	Reference string
+
+	// RegistryScope is the registry scope of the reference
+	RegistryScope string
+
+	// Coverage is how the reference is covered
+	Coverage Coverage
+
+	// Statement is the name of the applicable trust policy statement, if any.
+	// It is set for uncovered references whose applicable statement skips
+	// verification.
+	Statement string
+
+	// Error is set if the reference could not be parsed, in which case the
+	// reference is uncovered
+	Error error
+}
+
+// CoverageReport reports which artifact references have an applicable trust
+// policy statement that does not skip verification.
+type CoverageReport struct {
+	// References is the coverage of each reference, in the given order
+	References []ReferenceCoverage
+
+	// UncoveredNamespaces maps the namespace, i.e. the registry scope without
+	// its last path component, of the uncovered references to the number of
+	// uncovered references in it. References that could not be parsed are
+	// not included.
+	UncoveredNamespaces map[string]int
+}
+
+// CoverageReport classifies each artifact reference as covered by a statement
+// naming its registry scope, covered by the wildcard statement, or uncovered.
+// A reference is uncovered if no statement applies to it or the applicable
+// statement skips verification. References may be given with a digest, a tag
+// or as bare repositories, e.g. "registry.io/app@sha256:...",
+// "registry.io/app:v1" or "registry.io/app".
+func (policyDoc *Document) CoverageReport(references []string) CoverageReport {
+	report := CoverageReport{
+		References:          make([]ReferenceCoverage, 0, len(references)),
+		UncoveredNamespaces: make(map[string]int),
+	}
+	for _, reference := range references {
+		refCoverage := ReferenceCoverage{
+			Reference: reference,
+			Coverage:  CoverageNone,
+		}
+		scope := registryScopeFromReference(reference)
+		if err := validateRegistryScopeFormat(scope); err != nil {
+			refCoverage.Error = err
+			report.References = append(report.References, refCoverage)
+			continue
+		}
+		refCoverage.RegistryScope = scope
+
+		if statement := applicableStatement(policyDoc, scope); statement != nil {
+			refCoverage.Statement = statement.Name
+			if statement.SignatureVerification.VerificationLevel != LevelSkip.Name {
+				refCoverage.Coverage = CoverageStatement
+				if slices.Contains(statement.RegistryScopes, trustpolicy.Wildcard) {
+					refCoverage.Coverage = CoverageWildcard
+				}
+			}
+		}
+		if refCoverage.Coverage == CoverageNone {
+			report.UncoveredNamespaces[namespaceOf(scope)]++
+		}
+		report.References = append(report.References, refCoverage)
+	}
+	return report
+}
+
+// namespaceOf returns the registry scope without its last path component.
+func namespaceOf(scope string) string {
+	if i := strings.LastIndex(scope, "/"); i >= 0 {
+		return scope[:i]
+	}
+	return scope
+}
@@ -0,0 +1,83 @@
+// Copyright The Notary Project Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trustpolicy
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCoverageReport(t *testing.T) {
+	policyDoc := dummyPolicyDocument()
+	skipStatement := dummyPolicyStatement()
+	skipStatement.Name = "skip-statement"
+	skipStatement.RegistryScopes = []string{"registry.acme-rockets.io/legacy/app"}
+	skipStatement.SignatureVerification = SignatureVerification{VerificationLevel: "skip"}
+	skipStatement.TrustStores = nil
+	skipStatement.TrustedIdentities = nil
+	policyDoc.TrustPolicies = append(policyDoc.TrustPolicies, skipStatement)
+
+	references := []string{
+		"registry.acme-rockets.io/software/net-monitor@sha256:fe7e9333395060c2f5e63cf36a38fba10176f183b4163a5794e081a480abba5f",
+		"registry.acme-rockets.io/software/net-monitor:v1",
+		"registry.acme-rockets.io/software/web-app",
+		"registry.acme-rockets.io/legacy/app:v2",
+		"registry.acme-rockets.io/legacy/tool",
+		"invalid reference",
+	}
+
+	t.Run("without wildcard statement", func(t *testing.T) {
+		report := policyDoc.CoverageReport(references)
+		want := []ReferenceCoverage{
+			{Reference: references[0], RegistryScope: "registry.acme-rockets.io/software/net-monitor", Coverage: CoverageStatement, Statement: "test-statement-name"},
+			{Reference: references[1], RegistryScope: "registry.acme-rockets.io/software/net-monitor", Coverage: CoverageStatement, Statement: "test-statement-name"},
+			{Reference: references[2], RegistryScope: "registry.acme-rockets.io/software/web-app", Coverage: CoverageNone},
+			{Reference: references[3], RegistryScope: "registry.acme-rockets.io/legacy/app", Coverage: CoverageNone, Statement: "skip-statement"},
+			{Reference: references[4], RegistryScope: "registry.acme-rockets.io/legacy/tool", Coverage: CoverageNone},
+		}
+		if !reflect.DeepEqual(report.References[:5], want) {
+			t.Fatalf("expected coverage %+v, but got %+v", want, report.References[:5])
+		}
+		if invalid := report.References[5]; invalid.Coverage != CoverageNone || invalid.Error == nil {
+			t.Fatalf("expected invalid reference to be uncovered with an error, but got %+v", invalid)
+		}
+		wantNamespaces := map[string]int{
+			"registry.acme-rockets.io/software": 1,
+			"registry.acme-rockets.io/legacy":   2,
+		}
+		if !reflect.DeepEqual(report.UncoveredNamespaces, wantNamespaces) {
+			t.Fatalf("expected uncovered namespaces %v, but got %v", wantNamespaces, report.UncoveredNamespaces)
+		}
+	})
+
+	t.Run("with wildcard statement", func(t *testing.T) {
+		wildcardDoc := policyDoc
+		wildcardStatement := dummyPolicyStatement()
+		wildcardStatement.Name = "wildcard-statement"
+		wildcardStatement.RegistryScopes = []string{"*"}
+		wildcardDoc.TrustPolicies = append(wildcardDoc.TrustPolicies[:len(wildcardDoc.TrustPolicies):len(wildcardDoc.TrustPolicies)], wildcardStatement)
+
+		report := wildcardDoc.CoverageReport(references[:5])
+		want := []Coverage{CoverageStatement, CoverageStatement, CoverageWildcard, CoverageNone, CoverageWildcard}
+		for i, refCoverage := range report.References {
+			if refCoverage.Coverage != want[i] {
+				t.Fatalf("expected reference %q to be covered by %q, but got %q", refCoverage.Reference, want[i], refCoverage.Coverage)
+			}
+		}
+		wantNamespaces := map[string]int{"registry.acme-rockets.io/legacy": 1}
+		if !reflect.DeepEqual(report.UncoveredNamespaces, wantNamespaces) {
+			t.Fatalf("expected uncovered namespaces %v, but got %v", wantNamespaces, report.UncoveredNamespaces)
+		}
+	})
+}
@@ -0,0 +1,93 @@
+// Copyright The Notary Project Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trustpolicy
+
+import "fmt"
+
+// DocumentNotFoundError is used when the trust policy document file does not
+// exist
+type DocumentNotFoundError struct {
+	Path       string
+	InnerError error
+}
+
+func (e DocumentNotFoundError) Error() string {
+	return fmt.Sprintf("trust policy document %q is not present. To create a trust policy, see: %s", e.Path, trustPolicyLink)
+}
+
+func (e DocumentNotFoundError) Unwrap() error {
+	return e.InnerError
+}
+
+// MalformedDocumentError is used when the trust policy document is not a
+// valid JSON document or contains unknown fields. Line and Offset locate the
+// error in the document when known, both are zero otherwise.
+type MalformedDocumentError struct {
+	Line       int
+	Offset     int64
+	InnerError error
+}
+
+func (e MalformedDocumentError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("malformed trust policy at line %d, offset %d: %v", e.Line, e.Offset, e.InnerError)
+	}
+	return fmt.Sprintf("malformed trust policy: %v", e.InnerError)
+}
+
+func (e MalformedDocumentError) Unwrap() error {
+	return e.InnerError
+}
+
+// InvalidDocumentError is used when the trust policy document is well-formed
+// but fails validation
+type InvalidDocumentError struct {
+	InnerError error
+}
+
+func (e InvalidDocumentError) Error() string {
+	return fmt.Sprintf("trust policy validation failed: %v", e.InnerError)
+}
+
+func (e InvalidDocumentError) Unwrap() error {
+	return e.InnerError
+}
+
+// StatementError is used by Document.ValidateAll to attribute a violation to
+// the trust policy statement at Index of the document's TrustPolicies
+type StatementError struct {
+	Index      int
+	Name       string
+	InnerError error
+}
+
+func (e StatementError) Error() string {
+	return e.InnerError.Error()
+}
+
+func (e StatementError) Unwrap() error {
+	return e.InnerError
+}
+
+// InvalidTrustStoreError is used when a trust store value is not of the form
+// <TrustStoreType>:<TrustStoreName> with a supported trust store type and a
+// valid trust store name
+type InvalidTrustStoreError struct {
+	TrustStore string
+	Msg        string
+}
+
+func (e InvalidTrustStoreError) Error() string {
+	return fmt.Sprintf("invalid trust store value %q: %s", e.TrustStore, e.Msg)
+}
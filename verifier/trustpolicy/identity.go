@@ -0,0 +1,98 @@
+// Copyright The Notary Project Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trustpolicy
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/notaryproject/notation-go/internal/pkix"
+	"github.com/notaryproject/notation-go/internal/slices"
+	"github.com/notaryproject/notation-go/internal/trustpolicy"
+)
+
+// VerifyTrustedIdentity verifies that the signing certificate leaf matches
+// one of the trusted identities of the trust policy statement. It returns
+// nil if the statement trusts any identity (*), if the SHA-256 fingerprint
+// of leaf matches an x509.fingerprint.sha256 identity or if the subject of
+// leaf has every RDN attribute of an x509.subject identity, regardless of
+// the order of the attributes. Other identities are ignored, they are
+// verified by plugins.
+func VerifyTrustedIdentity(leaf *x509.Certificate, statement TrustPolicy) error {
+	if slices.Contains(statement.TrustedIdentities, trustpolicy.Wildcard) {
+		return nil
+	}
+	if leaf == nil {
+		return errors.New("signing certificate cannot be nil")
+	}
+
+	var trustedSubjects []string
+	var trustedX509Identities []map[string]string
+	var trustedFingerprints []string
+	for _, identity := range statement.TrustedIdentities {
+		identityPrefix, identityValue, found := strings.Cut(identity, ":")
+		if !found {
+			return fmt.Errorf("trust policy statement %q has trusted identity %q missing separator", statement.Name, identity)
+		}
+
+		// notation natively supports x509.subject and
+		// x509.fingerprint.sha256 identities only
+		if identityPrefix == trustpolicy.X509Subject {
+			// identityValue cannot be empty
+			if identityValue == "" {
+				return fmt.Errorf("trust policy statement %q has trusted identity %q without an identity value", statement.Name, identity)
+			}
+			parsedSubject, err := pkix.ParseDistinguishedName(identityValue)
+			if err != nil {
+				return err
+			}
+			trustedSubjects = append(trustedSubjects, identityValue)
+			trustedX509Identities = append(trustedX509Identities, parsedSubject)
+		} else if identityPrefix == trustpolicy.X509FingerprintSHA256 {
+			trustedFingerprints = append(trustedFingerprints, strings.ToLower(identityValue))
+		}
+	}
+
+	if len(trustedX509Identities) == 0 && len(trustedFingerprints) == 0 {
+		return fmt.Errorf("no x509 trusted identities are configured in the trust policy %q", statement.Name)
+	}
+
+	// match the fingerprint of the certificate
+	if len(trustedFingerprints) > 0 {
+		fingerprint := sha256.Sum256(leaf.Raw)
+		if slices.Contains(trustedFingerprints, hex.EncodeToString(fingerprint[:])) {
+			return nil
+		}
+		if len(trustedX509Identities) == 0 {
+			return fmt.Errorf("signing certificate from the digital signature does not match the X.509 fingerprints %q defined in the trust policy %q", trustedFingerprints, statement.Name)
+		}
+	}
+
+	// parse the certificate subject following rfc 4514 DN syntax
+	leafDN, err := pkix.ParseDistinguishedName(leaf.Subject.String())
+	if err != nil {
+		return fmt.Errorf("error while parsing the certificate subject from the digital signature. error : %q", err)
+	}
+	for _, trustedX509Identity := range trustedX509Identities {
+		if pkix.IsSubsetDN(trustedX509Identity, leafDN) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("signing certificate from the digital signature does not match the X.509 trusted identities %q defined in the trust policy %q, the subject of the signing certificate is %q", trustedSubjects, statement.Name, leaf.Subject.String())
+}
@@ -0,0 +1,77 @@
+// Copyright The Notary Project Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trustpolicy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/notaryproject/notation-core-go/testhelper"
+)
+
+func TestVerifyTrustedIdentity(t *testing.T) {
+	leaf := testhelper.GetRSALeafCertificate().Cert // subject is "CN=Notation Test RSA Leaf Cert,O=Notary,L=Seattle,ST=WA,C=US"
+	fingerprint := sha256.Sum256(leaf.Raw)
+	leafFingerprint := hex.EncodeToString(fingerprint[:])
+
+	tests := []struct {
+		name       string
+		identities []string
+		wantErr    string
+	}{
+		{name: "wildcard", identities: []string{"*"}},
+		{name: "matching subject", identities: []string{"x509.subject:CN=Notation Test RSA Leaf Cert,O=Notary,L=Seattle,ST=WA,C=US"}},
+		{name: "matching subject in another order", identities: []string{"x509.subject:C=US, ST=WA, O=Notary"}},
+		{name: "one matching subject", identities: []string{"x509.subject:C=US,ST=WA,O=Other", "x509.subject:C=US,ST=WA,O=Notary"}},
+		{name: "matching fingerprint", identities: []string{"x509.fingerprint.sha256:" + strings.ToUpper(leafFingerprint)}},
+		{name: "plugin identity alongside a matching subject", identities: []string{"plugin.identity:value", "x509.subject:C=US,ST=WA,O=Notary"}},
+		{
+			name:       "no matching subject",
+			identities: []string{"x509.subject:C=US,ST=WA,O=Other", "x509.subject:CN=Notation Test RSA Leaf Cert,O=Notary,L=Redmond,ST=WA,C=US"},
+			wantErr:    `signing certificate from the digital signature does not match the X.509 trusted identities ["C=US,ST=WA,O=Other" "CN=Notation Test RSA Leaf Cert,O=Notary,L=Redmond,ST=WA,C=US"] defined in the trust policy "test-statement-name", the subject of the signing certificate is "CN=Notation Test RSA Leaf Cert,O=Notary,L=Seattle,ST=WA,C=US"`,
+		},
+		{
+			name:       "no matching fingerprint",
+			identities: []string{"x509.fingerprint.sha256:" + strings.Repeat("0", 64)},
+			wantErr:    `signing certificate from the digital signature does not match the X.509 fingerprints ["` + strings.Repeat("0", 64) + `"] defined in the trust policy "test-statement-name"`,
+		},
+		{
+			name:       "no x509 identities",
+			identities: []string{"plugin.identity:value"},
+			wantErr:    `no x509 trusted identities are configured in the trust policy "test-statement-name"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statement := dummyPolicyStatement()
+			statement.TrustedIdentities = tt.identities
+			err := VerifyTrustedIdentity(leaf, statement)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("VerifyTrustedIdentity() error = %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("VerifyTrustedIdentity() error = %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+
+	if err := VerifyTrustedIdentity(nil, dummyPolicyStatement()); err == nil {
+		t.Fatal("VerifyTrustedIdentity() should return error for a nil certificate")
+	}
+}
@@ -16,14 +16,21 @@
 package trustpolicy
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
+	"sync"
+	"unicode"
 
 	"github.com/notaryproject/notation-go/dir"
 	"github.com/notaryproject/notation-go/internal/file"
@@ -36,6 +43,11 @@ import (
 // trustPolicyLink is a tutorial link for creating Notation's trust policy.
 const trustPolicyLink = "https://notaryproject.dev/docs/quickstart/#create-a-trust-policy"
 
+// prefixScopeSuffix is the suffix of a wildcard prefix registry scope, e.g.
+// "registry.example.com/team/*" applies to every repository under
+// "registry.example.com/team".
+const prefixScopeSuffix = "/*"
+
 // ValidationType is an enum for signature verification types such as Integrity,
 // Authenticity, etc.
 type ValidationType string
@@ -136,7 +148,33 @@ var (
 
 var supportedPolicyVersions = []string{"1.0"}
 
-// Document represents a trustPolicy.json document
+// Validator validates a policy document against custom rules, e.g. an
+// organization-wide policy schema extension.
+type Validator func(policyDoc *Document) error
+
+var (
+	validatorsMu sync.RWMutex
+	validators   []Validator
+)
+
+// RegisterValidator registers a custom validator invoked by
+// Document.Validate after the built-in rules pass. Validators run in
+// registration order and their errors are aggregated.
+func RegisterValidator(validator Validator) {
+	if validator == nil {
+		return
+	}
+	validatorsMu.Lock()
+	defer validatorsMu.Unlock()
+
+	validators = append(validators, validator)
+}
+
+// Document represents a trustPolicy.json document.
+//
+// A Document is safe for concurrent reads, e.g. by GetApplicableTrustPolicy,
+// as long as it is not modified. Callers that want to modify a shared
+// Document should modify a copy returned by Clone instead.
 type Document struct {
 	// Version of the policy document
 	Version string `json:"version"`
@@ -150,7 +188,13 @@ type TrustPolicy struct {
 	// Name of the policy statement
 	Name string `json:"name"`
 
-	// RegistryScopes that this policy statement affects
+	// RegistryScopes that this policy statement affects. Registry domains
+	// are case-insensitive: scopes and artifact references are matched with
+	// the registry domain lowercased, so "Registry.Example.com/app" applies
+	// to "registry.example.com/app". Repository paths are matched as is,
+	// they must be lowercase and cannot have empty segments or a trailing
+	// slash. Scopes that only differ in the case of the registry domain are
+	// duplicates.
 	RegistryScopes []string `json:"registryScopes"`
 
 	// SignatureVerification setting for this policy statement
@@ -159,8 +203,33 @@ type TrustPolicy struct {
 	// TrustStores this policy statement uses
 	TrustStores []string `json:"trustStores,omitempty"`
 
-	// TrustedIdentities this policy statement pins
+	// TrustedIdentities this policy statement pins. A signature is trusted if
+	// its signing certificate matches any of them, so listing the identities
+	// of both the old and the new signing certificates accepts artifacts
+	// signed on either side of a key rotation. A signature of the old,
+	// expired certificate still verifies if its signing time is authentic,
+	// i.e. it uses the notary.x509.signingAuthority signing scheme or is
+	// timestamped.
 	TrustedIdentities []string `json:"trustedIdentities,omitempty"`
+
+	// RequiredSignerGroups this policy statement requires signatures from.
+	// The artifact must be signed by at least one signer of every group. If
+	// set, trust stores and trusted identities are specified per group
+	// instead of for the whole statement.
+	RequiredSignerGroups []SignerGroup `json:"requiredSignerGroups,omitempty"`
+}
+
+// SignerGroup represents a group of signers in a trust policy statement, e.g.
+// the signers rooted in the certificate authority of the build system.
+type SignerGroup struct {
+	// Name of the signer group
+	Name string `json:"name"`
+
+	// TrustStores this signer group uses
+	TrustStores []string `json:"trustStores"`
+
+	// TrustedIdentities this signer group pins
+	TrustedIdentities []string `json:"trustedIdentities"`
 }
 
 // SignatureVerification represents verification configuration in a trust policy
@@ -169,80 +238,173 @@ type SignatureVerification struct {
 	Override          map[ValidationType]ValidationAction `json:"override,omitempty"`
 }
 
+// UnmarshalJSON decodes the signature verification configuration either from
+// its object form, e.g. {"level":"strict","override":{"expiry":"log"}}, or
+// from the shorthand string form naming the verification level, e.g.
+// "strict", which has no overrides. Unknown fields of the object form are
+// rejected.
+func (signatureVerification *SignatureVerification) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		var level string
+		if err := json.Unmarshal(data, &level); err != nil {
+			return err
+		}
+		*signatureVerification = SignatureVerification{VerificationLevel: level}
+		return nil
+	}
+
+	// signatureVerificationObject has no UnmarshalJSON method, so decoding
+	// into it does not recurse. The decoder of the enclosing document does
+	// not propagate its settings to UnmarshalJSON, unknown fields are
+	// rejected here so that a misspelled override is not silently dropped.
+	type signatureVerificationObject SignatureVerification
+	var object signatureVerificationObject
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&object); err != nil {
+		return err
+	}
+	*signatureVerification = SignatureVerification(object)
+	return nil
+}
+
 // Validate validates a policy document according to its version's rule set.
-// if any rule is violated, returns an error
+// if any rule is violated, returns an error. It returns the first error
+// reported by ValidateAll.
 func (policyDoc *Document) Validate() error {
+	if errs := policyDoc.ValidateAll(); len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+// ValidateAll validates a policy document according to its version's rule
+// set and returns every violation found, in document order, or nil if the
+// document is valid. Violations of a statement are reported as
+// StatementError so that they can be mapped back to the statement. The
+// document version and statements are checked first, the custom validators
+// registered with RegisterValidator only run against documents that pass
+// the built-in rules.
+func (policyDoc *Document) ValidateAll() []error {
 	// sanity check
 	if policyDoc == nil {
-		return errors.New("trust policy document cannot be nil")
+		return []error{errors.New("trust policy document cannot be nil")}
 	}
 
 	// Validate Version
 	if policyDoc.Version == "" {
-		return errors.New("trust policy document is missing or has empty version, it must be specified")
+		return []error{errors.New("trust policy document is missing or has empty version, it must be specified")}
 	}
 	if !slices.Contains(supportedPolicyVersions, policyDoc.Version) {
-		return fmt.Errorf("trust policy document uses unsupported version %q", policyDoc.Version)
+		return []error{fmt.Errorf("trust policy document uses unsupported version %q", policyDoc.Version)}
 	}
 
 	// Validate the policy according to 1.0 rules
 	if len(policyDoc.TrustPolicies) == 0 {
-		return errors.New("trust policy document can not have zero trust policy statements")
+		return []error{errors.New("trust policy document can not have zero trust policy statements")}
 	}
 
-	policyStatementNameCount := make(map[string]int)
+	var errs []error
+	for i, statement := range policyDoc.TrustPolicies {
+		for _, err := range validateStatement(statement) {
+			errs = append(errs, StatementError{Index: i, Name: statement.Name, InnerError: err})
+		}
+	}
 
-	for _, statement := range policyDoc.TrustPolicies {
+	// Verify registry scopes are valid
+	errs = append(errs, validateRegistryScopes(policyDoc)...)
 
-		// Verify statement name is valid
+	// Verify unique policy statement names across the policy document
+	policyStatementNameCount := make(map[string]int)
+	for i, statement := range policyDoc.TrustPolicies {
 		if statement.Name == "" {
-			return errors.New("a trust policy statement is missing a name, every statement requires a name")
+			continue
 		}
 		policyStatementNameCount[statement.Name]++
-
-		// Verify signature verification is valid
-		verificationLevel, err := statement.SignatureVerification.GetVerificationLevel()
-		if err != nil {
-			return fmt.Errorf("trust policy statement %q has invalid signatureVerification: %w", statement.Name, err)
+		if policyStatementNameCount[statement.Name] == 2 {
+			errs = append(errs, StatementError{
+				Index:      i,
+				Name:       statement.Name,
+				InnerError: fmt.Errorf("multiple trust policy statements use the same name %q, statement names must be unique", statement.Name),
+			})
 		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
 
-		// Any signature verification other than "skip" needs a trust store and
-		// trusted identities
-		if verificationLevel.Name == "skip" {
-			if len(statement.TrustStores) > 0 || len(statement.TrustedIdentities) > 0 {
-				return fmt.Errorf("trust policy statement %q is set to skip signature verification but configured with trust stores and/or trusted identities, remove them if signature verification needs to be skipped", statement.Name)
-			}
-		} else {
-			if len(statement.TrustStores) == 0 || len(statement.TrustedIdentities) == 0 {
-				return fmt.Errorf("trust policy statement %q is either missing trust stores or trusted identities, both must be specified", statement.Name)
-			}
-
-			// Verify Trust Store is valid
-			if err := validateTrustStore(statement); err != nil {
-				return err
-			}
+	// Verify custom rules
+	if err := runValidators(policyDoc); err != nil {
+		return []error{err}
+	}
+	return nil
+}
 
-			// Verify Trusted Identities are valid
-			if err := validateTrustedIdentities(statement); err != nil {
-				return err
-			}
-		}
+// validateStatement validates a single trust policy statement and returns
+// the violations found.
+func validateStatement(statement TrustPolicy) []error {
+	var errs []error
 
+	// Verify statement name is valid
+	if statement.Name == "" {
+		errs = append(errs, errors.New("a trust policy statement is missing a name, every statement requires a name"))
 	}
 
-	// Verify registry scopes are valid
-	if err := validateRegistryScopes(policyDoc); err != nil {
-		return err
+	// Verify signature verification is valid
+	verificationLevel, err := statement.SignatureVerification.GetVerificationLevel()
+	if err != nil {
+		return append(errs, fmt.Errorf("trust policy statement %q has invalid signatureVerification: %w", statement.Name, err))
 	}
 
-	// Verify unique policy statement names across the policy document
-	for key := range policyStatementNameCount {
-		if policyStatementNameCount[key] > 1 {
-			return fmt.Errorf("multiple trust policy statements use the same name %q, statement names must be unique", key)
+	// Any signature verification other than "skip" needs a trust store and
+	// trusted identities
+	if verificationLevel.Name == "skip" {
+		if len(statement.TrustStores) > 0 || len(statement.TrustedIdentities) > 0 {
+			errs = append(errs, fmt.Errorf("trust policy statement %q is set to skip signature verification but configured with trust stores and/or trusted identities, remove them if signature verification needs to be skipped", statement.Name))
+		}
+		if len(statement.RequiredSignerGroups) > 0 {
+			errs = append(errs, fmt.Errorf("trust policy statement %q is set to skip signature verification but configured with required signer groups, remove them if signature verification needs to be skipped", statement.Name))
+		}
+	} else if len(statement.RequiredSignerGroups) > 0 {
+		// Verify required signer groups are valid
+		if err := validateRequiredSignerGroups(statement); err != nil {
+			errs = append(errs, err)
+		}
+	} else if len(statement.TrustStores) == 0 || len(statement.TrustedIdentities) == 0 {
+		errs = append(errs, fmt.Errorf("trust policy statement %q is either missing trust stores or trusted identities, both must be specified", statement.Name))
+	} else {
+		// Verify Trust Store is valid
+		if err := validateTrustStore(statement); err != nil {
+			errs = append(errs, err)
+		}
+
+		// Verify Trusted Identities are valid
+		if err := validateTrustedIdentities(statement); err != nil {
+			errs = append(errs, err)
 		}
 	}
+	return errs
+}
 
-	// No errors
+// runValidators runs the registered custom validators against the policy
+// document and aggregates their errors.
+func runValidators(policyDoc *Document) error {
+	validatorsMu.RLock()
+	defer validatorsMu.RUnlock()
+
+	var errs []error
+	for _, validator := range validators {
+		if err := validator(policyDoc); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("trust policy document violates custom validation rules: %w", errors.Join(errs...))
+	}
 	return nil
 }
 
@@ -256,32 +418,64 @@ func (trustPolicyDoc *Document) GetApplicableTrustPolicy(artifactReference strin
 		return nil, err
 	}
 
-	var wildcardPolicy *TrustPolicy
-	var applicablePolicy *TrustPolicy
-	for _, policyStatement := range trustPolicyDoc.TrustPolicies {
-		if slices.Contains(policyStatement.RegistryScopes, trustpolicy.Wildcard) {
-			// we need to deep copy because we can't use the loop variable
-			// address. see https://stackoverflow.com/a/45967429
-			wildcardPolicy = (&policyStatement).clone()
-		} else if slices.Contains(policyStatement.RegistryScopes, artifactPath) {
-			applicablePolicy = (&policyStatement).clone()
+	i := applicableStatementIndex(trustPolicyDoc.TrustPolicies, artifactPath)
+	if i < 0 {
+		return nil, fmt.Errorf("artifact %q has no applicable trust policy. Trust policy applicability for a given artifact is determined by registryScopes. To create a trust policy, see: %s", artifactReference, trustPolicyLink)
+	}
+	return trustPolicyDoc.TrustPolicies[i].clone(), nil
+}
+
+// applicableStatementIndex returns the index of the trust policy statement
+// applicable to the artifact path, or -1 if there is none. Registry scopes
+// and the artifact path are compared in their normalized form. A statement
+// with the exact registry scope takes precedence over a statement with a
+// matching wildcard prefix scope, a longer prefix takes precedence over a
+// shorter one and any prefix takes precedence over the wildcard (*)
+// statement.
+func applicableStatementIndex(statements []TrustPolicy, artifactPath string) int {
+	artifactPath = normalizeRegistryScope(artifactPath)
+	prefixIndex, wildcardIndex := -1, -1
+	longestPrefix := 0
+	for i, statement := range statements {
+		for _, scope := range statement.RegistryScopes {
+			scope = normalizeRegistryScope(scope)
+			if scope == artifactPath {
+				return i
+			}
+			if scope == trustpolicy.Wildcard {
+				wildcardIndex = i
+				continue
+			}
+			// keep the trailing "/" so that "registry.io/team/*" does not
+			// match "registry.io/teammate/app"
+			prefix, ok := strings.CutSuffix(scope, prefixScopeSuffix)
+			prefix += "/"
+			if ok && len(prefix) > longestPrefix && strings.HasPrefix(artifactPath, prefix) {
+				prefixIndex = i
+				longestPrefix = len(prefix)
+			}
 		}
 	}
+	if prefixIndex >= 0 {
+		return prefixIndex
+	}
+	return wildcardIndex
+}
 
-	if applicablePolicy != nil {
-		// a policy with exact match for registry scope takes precedence over
-		// a wildcard (*) policy.
-		return applicablePolicy, nil
-	} else if wildcardPolicy != nil {
-		return wildcardPolicy, nil
-	} else {
-		return nil, fmt.Errorf("artifact %q has no applicable trust policy. Trust policy applicability for a given artifact is determined by registryScopes. To create a trust policy, see: %s", artifactReference, trustPolicyLink)
+// GetTrustPolicy returns a pointer to the deep copied TrustPolicy statement
+// with the given name. If no such statement is found, returns an error
+func (trustPolicyDoc *Document) GetTrustPolicy(name string) (*TrustPolicy, error) {
+	for _, policyStatement := range trustPolicyDoc.TrustPolicies {
+		if policyStatement.Name == name {
+			return (&policyStatement).clone(), nil
+		}
 	}
+	return nil, fmt.Errorf("trust policy statement %q is not found in the trust policy document", name)
 }
 
 // LoadDocument loads a trust policy document from a local file system
 func LoadDocument() (*Document, error) {
-	path, err := dir.ConfigFS().SysPath(dir.PathTrustPolicy)
+	path, err := DefaultDocumentPath()
 	if err != nil {
 		return nil, err
 	}
@@ -317,6 +511,82 @@ func LoadDocument() (*Document, error) {
 	return policyDocument, nil
 }
 
+// DefaultDocumentPath returns the path of the trust policy document in the
+// notation configuration directory.
+func DefaultDocumentPath() (string, error) {
+	return dir.ConfigFS().SysPath(dir.PathTrustPolicy)
+}
+
+// LoadPolicyDocument reads the trust policy document at path, decodes it and
+// validates it. See ParsePolicyDocument for the decoding rules. A missing
+// file is reported as DocumentNotFoundError.
+func LoadPolicyDocument(path string) (*Document, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, DocumentNotFoundError{Path: path, InnerError: err}
+		}
+		return nil, err
+	}
+	defer f.Close()
+	return ParsePolicyDocument(f)
+}
+
+// ParsePolicyDocument decodes a trust policy document from r and validates
+// it. Unknown fields and trailing data are rejected so that misspelled
+// properties do not go unnoticed. Decoding failures are reported as
+// MalformedDocumentError and validation failures as InvalidDocumentError
+// joining every violation reported by Document.ValidateAll.
+func ParsePolicyDocument(r io.Reader) (*Document, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	policyDocument := &Document{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(policyDocument); err != nil {
+		return nil, malformedDocumentError(data, err)
+	}
+	offset := decoder.InputOffset()
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, newMalformedDocumentError(data, offset, errors.New("unexpected data after the trust policy document"))
+	}
+
+	if errs := policyDocument.ValidateAll(); len(errs) > 0 {
+		return nil, InvalidDocumentError{InnerError: errors.Join(errs...)}
+	}
+	return policyDocument, nil
+}
+
+// malformedDocumentError wraps a JSON decoding error, locating it in data
+// when the error carries an offset.
+func malformedDocumentError(data []byte, err error) error {
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return newMalformedDocumentError(data, syntaxErr.Offset, err)
+	}
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return newMalformedDocumentError(data, typeErr.Offset, err)
+	}
+	return MalformedDocumentError{InnerError: err}
+}
+
+// newMalformedDocumentError returns a MalformedDocumentError located at the
+// offset in data.
+func newMalformedDocumentError(data []byte, offset int64, err error) error {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	return MalformedDocumentError{
+		Line:       bytes.Count(data[:offset], []byte("\n")) + 1,
+		Offset:     offset,
+		InnerError: err,
+	}
+}
+
 // GetVerificationLevel returns VerificationLevel struct for the given
 // SignatureVerification struct throws error if SignatureVerification is invalid
 func (signatureVerification *SignatureVerification) GetVerificationLevel() (*VerificationLevel, error) {
@@ -389,14 +659,87 @@ func (signatureVerification *SignatureVerification) GetVerificationLevel() (*Ver
 	return customVerificationLevel, nil
 }
 
+// Clone returns a deep copy of the policy document. Modifying the copy,
+// including the slices and maps of its statements, does not affect the
+// original document.
+func (policyDoc *Document) Clone() *Document {
+	if policyDoc == nil {
+		return nil
+	}
+	var trustPolicies []TrustPolicy
+	if policyDoc.TrustPolicies != nil {
+		trustPolicies = make([]TrustPolicy, 0, len(policyDoc.TrustPolicies))
+	}
+	for i := range policyDoc.TrustPolicies {
+		trustPolicies = append(trustPolicies, *policyDoc.TrustPolicies[i].clone())
+	}
+	return &Document{
+		Version:       policyDoc.Version,
+		TrustPolicies: trustPolicies,
+	}
+}
+
+// Marshal returns the canonical JSON encoding of the policy document,
+// indented with two spaces. Trust policy statements are sorted by name and
+// the registry scopes, trust stores and trusted identities of statements and
+// required signer groups are sorted, so that equivalent documents are
+// encoded identically. The document is not modified.
+func (policyDoc *Document) Marshal() ([]byte, error) {
+	canonical := policyDoc.Clone()
+	if canonical == nil {
+		return nil, errors.New("trust policy document cannot be nil")
+	}
+	sort.SliceStable(canonical.TrustPolicies, func(i, j int) bool {
+		return canonical.TrustPolicies[i].Name < canonical.TrustPolicies[j].Name
+	})
+	for i := range canonical.TrustPolicies {
+		statement := &canonical.TrustPolicies[i]
+		sort.Strings(statement.RegistryScopes)
+		sort.Strings(statement.TrustStores)
+		sort.Strings(statement.TrustedIdentities)
+		for j := range statement.RequiredSignerGroups {
+			sort.Strings(statement.RequiredSignerGroups[j].TrustStores)
+			sort.Strings(statement.RequiredSignerGroups[j].TrustedIdentities)
+		}
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(canonical); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
 // clone returns a pointer to the deeply copied TrustPolicy
 func (t *TrustPolicy) clone() *TrustPolicy {
+	var requiredSignerGroups []SignerGroup
+	for _, group := range t.RequiredSignerGroups {
+		requiredSignerGroups = append(requiredSignerGroups, SignerGroup{
+			Name:              group.Name,
+			TrustStores:       append([]string(nil), group.TrustStores...),
+			TrustedIdentities: append([]string(nil), group.TrustedIdentities...),
+		})
+	}
+	var override map[ValidationType]ValidationAction
+	if t.SignatureVerification.Override != nil {
+		override = make(map[ValidationType]ValidationAction, len(t.SignatureVerification.Override))
+		for validationType, action := range t.SignatureVerification.Override {
+			override[validationType] = action
+		}
+	}
 	return &TrustPolicy{
-		Name:                  t.Name,
-		SignatureVerification: t.SignatureVerification,
-		RegistryScopes:        append([]string(nil), t.RegistryScopes...),
-		TrustedIdentities:     append([]string(nil), t.TrustedIdentities...),
-		TrustStores:           append([]string(nil), t.TrustStores...),
+		Name: t.Name,
+		SignatureVerification: SignatureVerification{
+			VerificationLevel: t.SignatureVerification.VerificationLevel,
+			Override:          override,
+		},
+		RegistryScopes:       append([]string(nil), t.RegistryScopes...),
+		TrustedIdentities:    append([]string(nil), t.TrustedIdentities...),
+		TrustStores:          append([]string(nil), t.TrustStores...),
+		RequiredSignerGroups: requiredSignerGroups,
 	}
 }
 
@@ -414,11 +757,78 @@ func validateTrustStore(statement TrustPolicy) error {
 		if !file.IsValidFileName(namedStore) {
 			return fmt.Errorf("trust policy statement %q uses an unsupported trust store name %q in trust store value %q. Named store name needs to follow [a-zA-Z0-9_.-]+ format", statement.Name, namedStore, trustStore)
 		}
+		// trust store names map to directory names, reject the ones that
+		// could resolve outside of the trust store type directory
+		if namedStore == "." || strings.Contains(namedStore, "..") {
+			return fmt.Errorf("trust policy statement %q uses an unsafe trust store name %q in trust store value %q. Named store name cannot be \".\" or contain \"..\"", statement.Name, namedStore, trustStore)
+		}
+	}
+
+	return nil
+}
+
+// ParseTrustStore splits a trust store value of a trust policy statement,
+// e.g. "ca:acme-rockets", into its type and name. It returns an
+// InvalidTrustStoreError if the value is malformed, the type is not one of
+// truststore.Types or the name is not a valid trust store name.
+func ParseTrustStore(trustStore string) (truststore.Type, string, error) {
+	storeType, namedStore, found := strings.Cut(trustStore, ":")
+	if !found {
+		return "", "", InvalidTrustStoreError{TrustStore: trustStore, Msg: "the required format is <TrustStoreType>:<TrustStoreName>"}
+	}
+	if !isValidTrustStoreType(storeType) {
+		return "", "", InvalidTrustStoreError{TrustStore: trustStore, Msg: fmt.Sprintf("unsupported trust store type %q", storeType)}
+	}
+	if !file.IsValidFileName(namedStore) || namedStore == "." || strings.Contains(namedStore, "..") {
+		return "", "", InvalidTrustStoreError{TrustStore: trustStore, Msg: fmt.Sprintf("unsupported trust store name %q, it needs to follow [a-zA-Z0-9_.-]+ format and cannot be \".\" or contain \"..\"", namedStore)}
+	}
+	return truststore.Type(storeType), namedStore, nil
+}
+
+// validateRequiredSignerGroups validates the required signer groups of the
+// policy statement. Every group needs a unique name, trust stores and trusted
+// identities.
+func validateRequiredSignerGroups(statement TrustPolicy) error {
+	if len(statement.TrustStores) > 0 || len(statement.TrustedIdentities) > 0 {
+		return fmt.Errorf("trust policy statement %q is configured with both required signer groups and trust stores and/or trusted identities, specify them per signer group instead", statement.Name)
+	}
+
+	groupNames := make(map[string]struct{})
+	for _, group := range statement.RequiredSignerGroups {
+		if group.Name == "" {
+			return fmt.Errorf("trust policy statement %q has a required signer group missing a name, every signer group requires a name", statement.Name)
+		}
+		if _, ok := groupNames[group.Name]; ok {
+			return fmt.Errorf("trust policy statement %q has multiple required signer groups named %q, signer group names must be unique", statement.Name, group.Name)
+		}
+		groupNames[group.Name] = struct{}{}
+
+		if len(group.TrustStores) == 0 || len(group.TrustedIdentities) == 0 {
+			return fmt.Errorf("trust policy statement %q has required signer group %q missing trust stores or trusted identities, both must be specified", statement.Name, group.Name)
+		}
+		groupStatement := TrustPolicy{
+			Name:              statement.Name,
+			TrustStores:       group.TrustStores,
+			TrustedIdentities: group.TrustedIdentities,
+		}
+		if err := validateTrustStore(groupStatement); err != nil {
+			return fmt.Errorf("required signer group %q is invalid: %w", group.Name, err)
+		}
+		if err := validateTrustedIdentities(groupStatement); err != nil {
+			return fmt.Errorf("required signer group %q is invalid: %w", group.Name, err)
+		}
 	}
 
 	return nil
 }
 
+// ValidateTrustedIdentities validates the trusted identities of the trust
+// policy statement, e.g. after overriding them with trusted identities
+// resolved at verification time.
+func ValidateTrustedIdentities(statement TrustPolicy) error {
+	return validateTrustedIdentities(statement)
+}
+
 // validateTrustedIdentities validates if the policy statement is following the
 // Notary Project spec rules for trusted identities
 func validateTrustedIdentities(statement TrustPolicy) error {
@@ -429,6 +839,9 @@ func validateTrustedIdentities(statement TrustPolicy) error {
 	}
 
 	var parsedDNs []parsedDN
+	// identities other than x509.subject ones, which are compared by
+	// validateOverlappingDNs, keyed by their canonical form
+	identities := make(map[string]struct{})
 	// If there are trusted identities, verify they are valid
 	for _, identity := range statement.TrustedIdentities {
 		if identity == "" {
@@ -441,7 +854,8 @@ func validateTrustedIdentities(statement TrustPolicy) error {
 				return fmt.Errorf("trust policy statement %q has trusted identity %q missing separator", statement.Name, identity)
 			}
 
-			// notation natively supports x509.subject identities only
+			// notation natively supports x509.subject and
+			// x509.fingerprint.sha256 identities only
 			if identityPrefix == trustpolicy.X509Subject {
 				// identityValue cannot be empty
 				if identityValue == "" {
@@ -453,6 +867,22 @@ func validateTrustedIdentities(statement TrustPolicy) error {
 				}
 				parsedDNs = append(parsedDNs, parsedDN{RawString: identity, ParsedMap: dn})
 			}
+
+			// x509.fingerprint.sha256 identities pin the signing certificate
+			if identityPrefix == trustpolicy.X509FingerprintSHA256 && !isValidSHA256Fingerprint(identityValue) {
+				return fmt.Errorf("trust policy statement %q has trusted identity %q with invalid identity value, it must be a SHA-256 fingerprint of 64 hexadecimal characters", statement.Name, identity)
+			}
+
+			if identityPrefix != trustpolicy.X509Subject {
+				key := identity
+				if identityPrefix == trustpolicy.X509FingerprintSHA256 {
+					key = identityPrefix + ":" + strings.ToLower(identityValue)
+				}
+				if _, ok := identities[key]; ok {
+					return fmt.Errorf("trust policy statement %q has duplicate trusted identity %q", statement.Name, identity)
+				}
+				identities[key] = struct{}{}
+			}
 		}
 	}
 
@@ -465,37 +895,82 @@ func validateTrustedIdentities(statement TrustPolicy) error {
 	return nil
 }
 
+// isValidSHA256Fingerprint reports whether fingerprint is a hex encoded
+// SHA-256 digest.
+func isValidSHA256Fingerprint(fingerprint string) bool {
+	if len(fingerprint) != hex.EncodedLen(sha256.Size) {
+		return false
+	}
+	_, err := hex.DecodeString(fingerprint)
+	return err == nil
+}
+
 // validateRegistryScopes validates if the policy document is following the
-// Notary Project spec rules for registry scopes
-func validateRegistryScopes(policyDoc *Document) error {
-	registryScopeCount := make(map[string]int)
-	for _, statement := range policyDoc.TrustPolicies {
+// Notary Project spec rules for registry scopes and returns the violations
+// found
+func validateRegistryScopes(policyDoc *Document) []error {
+	var errs []error
+	// registry scopes are compared in their normalized form so that scopes
+	// differing only in the case of the registry domain are detected as
+	// duplicates. Overlapping scopes of different specificity, e.g. a prefix
+	// scope and a longer prefix scope, are resolved by the precedence rules
+	// of applicableStatementIndex and are not conflicts.
+	type scopeOwner struct {
+		scope     string
+		index     int
+		statement string
+	}
+	registryScopes := make(map[string]scopeOwner)
+	for i, statement := range policyDoc.TrustPolicies {
+		statementError := func(err error) StatementError {
+			return StatementError{Index: i, Name: statement.Name, InnerError: err}
+		}
 		// Verify registry scopes are valid
 		if len(statement.RegistryScopes) == 0 {
-			return fmt.Errorf("trust policy statement %q has zero registry scopes, it must specify registry scopes with at least one value", statement.Name)
+			errs = append(errs, statementError(fmt.Errorf("trust policy statement %q has zero registry scopes, it must specify registry scopes with at least one value", statement.Name)))
+			continue
 		}
 		if len(statement.RegistryScopes) > 1 && slices.Contains(statement.RegistryScopes, trustpolicy.Wildcard) {
-			return fmt.Errorf("trust policy statement %q uses wildcard registry scope '*', a wildcard scope cannot be used in conjunction with other scope values", statement.Name)
+			errs = append(errs, statementError(fmt.Errorf("trust policy statement %q uses wildcard registry scope '*', a wildcard scope cannot be used in conjunction with other scope values", statement.Name)))
 		}
 		for _, scope := range statement.RegistryScopes {
 			if scope != trustpolicy.Wildcard {
-				if err := validateRegistryScopeFormat(scope); err != nil {
-					return err
+				if err := validatePolicyRegistryScope(scope); err != nil {
+					errs = append(errs, statementError(err))
+					continue
+				}
+			}
+			// Verify one policy statement per registry scope
+			normalizedScope := normalizeRegistryScope(scope)
+			if owner, ok := registryScopes[normalizedScope]; ok {
+				switch {
+				case owner.index == i:
+					errs = append(errs, statementError(fmt.Errorf("trust policy statement %q lists registry scope %q more than once", statement.Name, scope)))
+				case owner.scope != scope:
+					errs = append(errs, statementError(fmt.Errorf("registry scope %q of trust policy statement %q is equivalent to registry scope %q of trust policy statement %q, one registry scope value can only be associated with one statement", scope, statement.Name, owner.scope, owner.statement)))
+				default:
+					errs = append(errs, statementError(fmt.Errorf("registry scope %q is present in multiple trust policy statements %q and %q, one registry scope value can only be associated with one statement", scope, owner.statement, statement.Name)))
 				}
+				continue
 			}
-			registryScopeCount[scope]++
+			registryScopes[normalizedScope] = scopeOwner{scope: scope, index: i, statement: statement.Name}
 		}
 	}
+	return errs
+}
 
-	// Verify one policy statement per registry scope
-	for key := range registryScopeCount {
-		if registryScopeCount[key] > 1 {
-			return fmt.Errorf("registry scope %q is present in multiple trust policy statements, one registry scope value can only be associated with one statement", key)
-		}
+// normalizeRegistryScope returns the canonical form of a registry scope.
+// Registry domains are case-insensitive while repository names are
+// restricted to lowercase, so only the domain is lowercased.
+func normalizeRegistryScope(scope string) string {
+	if scope == trustpolicy.Wildcard {
+		return scope
 	}
-
-	// No error
-	return nil
+	domain, repository, found := strings.Cut(scope, "/")
+	if !found {
+		return scope
+	}
+	return strings.ToLower(domain) + "/" + repository
 }
 
 func validateOverlappingDNs(policyName string, parsedDNs []parsedDN) error {
@@ -521,37 +996,69 @@ func isValidTrustStoreType(s string) bool {
 	return false
 }
 
+// getArtifactPathFromReference strips the digest or the tag from the artifact
+// reference, e.g. "domain.com/repository@sha256:digest" or
+// "domain.com/repository:tag".
 func getArtifactPathFromReference(artifactReference string) (string, error) {
-	// TODO support more types of URI like "domain.com/repository",
-	// "domain.com/repository:tag"
-	i := strings.LastIndex(artifactReference, "@")
-	if i < 0 {
+	artifactPath := registryScopeFromReference(artifactReference)
+	if artifactPath == artifactReference {
 		return "", fmt.Errorf("artifact URI %q could not be parsed, make sure it is the fully qualified OCI artifact URI without the scheme/protocol. e.g domain.com:80/my/repository@sha256:digest", artifactReference)
 	}
-
-	artifactPath := artifactReference[:i]
 	if err := validateRegistryScopeFormat(artifactPath); err != nil {
 		return "", err
 	}
 	return artifactPath, nil
 }
 
+// registryScopeFromReference strips the digest or the tag from an artifact
+// reference.
+func registryScopeFromReference(reference string) string {
+	if i := strings.LastIndex(reference, "@"); i >= 0 {
+		return reference[:i]
+	}
+	if i := strings.LastIndex(reference, ":"); i > strings.LastIndex(reference, "/") {
+		return reference[:i]
+	}
+	return reference
+}
+
 // Internal type to hold raw and parsed Distinguished Names
 type parsedDN struct {
 	RawString string
 	ParsedMap map[string]string
 }
 
+// Domain and Repository regexes are adapted from distribution
+// implementation
+// https://github.com/distribution/distribution/blob/main/reference/regexp.go#L31
+var (
+	domainRegexp     = regexp.MustCompile(`^(?:[a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9-]*[a-zA-Z0-9])(?:(?:\.(?:[a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9-]*[a-zA-Z0-9]))+)?(?::[0-9]+)?$`)
+	repositoryRegexp = regexp.MustCompile(`^[a-z0-9]+(?:(?:(?:[._]|__|[-]*)[a-z0-9]+)+)?(?:(?:/[a-z0-9]+(?:(?:(?:[._]|__|[-]*)[a-z0-9]+)+)?)+)?$`)
+)
+
+// validatePolicyRegistryScope validates a registry scope of a trust policy
+// statement. In addition to the format accepted by
+// validateRegistryScopeFormat, a scope may end with a wildcard prefix such as
+// "registry.example.com/team/*", in which case the part before "/*" must be a
+// valid registry scope itself, or a valid registry host such as in
+// "registry.example.com/*" to apply to every repository of the registry.
+func validatePolicyRegistryScope(scope string) error {
+	if prefix, ok := strings.CutSuffix(scope, prefixScopeSuffix); ok && !strings.Contains(prefix, "*") {
+		if !strings.Contains(prefix, "/") && domainRegexp.MatchString(prefix) {
+			return nil
+		}
+		if validateRegistryScopeFormat(prefix) == nil {
+			return nil
+		}
+	}
+	return validateRegistryScopeFormat(scope)
+}
+
 // validateRegistryScopeFormat validates if a scope is following the format
 // defined in distribution spec
 func validateRegistryScopeFormat(scope string) error {
-	// Domain and Repository regexes are adapted from distribution
-	// implementation
-	// https://github.com/distribution/distribution/blob/main/reference/regexp.go#L31
-	domainRegexp := regexp.MustCompile(`^(?:[a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9-]*[a-zA-Z0-9])(?:(?:\.(?:[a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9-]*[a-zA-Z0-9]))+)?(?::[0-9]+)?$`)
-	repositoryRegexp := regexp.MustCompile(`^[a-z0-9]+(?:(?:(?:[._]|__|[-]*)[a-z0-9]+)+)?(?:(?:/[a-z0-9]+(?:(?:(?:[._]|__|[-]*)[a-z0-9]+)+)?)+)?$`)
 	ensureMessage := "make sure it is a fully qualified repository without the scheme, protocol or tag. For example domain.com/my/repository or a local scope like local/myOCILayout"
-	errorMessage := "registry scope %q is not valid, " + ensureMessage
+	errorMessage := "registry scope %q is not valid, it %s, " + ensureMessage
 	errorWildCardMessage := "registry scope %q with wild card(s) is not valid, " + ensureMessage
 
 	// Check for presence of * in scope
@@ -559,13 +1066,37 @@ func validateRegistryScopeFormat(scope string) error {
 		return fmt.Errorf(errorWildCardMessage, scope)
 	}
 
+	// Check for the common mistakes first so that the error tells which
+	// part of the scope is invalid
+	switch {
+	case scope == "":
+		return fmt.Errorf(errorMessage, scope, "is empty")
+	case strings.IndexFunc(scope, unicode.IsSpace) >= 0:
+		return fmt.Errorf(errorMessage, scope, "contains whitespace")
+	case strings.Contains(scope, "://"):
+		return fmt.Errorf(errorMessage, scope, "has a scheme")
+	case strings.Contains(scope, "@"):
+		return fmt.Errorf(errorMessage, scope, "has a digest")
+	}
+
 	domain, repository, found := strings.Cut(scope, "/")
 	if !found {
-		return fmt.Errorf(errorMessage, scope)
+		return fmt.Errorf(errorMessage, scope, "has no repository path")
 	}
-
-	if domain == "" || repository == "" || !domainRegexp.MatchString(domain) || !repositoryRegexp.MatchString(repository) {
-		return fmt.Errorf(errorMessage, scope)
+	if strings.Contains(repository[strings.LastIndex(repository, "/")+1:], ":") {
+		return fmt.Errorf(errorMessage, scope, "has a tag")
+	}
+	if domain == "" {
+		return fmt.Errorf(errorMessage, scope, "has an empty registry host")
+	}
+	if !domainRegexp.MatchString(domain) {
+		return fmt.Errorf(errorMessage, scope, fmt.Sprintf("has an invalid registry host %q", domain))
+	}
+	if repository == "" {
+		return fmt.Errorf(errorMessage, scope, "has an empty repository path")
+	}
+	if !repositoryRegexp.MatchString(repository) {
+		return fmt.Errorf(errorMessage, scope, fmt.Sprintf("has an invalid repository path %q, repository paths are lowercase and cannot have empty components", repository))
 	}
 
 	// No errors
@@ -14,16 +14,20 @@
 package trustpolicy
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"runtime"
 	"strconv"
 	"strings"
 	"testing"
 
 	"github.com/notaryproject/notation-go/dir"
+	"github.com/notaryproject/notation-go/verifier/truststore"
 )
 
 func dummyPolicyStatement() (policyStatement TrustPolicy) {
@@ -73,6 +77,7 @@ func TestValidateValidPolicyDocument(t *testing.T) {
 	policyStatement5.Name = "test-statement-name-5"
 	policyStatement5.RegistryScopes = []string{"registry.acme-rockets2.io/software"}
 	policyStatement5.TrustedIdentities = []string{"*"}
+	policyStatement5.TrustStores = []string{"ca:valid-name", "signingAuthority:valid.trust_store-2", "tsa:valid-tsa-store"}
 	policyStatement5.SignatureVerification = SignatureVerification{VerificationLevel: "strict"}
 
 	policyDoc.TrustPolicies = []TrustPolicy{
@@ -122,6 +127,36 @@ func TestValidateTrustedIdentities(t *testing.T) {
 		t.Fatalf("invalid x509.subject identity should return error. Error : %q", err)
 	}
 
+	// Validate x509.fingerprint.sha256 identities
+	policyDoc = dummyPolicyDocument()
+	policyStatement = dummyPolicyStatement()
+	policyStatement.TrustedIdentities = []string{"x509.fingerprint.sha256:3C7D7FD2A3B04D4AE6B96A6A4D3FB8D5A18B2BE5F3B1F8E6C9D94E4B45F7D6E1"}
+	policyDoc.TrustPolicies = []TrustPolicy{policyStatement}
+	if err := policyDoc.Validate(); err != nil {
+		t.Fatalf("valid x509.fingerprint.sha256 identity should not return an error. Error: %q", err)
+	}
+	for _, fingerprint := range []string{"", "3c7d7fd2", "zz7d7fd2a3b04d4ae6b96a6a4d3fb8d5a18b2be5f3b1f8e6c9d94e4b45f7d6e1", "sha256:3c7d7fd2a3b04d4ae6b96a6a4d3fb8d5a18b2be5f3b1f8e6c9d94e4b45f7d6e1"} {
+		policyStatement.TrustedIdentities = []string{"x509.fingerprint.sha256:" + fingerprint}
+		policyDoc.TrustPolicies = []TrustPolicy{policyStatement}
+		err = policyDoc.Validate()
+		if err == nil || !strings.Contains(err.Error(), "it must be a SHA-256 fingerprint of 64 hexadecimal characters") {
+			t.Fatalf("invalid x509.fingerprint.sha256 identity %q should return error. Error : %q", fingerprint, err)
+		}
+	}
+
+	// Validate duplicate identities
+	for _, identities := range [][]string{
+		{"x509.fingerprint.sha256:3c7d7fd2a3b04d4ae6b96a6a4d3fb8d5a18b2be5f3b1f8e6c9d94e4b45f7d6e1", "x509.fingerprint.sha256:3C7D7FD2A3B04D4AE6B96A6A4D3FB8D5A18B2BE5F3B1F8E6C9D94E4B45F7D6E1"},
+		{"plugin.identity:value", "plugin.identity:value"},
+	} {
+		policyStatement.TrustedIdentities = identities
+		policyDoc.TrustPolicies = []TrustPolicy{policyStatement}
+		err = policyDoc.Validate()
+		if err == nil || err.Error() != fmt.Sprintf("trust policy statement \"test-statement-name\" has duplicate trusted identity %q", identities[1]) {
+			t.Fatalf("duplicate identities %q should return error. Error : %q", identities, err)
+		}
+	}
+
 	// Validate duplicate RDNs
 	policyDoc = dummyPolicyDocument()
 	policyStatement = dummyPolicyStatement()
@@ -133,6 +168,35 @@ func TestValidateTrustedIdentities(t *testing.T) {
 		t.Fatalf("invalid x509.subject identity should return error. Error : %q", err)
 	}
 
+	// Validate empty RDN values
+	policyDoc = dummyPolicyDocument()
+	policyStatement = dummyPolicyStatement()
+	invalidDN = "x509.subject:C=US,ST=WA,O=acme,CN="
+	policyStatement.TrustedIdentities = []string{invalidDN}
+	policyDoc.TrustPolicies = []TrustPolicy{policyStatement}
+	err = policyDoc.Validate()
+	if err == nil || err.Error() != "trust policy statement \"test-statement-name\" has trusted identity \"x509.subject:C=US,ST=WA,O=acme,CN=\" with invalid identity value: distinguished name (DN) \"C=US,ST=WA,O=acme,CN=\" has an empty value for RDN attribute \"CN\", RDN attributes must have a value" {
+		t.Fatalf("x509.subject identity with empty RDN value should return error. Error : %q", err)
+	}
+
+	// Validate duplicate RDNs after an empty RDN value
+	policyDoc = dummyPolicyDocument()
+	policyStatement = dummyPolicyStatement()
+	policyStatement.TrustedIdentities = []string{"x509.subject:C=US,ST=WA,O=acme,CN=,CN=acme-signer"}
+	policyDoc.TrustPolicies = []TrustPolicy{policyStatement}
+	if err := policyDoc.Validate(); err == nil {
+		t.Fatal("x509.subject identity with empty and duplicate RDN attributes should return error")
+	}
+
+	// Mandatory fields are sufficient
+	policyDoc = dummyPolicyDocument()
+	policyStatement = dummyPolicyStatement()
+	policyStatement.TrustedIdentities = []string{"x509.subject:C=US,ST=WA,O=acme,CN=acme-signer"}
+	policyDoc.TrustPolicies = []TrustPolicy{policyStatement}
+	if err := policyDoc.Validate(); err != nil {
+		t.Fatalf("valid x509.subject identity should not return an error. Error: %q", err)
+	}
+
 	// Validate mandatory RDNs
 	policyDoc = dummyPolicyDocument()
 	policyStatement = dummyPolicyStatement()
@@ -168,6 +232,42 @@ func TestValidateTrustedIdentities(t *testing.T) {
 		t.Fatalf("valid x509.subject identity should not return error. Error : %q", err)
 	}
 
+	// Validate canonically equal DNs within a statement
+	policyDoc = dummyPolicyDocument()
+	policyStatement = dummyPolicyStatement()
+	validDN1 = "x509.subject:C=US,ST=WA,O=MyOrg"
+	validDN2 = "x509.subject:O=MyOrg, ST=WA, C=US"
+	policyStatement.TrustedIdentities = []string{validDN1, validDN2}
+	policyDoc.TrustPolicies = []TrustPolicy{policyStatement}
+	err = policyDoc.Validate()
+	if err == nil || err.Error() != "trust policy statement \"test-statement-name\" has overlapping x509 trustedIdentities, \"x509.subject:C=US,ST=WA,O=MyOrg\" overlaps with \"x509.subject:O=MyOrg, ST=WA, C=US\"" {
+		t.Fatalf("policy statement with canonically equal DNs should return error %q", err)
+	}
+
+	// Validate DNs differing in the case of attribute types within a statement
+	policyDoc = dummyPolicyDocument()
+	policyStatement = dummyPolicyStatement()
+	policyStatement.TrustedIdentities = []string{"x509.subject:CN=a,O=b,ST=WA,C=US", "x509.subject:cn=a, o=b, st=WA, c=US"}
+	policyDoc.TrustPolicies = []TrustPolicy{policyStatement}
+	err = policyDoc.Validate()
+	if err == nil || !strings.Contains(err.Error(), "has overlapping x509 trustedIdentities") {
+		t.Fatalf("policy statement with DNs differing in the case of attribute types should return error %q", err)
+	}
+
+	// Canonically equal DNs across statements are allowed, the same signer
+	// may be trusted for different registry scopes
+	policyDoc = dummyPolicyDocument()
+	policyStatement = dummyPolicyStatement()
+	policyStatement.TrustedIdentities = []string{"x509.subject:C=US,ST=WA,O=MyOrg"}
+	policyStatement2 := dummyPolicyStatement()
+	policyStatement2.Name = "test-statement-name-2"
+	policyStatement2.RegistryScopes = []string{"registry.acme-rockets.io/software/net-logger"}
+	policyStatement2.TrustedIdentities = []string{"x509.subject:O=MyOrg, ST=WA, C=US"}
+	policyDoc.TrustPolicies = []TrustPolicy{policyStatement, policyStatement2}
+	if err = policyDoc.Validate(); err != nil {
+		t.Fatalf("policy statements with canonically equal DNs should not return error %q", err)
+	}
+
 	// Validate overlapping DNs
 	policyDoc = dummyPolicyDocument()
 	policyStatement = dummyPolicyStatement()
@@ -192,28 +292,92 @@ func TestValidateTrustedIdentities(t *testing.T) {
 	}
 }
 
+// TestValidateAll tests that every violation of a policy document is
+// reported
+func TestValidateAll(t *testing.T) {
+	policyDoc := dummyPolicyDocument()
+	if errs := policyDoc.ValidateAll(); errs != nil {
+		t.Fatalf("ValidateAll should not return errors for a valid document. Errors: %v", errs)
+	}
+
+	badLevel := dummyPolicyStatement()
+	badLevel.Name = "bad-level"
+	badLevel.RegistryScopes = []string{"registry.io/bad-level"}
+	badLevel.SignatureVerification = SignatureVerification{VerificationLevel: "invalid"}
+
+	badScopeAndIdentity := dummyPolicyStatement()
+	badScopeAndIdentity.Name = "bad-scope-and-identity"
+	badScopeAndIdentity.RegistryScopes = []string{"registry.io/*/app"}
+	badScopeAndIdentity.TrustedIdentities = []string{"x509.subject"}
+
+	duplicate := dummyPolicyStatement()
+	duplicate.RegistryScopes = []string{"registry.io/duplicate"}
+
+	policyDoc.TrustPolicies = []TrustPolicy{dummyPolicyStatement(), badLevel, badScopeAndIdentity, duplicate}
+	want := []StatementError{
+		{Index: 1, Name: "bad-level"},
+		{Index: 2, Name: "bad-scope-and-identity"},
+		{Index: 2, Name: "bad-scope-and-identity"},
+		{Index: 3, Name: "test-statement-name"},
+	}
+	errs := policyDoc.ValidateAll()
+	if len(errs) != len(want) {
+		t.Fatalf("ValidateAll returned %d errors, want %d. Errors: %v", len(errs), len(want), errs)
+	}
+	for i, err := range errs {
+		var statementErr StatementError
+		if !errors.As(err, &statementErr) || statementErr.Index != want[i].Index || statementErr.Name != want[i].Name {
+			t.Fatalf("error %d should be attributed to statement %d %q, got: %#v", i, want[i].Index, want[i].Name, err)
+		}
+	}
+	if err := policyDoc.Validate(); err == nil || err.Error() != errs[0].Error() {
+		t.Fatalf("Validate should return the first error of ValidateAll %q, got: %v", errs[0], err)
+	}
+}
+
 // TestInvalidRegistryScopes tests invalid scopes are rejected
 func TestInvalidRegistryScopes(t *testing.T) {
-	invalidScopes := []string{
-		"", "1:1", "a,b", "abcd", "1111", "1,2", "example.com/rep:tag",
-		"example.com/rep/subrep/sub:latest", "example.com", "rep/rep2:latest",
-		"repository", "10.10.10.10", "10.10.10.10:8080/rep/rep2:latest",
+	invalidScopes := map[string]string{
+		"":                                  "is empty",
+		"1:1":                               "has no repository path",
+		"a,b":                               "has no repository path",
+		"abcd":                              "has no repository path",
+		"1111":                              "has no repository path",
+		"1,2":                               "has no repository path",
+		"example.com":                       "has no repository path",
+		"repository":                        "has no repository path",
+		"10.10.10.10":                       "has no repository path",
+		"example.com/rep:tag":               "has a tag",
+		"example.com/rep/subrep/sub:latest": "has a tag",
+		"rep/rep2:latest":                   "has a tag",
+		"10.10.10.10:8080/rep/rep2:latest":  "has a tag",
+		"example.com/rep@sha256:a1b2":       "has a digest",
+		"https://example.com/rep":           "has a scheme",
+		"example.com/rep ":                  "contains whitespace",
+		"example.com/my rep":                "contains whitespace",
+		"/rep":                              "has an empty registry host",
+		"example_com/rep":                   "has an invalid registry host \"example_com\"",
+		"example.com/":                      "has an empty repository path",
+		"example.com/rep/":                  "has an invalid repository path \"rep/\", repository paths are lowercase and cannot have empty components",
+		"example.com//rep":                  "has an invalid repository path \"/rep\", repository paths are lowercase and cannot have empty components",
+		"example.com/rep//subrep":           "has an invalid repository path \"rep//subrep\", repository paths are lowercase and cannot have empty components",
+		"example.com/Rep":                   "has an invalid repository path \"Rep\", repository paths are lowercase and cannot have empty components",
 	}
 
-	for _, scope := range invalidScopes {
+	for scope, reason := range invalidScopes {
 		policyDoc := dummyPolicyDocument()
 		policyStatement := dummyPolicyStatement()
 		policyStatement.RegistryScopes = []string{scope}
 		policyDoc.TrustPolicies = []TrustPolicy{policyStatement}
 		err := policyDoc.Validate()
-		if err == nil || err.Error() != "registry scope \""+scope+"\" is not valid, make sure it is a fully qualified repository without the scheme, protocol or tag. For example domain.com/my/repository or a local scope like local/myOCILayout" {
+		if err == nil || err.Error() != "registry scope \""+scope+"\" is not valid, it "+reason+", make sure it is a fully qualified repository without the scheme, protocol or tag. For example domain.com/my/repository or a local scope like local/myOCILayout" {
 			t.Fatalf("invalid registry scope should return error. Error : %q", err)
 		}
 	}
 
 	// Test invalid scope with wild card suffix
 
-	invalidWildCardScopes := []string{"example.com/*", "*/", "example*/", "ex*test"}
+	invalidWildCardScopes := []string{"*/", "example*/", "ex*test", "example.com/*/app", "example.com/rep*", "example.com/rep/**", "example.com/rep/*/*"}
 	for _, scope := range invalidWildCardScopes {
 		policyDoc := dummyPolicyDocument()
 		policyStatement := dummyPolicyStatement()
@@ -226,11 +390,67 @@ func TestInvalidRegistryScopes(t *testing.T) {
 	}
 }
 
+// TestPrefixRegistryScopes tests the validation of wildcard prefix scopes
+// across statements
+func TestPrefixRegistryScopes(t *testing.T) {
+	policyDoc := dummyPolicyDocument()
+	policyStatement := dummyPolicyStatement()
+	policyStatement.RegistryScopes = []string{"*", "example.com/team/*"}
+	policyDoc.TrustPolicies = []TrustPolicy{policyStatement}
+	err := policyDoc.Validate()
+	if err == nil || err.Error() != "trust policy statement \"test-statement-name\" uses wildcard registry scope '*', a wildcard scope cannot be used in conjunction with other scope values" {
+		t.Fatalf("wildcard scope combined with a prefix scope should return error. Error : %q", err)
+	}
+
+	policyStatement1 := dummyPolicyStatement()
+	policyStatement1.Name = "test-statement-name-1"
+	policyStatement1.RegistryScopes = []string{"example.com/team/*"}
+	policyStatement2 := dummyPolicyStatement()
+	policyStatement2.Name = "test-statement-name-2"
+	policyStatement2.RegistryScopes = []string{"example.com/team/*"}
+	policyDoc.TrustPolicies = []TrustPolicy{policyStatement1, policyStatement2}
+	err = policyDoc.Validate()
+	if err == nil || err.Error() != "registry scope \"example.com/team/*\" is present in multiple trust policy statements \"test-statement-name-1\" and \"test-statement-name-2\", one registry scope value can only be associated with one statement" {
+		t.Fatalf("identical prefix scopes should return error. Error : %q", err)
+	}
+
+	// nested prefix scopes in different statements are allowed
+	policyStatement2.RegistryScopes = []string{"example.com/team/app/*"}
+	policyDoc.TrustPolicies = []TrustPolicy{policyStatement1, policyStatement2}
+	if err := policyDoc.Validate(); err != nil {
+		t.Fatalf("nested prefix scopes should not return error. Error : %q", err)
+	}
+
+	// host-only prefix scopes apply to every repository of the registry
+	for _, scope := range []string{"example.com/*", "localhost:5000/*", "local/*"} {
+		policyStatement.RegistryScopes = []string{scope}
+		policyDoc.TrustPolicies = []TrustPolicy{policyStatement}
+		if err := policyDoc.Validate(); err != nil {
+			t.Fatalf("host-only prefix scope %q should not return error. Error : %q", scope, err)
+		}
+	}
+	policyStatement2.RegistryScopes = []string{"Example.com/*"}
+	policyStatement1.RegistryScopes = []string{"example.com/*"}
+	policyDoc.TrustPolicies = []TrustPolicy{policyStatement1, policyStatement2}
+	err = policyDoc.Validate()
+	if err == nil || err.Error() != "registry scope \"Example.com/*\" of trust policy statement \"test-statement-name-2\" is equivalent to registry scope \"example.com/*\" of trust policy statement \"test-statement-name-1\", one registry scope value can only be associated with one statement" {
+		t.Fatalf("equivalent host-only prefix scopes should return error. Error : %q", err)
+	}
+	for _, scope := range []string{"/*", "exa_mple.com/*", "example.com:port/*"} {
+		policyStatement.RegistryScopes = []string{scope}
+		policyDoc.TrustPolicies = []TrustPolicy{policyStatement}
+		if err := policyDoc.Validate(); err == nil {
+			t.Fatalf("invalid host-only prefix scope %q should return error", scope)
+		}
+	}
+}
+
 // TestValidRegistryScopes tests valid scopes are accepted
 func TestValidRegistryScopes(t *testing.T) {
 	validScopes := []string{
 		"*", "example.com/rep", "example.com:8080/rep/rep2", "example.com/rep/subrep/subsub",
 		"10.10.10.10:8080/rep/rep2", "domain/rep", "domain:1234/rep",
+		"example.com/rep/*", "example.com:8080/rep/rep2/*",
 	}
 
 	for _, scope := range validScopes {
@@ -298,10 +518,53 @@ func TestValidateInvalidPolicyDocument(t *testing.T) {
 	policyStatement2.Name = "test-statement-name-2"
 	policyDoc.TrustPolicies = []TrustPolicy{policyStatement1, policyStatement2}
 	err = policyDoc.Validate()
-	if err == nil || err.Error() != "registry scope \"registry.acme-rockets.io/software/net-monitor\" is present in multiple trust policy statements, one registry scope value can only be associated with one statement" {
+	if err == nil || err.Error() != "registry scope \"registry.acme-rockets.io/software/net-monitor\" is present in multiple trust policy statements \"test-statement-name\" and \"test-statement-name-2\", one registry scope value can only be associated with one statement" {
 		t.Fatalf("Policy statements with same registry scope should return error %q", err)
 	}
 
+	// Multiple policy statements with registry scopes differing only in the
+	// case of the registry domain
+	policyDoc = dummyPolicyDocument()
+	policyStatement1 = dummyPolicyStatement()
+	policyStatement2 = dummyPolicyStatement()
+	policyStatement2.Name = "test-statement-name-2"
+	policyStatement2.RegistryScopes = []string{"Registry.Acme-Rockets.IO/software/net-monitor"}
+	policyDoc.TrustPolicies = []TrustPolicy{policyStatement1, policyStatement2}
+	err = policyDoc.Validate()
+	if err == nil || err.Error() != "registry scope \"Registry.Acme-Rockets.IO/software/net-monitor\" of trust policy statement \"test-statement-name-2\" is equivalent to registry scope \"registry.acme-rockets.io/software/net-monitor\" of trust policy statement \"test-statement-name\", one registry scope value can only be associated with one statement" {
+		t.Fatalf("Policy statements with equivalent registry scopes should return error %q", err)
+	}
+
+	// Policy statement listing the same registry scope twice
+	policyDoc = dummyPolicyDocument()
+	policyStatement1 = dummyPolicyStatement()
+	policyStatement1.RegistryScopes = []string{"registry.acme-rockets.io/software/net-monitor", "registry.acme-rockets.io/software/net-monitor"}
+	policyDoc.TrustPolicies = []TrustPolicy{policyStatement1}
+	err = policyDoc.Validate()
+	if err == nil || err.Error() != "trust policy statement \"test-statement-name\" lists registry scope \"registry.acme-rockets.io/software/net-monitor\" more than once" {
+		t.Fatalf("Policy statement with repeated registry scope should return error %q", err)
+	}
+	policyStatement1.RegistryScopes = []string{"registry.acme-rockets.io/software/net-monitor", "Registry.Acme-Rockets.io/software/net-monitor"}
+	policyDoc.TrustPolicies = []TrustPolicy{policyStatement1}
+	err = policyDoc.Validate()
+	if err == nil || err.Error() != "trust policy statement \"test-statement-name\" lists registry scope \"Registry.Acme-Rockets.io/software/net-monitor\" more than once" {
+		t.Fatalf("Policy statement with equivalent registry scopes should return error %q", err)
+	}
+
+	// Overlapping registry scopes resolved by precedence are not conflicts
+	policyDoc = dummyPolicyDocument()
+	policyStatement1 = dummyPolicyStatement()
+	policyStatement1.RegistryScopes = []string{"registry.acme-rockets.io/software/*"}
+	policyStatement2 = dummyPolicyStatement()
+	policyStatement2.Name = "test-statement-name-2"
+	policyStatement3 := dummyPolicyStatement()
+	policyStatement3.Name = "test-statement-name-3"
+	policyStatement3.RegistryScopes = []string{"*"}
+	policyDoc.TrustPolicies = []TrustPolicy{policyStatement1, policyStatement2, policyStatement3}
+	if err := policyDoc.Validate(); err != nil {
+		t.Fatalf("Policy statements with overlapping registry scopes of different specificity should not return error %q", err)
+	}
+
 	// Registry scopes with a wildcard
 	policyDoc = dummyPolicyDocument()
 	policyStatement = dummyPolicyStatement()
@@ -423,6 +686,28 @@ func TestValidateInvalidPolicyDocument(t *testing.T) {
 		t.Fatalf("policy statement with trust store missing named store should return error")
 	}
 
+	// Path traversal in Named Store
+	policyDoc = dummyPolicyDocument()
+	policyStatement = dummyPolicyStatement()
+	policyStatement.TrustStores = []string{"ca:../../etc"}
+	policyDoc.TrustPolicies = []TrustPolicy{policyStatement}
+	err = policyDoc.Validate()
+	if err == nil || err.Error() != "trust policy statement \"test-statement-name\" uses an unsupported trust store name \"../../etc\" in trust store value \"ca:../../etc\". Named store name needs to follow [a-zA-Z0-9_.-]+ format" {
+		t.Fatalf("policy statement with path traversal in named store should return error")
+	}
+
+	for _, namedStore := range []string{".", "..", "valid..name"} {
+		policyDoc = dummyPolicyDocument()
+		policyStatement = dummyPolicyStatement()
+		policyStatement.TrustStores = []string{"ca:" + namedStore}
+		policyDoc.TrustPolicies = []TrustPolicy{policyStatement}
+		err = policyDoc.Validate()
+		expectedErr := fmt.Sprintf("trust policy statement \"test-statement-name\" uses an unsafe trust store name %q in trust store value %q. Named store name cannot be \".\" or contain \"..\"", namedStore, "ca:"+namedStore)
+		if err == nil || err.Error() != expectedErr {
+			t.Fatalf("policy statement with unsafe named store %q should return error, got: %v", namedStore, err)
+		}
+	}
+
 	// trusted identities with a wildcard
 	policyDoc = dummyPolicyDocument()
 	policyStatement = dummyPolicyStatement()
@@ -445,6 +730,136 @@ func TestValidateInvalidPolicyDocument(t *testing.T) {
 	}
 }
 
+func TestParseTrustStore(t *testing.T) {
+	tests := []struct {
+		trustStore string
+		wantType   truststore.Type
+		wantName   string
+		wantErr    bool
+	}{
+		{trustStore: "ca:acme-rockets", wantType: truststore.TypeCA, wantName: "acme-rockets"},
+		{trustStore: "signingAuthority:acme.rockets_2", wantType: truststore.TypeSigningAuthority, wantName: "acme.rockets_2"},
+		{trustStore: "tsa:timestamp", wantType: truststore.TypeTSA, wantName: "timestamp"},
+		{trustStore: "", wantErr: true},
+		{trustStore: "ca", wantErr: true},
+		{trustStore: "invalid:acme-rockets", wantErr: true},
+		{trustStore: "ca:", wantErr: true},
+		{trustStore: "ca:acme:rockets", wantErr: true},
+		{trustStore: "ca:..", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.trustStore, func(t *testing.T) {
+			storeType, name, err := ParseTrustStore(tt.trustStore)
+			if tt.wantErr {
+				var trustStoreErr InvalidTrustStoreError
+				if !errors.As(err, &trustStoreErr) || trustStoreErr.TrustStore != tt.trustStore {
+					t.Fatalf("ParseTrustStore(%q) should return InvalidTrustStoreError, got: %v", tt.trustStore, err)
+				}
+				return
+			}
+			if err != nil || storeType != tt.wantType || name != tt.wantName {
+				t.Fatalf("ParseTrustStore(%q) = %q, %q, %v, want %q, %q", tt.trustStore, storeType, name, err, tt.wantType, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestValidateRequiredSignerGroups(t *testing.T) {
+	signerGroupsStatement := func() TrustPolicy {
+		policyStatement := dummyPolicyStatement()
+		policyStatement.TrustStores = nil
+		policyStatement.TrustedIdentities = nil
+		policyStatement.RequiredSignerGroups = []SignerGroup{
+			{
+				Name:              "build",
+				TrustStores:       []string{"ca:build"},
+				TrustedIdentities: []string{"x509.subject:C=US,ST=WA,O=Build"},
+			},
+			{
+				Name:              "security",
+				TrustStores:       []string{"ca:security"},
+				TrustedIdentities: []string{"x509.subject:C=US,ST=WA,O=Security"},
+			},
+		}
+		return policyStatement
+	}
+
+	// Valid signer groups
+	policyDoc := dummyPolicyDocument()
+	policyDoc.TrustPolicies = []TrustPolicy{signerGroupsStatement()}
+	if err := policyDoc.Validate(); err != nil {
+		t.Fatalf("validation failed on a good policy document. Error : %q", err)
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(*TrustPolicy)
+		wantErr string
+	}{
+		{
+			name: "skip with signer groups",
+			modify: func(p *TrustPolicy) {
+				p.SignatureVerification = SignatureVerification{VerificationLevel: "skip"}
+			},
+			wantErr: "trust policy statement \"test-statement-name\" is set to skip signature verification but configured with required signer groups, remove them if signature verification needs to be skipped",
+		},
+		{
+			name: "statement trust stores with signer groups",
+			modify: func(p *TrustPolicy) {
+				p.TrustStores = []string{"ca:valid-trust-store"}
+			},
+			wantErr: "trust policy statement \"test-statement-name\" is configured with both required signer groups and trust stores and/or trusted identities, specify them per signer group instead",
+		},
+		{
+			name: "missing group name",
+			modify: func(p *TrustPolicy) {
+				p.RequiredSignerGroups[1].Name = ""
+			},
+			wantErr: "trust policy statement \"test-statement-name\" has a required signer group missing a name, every signer group requires a name",
+		},
+		{
+			name: "duplicate group name",
+			modify: func(p *TrustPolicy) {
+				p.RequiredSignerGroups[1].Name = "build"
+			},
+			wantErr: "trust policy statement \"test-statement-name\" has multiple required signer groups named \"build\", signer group names must be unique",
+		},
+		{
+			name: "missing group trust stores",
+			modify: func(p *TrustPolicy) {
+				p.RequiredSignerGroups[1].TrustStores = nil
+			},
+			wantErr: "trust policy statement \"test-statement-name\" has required signer group \"security\" missing trust stores or trusted identities, both must be specified",
+		},
+		{
+			name: "invalid group trust store",
+			modify: func(p *TrustPolicy) {
+				p.RequiredSignerGroups[1].TrustStores = []string{"invalid:security"}
+			},
+			wantErr: "required signer group \"security\" is invalid: trust policy statement \"test-statement-name\" uses an unsupported trust store type \"invalid\" in trust store value \"invalid:security\"",
+		},
+		{
+			name: "invalid group trusted identity",
+			modify: func(p *TrustPolicy) {
+				p.RequiredSignerGroups[1].TrustedIdentities = []string{"x509.subject"}
+			},
+			wantErr: "required signer group \"security\" is invalid: trust policy statement \"test-statement-name\" has trusted identity \"x509.subject\" missing separator",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policyStatement := signerGroupsStatement()
+			tt.modify(&policyStatement)
+			policyDoc := dummyPolicyDocument()
+			policyDoc.TrustPolicies = []TrustPolicy{policyStatement}
+			err := policyDoc.Validate()
+			if err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("expected error %q, but got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
 func TestGetVerificationLevel(t *testing.T) {
 	tests := []struct {
 		verificationLevel   SignatureVerification
@@ -475,6 +890,102 @@ func TestGetVerificationLevel(t *testing.T) {
 	}
 }
 
+func TestSignatureVerificationUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    SignatureVerification
+		wantErr bool
+	}{
+		{name: "string form", data: `"strict"`, want: SignatureVerification{VerificationLevel: "strict"}},
+		{name: "object form", data: `{"level":"strict"}`, want: SignatureVerification{VerificationLevel: "strict"}},
+		{
+			name: "object form with override",
+			data: `{"level":"strict","override":{"expiry":"log"}}`,
+			want: SignatureVerification{VerificationLevel: "strict", Override: map[ValidationType]ValidationAction{TypeExpiry: ActionLog}},
+		},
+		{name: "null", data: `null`},
+		{name: "invalid type", data: `1`, wantErr: true},
+		{name: "invalid object", data: `{"level":1}`, wantErr: true},
+		{name: "unknown field", data: `{"level":"audit","overide":{"authenticity":"enforce"}}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got SignatureVerification
+			err := json.Unmarshal([]byte(tt.data), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("UnmarshalJSON() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	// both forms can be mixed in a document
+	document := `{
+		"version": "1.0",
+		"trustPolicies": [
+			{
+				"name": "wabbit-networks-images",
+				"registryScopes": ["registry.wabbit-networks.io/software/net-monitor"],
+				"signatureVerification": "strict",
+				"trustStores": ["ca:valid-trust-store"],
+				"trustedIdentities": ["*"]
+			},
+			{
+				"name": "legacy-images",
+				"registryScopes": ["registry.wabbit-networks.io/software/legacy"],
+				"signatureVerification": {"level": "strict", "override": {"expiry": "log"}},
+				"trustStores": ["ca:valid-trust-store"],
+				"trustedIdentities": ["*"]
+			}
+		]
+	}`
+	policyDoc, err := ParsePolicyDocument(strings.NewReader(document))
+	if err != nil {
+		t.Fatalf("ParsePolicyDocument should accept both signature verification forms. Error: %v", err)
+	}
+	level, err := policyDoc.TrustPolicies[1].SignatureVerification.GetVerificationLevel()
+	if err != nil || level.Enforcement[TypeExpiry] != ActionLog || level.Enforcement[TypeIntegrity] != ActionEnforce {
+		t.Fatalf("unexpected verification level %+v, error: %v", level, err)
+	}
+
+	// unknown fields inside signatureVerification are rejected
+	document = `{
+		"version": "1.0",
+		"trustPolicies": [
+			{
+				"name": "legacy-images",
+				"registryScopes": ["*"],
+				"signatureVerification": {"level": "audit", "overide": {"authenticity": "enforce"}},
+				"trustStores": ["ca:valid-trust-store"],
+				"trustedIdentities": ["*"]
+			}
+		]
+	}`
+	var malformedErr MalformedDocumentError
+	if _, err = ParsePolicyDocument(strings.NewReader(document)); !errors.As(err, &malformedErr) || !strings.Contains(err.Error(), `unknown field "overide"`) {
+		t.Fatalf("ParsePolicyDocument should reject unknown fields inside signatureVerification, got: %v", err)
+	}
+
+	// the skip level cannot be customized
+	document = `{
+		"version": "1.0",
+		"trustPolicies": [
+			{
+				"name": "skipped-images",
+				"registryScopes": ["*"],
+				"signatureVerification": {"level": "skip", "override": {"expiry": "log"}}
+			}
+		]
+	}`
+	var invalidErr InvalidDocumentError
+	if _, err = ParsePolicyDocument(strings.NewReader(document)); !errors.As(err, &invalidErr) {
+		t.Fatalf("ParsePolicyDocument should reject overrides of the skip level, got: %v", err)
+	}
+}
+
 func TestCustomVerificationLevel(t *testing.T) {
 	tests := []struct {
 		customVerification  SignatureVerification
@@ -567,6 +1078,231 @@ func TestApplicableTrustPolicy(t *testing.T) {
 	if policy.Name != wildcardStatement.Name || err != nil {
 		t.Fatalf("getApplicableTrustPolicy should return wildcard policy for registry scope \"some.registry.that/has.no.policy\"")
 	}
+
+	// exact registry scope takes precedence over a preceding wildcard scope
+	policyDoc.TrustPolicies = []TrustPolicy{
+		wildcardStatement,
+		policyStatement,
+	}
+	policy, err = (&policyDoc).GetApplicableTrustPolicy(registryUri)
+	if err != nil || policy.Name != policyStatement.Name {
+		t.Fatalf("getApplicableTrustPolicy should return %q for registry scope %q, got: %v, error: %v", policyStatement.Name, registryScope, policy, err)
+	}
+
+	// tag references
+	for _, reference := range []string{registryScope + ":v1", "registry.wabbit-networks.io:5000/software/app:v1"} {
+		policy, err = (&policyDoc).GetApplicableTrustPolicy(reference)
+		if err != nil {
+			t.Fatalf("getApplicableTrustPolicy should accept tag reference %q, error: %v", reference, err)
+		}
+	}
+	if policy.Name != wildcardStatement.Name {
+		t.Fatalf("getApplicableTrustPolicy should return wildcard policy for registry scope with port, got: %q", policy.Name)
+	}
+	policy, err = (&policyDoc).GetApplicableTrustPolicy(registryScope + ":v1")
+	if err != nil || policy.Name != policyStatement.Name {
+		t.Fatalf("getApplicableTrustPolicy should return %q for tag reference, got: %v, error: %v", policyStatement.Name, policy, err)
+	}
+
+	// references without a tag or digest
+	for _, reference := range []string{registryScope, "registry.wabbit-networks.io:5000/software/app"} {
+		if _, err = (&policyDoc).GetApplicableTrustPolicy(reference); err == nil {
+			t.Fatalf("getApplicableTrustPolicy should reject reference %q without a tag or digest", reference)
+		}
+	}
+
+	// registry domains are matched case-insensitively
+	mixedCaseStatement := dummyPolicyStatement()
+	mixedCaseStatement.Name = "test-statement-name-mixed-case"
+	mixedCaseStatement.RegistryScopes = []string{"Registry.Example.com/app", "registry.example.com/team/*"}
+	policyDoc.TrustPolicies = []TrustPolicy{mixedCaseStatement}
+	for _, reference := range []string{"registry.example.com/app@sha256:hash", "REGISTRY.example.com/app:v1", "Registry.Example.COM/team/app:v1"} {
+		policy, err = (&policyDoc).GetApplicableTrustPolicy(reference)
+		if err != nil || policy.Name != mixedCaseStatement.Name {
+			t.Fatalf("getApplicableTrustPolicy should return %q for %q, got: %v, error: %v", mixedCaseStatement.Name, reference, policy, err)
+		}
+	}
+
+	// wildcard prefix registry scopes
+	prefixStatement := dummyPolicyStatement()
+	prefixStatement.Name = "test-statement-name-3"
+	prefixStatement.RegistryScopes = []string{"registry.wabbit-networks.io/software/*"}
+	longerPrefixStatement := dummyPolicyStatement()
+	longerPrefixStatement.Name = "test-statement-name-4"
+	longerPrefixStatement.RegistryScopes = []string{"registry.wabbit-networks.io/software/unsigned/*"}
+	hostPrefixStatement := dummyPolicyStatement()
+	hostPrefixStatement.Name = "test-statement-name-5"
+	hostPrefixStatement.RegistryScopes = []string{"registry.wabbit-networks.io/*"}
+	policyDoc.TrustPolicies = []TrustPolicy{
+		wildcardStatement,
+		hostPrefixStatement,
+		longerPrefixStatement,
+		prefixStatement,
+		policyStatement,
+	}
+	tests := []struct {
+		reference string
+		want      string
+	}{
+		{registryUri, policyStatement.Name},
+		{"registry.wabbit-networks.io/software/unsigned/other@sha256:hash", longerPrefixStatement.Name},
+		{"registry.wabbit-networks.io/software/unsigned/nested/app:v1", longerPrefixStatement.Name},
+		{"registry.wabbit-networks.io/software/app@sha256:hash", prefixStatement.Name},
+		{"registry.wabbit-networks.io/softwareapp/app@sha256:hash", hostPrefixStatement.Name},
+		{"registry.wabbit-networks.io/software@sha256:hash", hostPrefixStatement.Name},
+		{"REGISTRY.wabbit-networks.io/app:v1", hostPrefixStatement.Name},
+		{"registry.wabbit-networks.io.evil/app@sha256:hash", wildcardStatement.Name},
+		{"registry.acme-rockets.io/app@sha256:hash", wildcardStatement.Name},
+	}
+	for _, tt := range tests {
+		policy, err = (&policyDoc).GetApplicableTrustPolicy(tt.reference)
+		if err != nil || policy.Name != tt.want {
+			t.Fatalf("getApplicableTrustPolicy should return %q for %q, got: %v, error: %v", tt.want, tt.reference, policy, err)
+		}
+	}
+}
+
+func TestClone(t *testing.T) {
+	policyDoc := dummyPolicyDocument()
+	policyStatement := dummyPolicyStatement()
+	policyStatement.Name = "test-statement-name-2"
+	policyStatement.RegistryScopes = []string{"registry.acme-rockets.io/software/legacy"}
+	policyStatement.SignatureVerification.Override = map[ValidationType]ValidationAction{TypeExpiry: ActionLog}
+	policyStatement.TrustStores = nil
+	policyStatement.TrustedIdentities = nil
+	policyStatement.RequiredSignerGroups = []SignerGroup{{
+		Name:              "security",
+		TrustStores:       []string{"ca:security"},
+		TrustedIdentities: []string{"*"},
+	}}
+	policyDoc.TrustPolicies = append(policyDoc.TrustPolicies, policyStatement)
+	want, err := json.Marshal(policyDoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clone := policyDoc.Clone()
+	got, err := json.Marshal(clone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(want) {
+		t.Fatalf("Clone() = %s, want %s", got, want)
+	}
+
+	clone.Version = "2.0"
+	clone.TrustPolicies[0].RegistryScopes[0] = "registry.acme-rockets.io/modified"
+	clone.TrustPolicies[0].TrustedIdentities[0] = "*"
+	clone.TrustPolicies[0].TrustStores = append(clone.TrustPolicies[0].TrustStores, "ca:modified")
+	clone.TrustPolicies[1].SignatureVerification.Override[TypeExpiry] = ActionSkip
+	clone.TrustPolicies[1].RequiredSignerGroups[0].TrustedIdentities[0] = "x509.subject:C=US,ST=WA,O=Modified"
+	clone.TrustPolicies = append(clone.TrustPolicies, dummyPolicyStatement())
+	if got, _ = json.Marshal(policyDoc); string(got) != string(want) {
+		t.Fatalf("modifying the clone changed the original document to %s", got)
+	}
+
+	if (*Document)(nil).Clone() != nil {
+		t.Fatal("Clone() of a nil document should return nil")
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	policyDoc := Document{
+		Version: "1.0",
+		TrustPolicies: []TrustPolicy{
+			{
+				Name:                  "wabbit-networks-images",
+				RegistryScopes:        []string{"registry.wabbit-networks.io/software/net-utils", "registry.wabbit-networks.io/software/net-monitor"},
+				SignatureVerification: SignatureVerification{VerificationLevel: "strict", Override: map[ValidationType]ValidationAction{TypeRevocation: ActionLog, TypeExpiry: ActionLog}},
+				TrustStores:           []string{"signingAuthority:wabbit-networks", "ca:wabbit-networks"},
+				TrustedIdentities:     []string{"x509.subject:C=US,ST=WA,O=wabbit-networks.io", "x509.subject:C=US,ST=WA,O=acme-rockets.io"},
+			},
+			{
+				Name:                  "unsigned-images",
+				RegistryScopes:        []string{"*"},
+				SignatureVerification: SignatureVerification{VerificationLevel: "skip"},
+				TrustStores:           []string{},
+			},
+		},
+	}
+	want := `{
+  "version": "1.0",
+  "trustPolicies": [
+    {
+      "name": "unsigned-images",
+      "registryScopes": [
+        "*"
+      ],
+      "signatureVerification": {
+        "level": "skip"
+      }
+    },
+    {
+      "name": "wabbit-networks-images",
+      "registryScopes": [
+        "registry.wabbit-networks.io/software/net-monitor",
+        "registry.wabbit-networks.io/software/net-utils"
+      ],
+      "signatureVerification": {
+        "level": "strict",
+        "override": {
+          "expiry": "log",
+          "revocation": "log"
+        }
+      },
+      "trustStores": [
+        "ca:wabbit-networks",
+        "signingAuthority:wabbit-networks"
+      ],
+      "trustedIdentities": [
+        "x509.subject:C=US,ST=WA,O=acme-rockets.io",
+        "x509.subject:C=US,ST=WA,O=wabbit-networks.io"
+      ]
+    }
+  ]
+}
+`
+	got, err := policyDoc.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if string(got) != want {
+		t.Fatalf("Marshal() = %s, want %s", got, want)
+	}
+	if policyDoc.TrustPolicies[0].Name != "wabbit-networks-images" || policyDoc.TrustPolicies[0].RegistryScopes[0] != "registry.wabbit-networks.io/software/net-utils" {
+		t.Fatalf("Marshal() modified the document")
+	}
+
+	// marshaling round-trips
+	parsed, err := ParsePolicyDocument(bytes.NewReader(got))
+	if err != nil {
+		t.Fatalf("ParsePolicyDocument() error = %v", err)
+	}
+	if remarshaled, err := parsed.Marshal(); err != nil || string(remarshaled) != want {
+		t.Fatalf("Marshal() of the parsed document = %s, error = %v, want %s", remarshaled, err, want)
+	}
+
+	if _, err := (*Document)(nil).Marshal(); err == nil {
+		t.Fatal("Marshal() of a nil document should return error")
+	}
+}
+
+func TestGetTrustPolicy(t *testing.T) {
+	policyDoc := dummyPolicyDocument()
+
+	policy, err := (&policyDoc).GetTrustPolicy("test-statement-name")
+	if err != nil || policy.Name != "test-statement-name" {
+		t.Fatalf("GetTrustPolicy should return %q, got: %v, error: %v", "test-statement-name", policy, err)
+	}
+	policy.TrustStores[0] = "ca:modified"
+	if policyDoc.TrustPolicies[0].TrustStores[0] == "ca:modified" {
+		t.Fatalf("GetTrustPolicy should return a deep copy of the statement")
+	}
+
+	policy, err = (&policyDoc).GetTrustPolicy("non-existing-statement")
+	if policy != nil || err == nil || err.Error() != "trust policy statement \"non-existing-statement\" is not found in the trust policy document" {
+		t.Fatalf("GetTrustPolicy should return error for non existing statement, got: %v", err)
+	}
 }
 
 func TestLoadDocument(t *testing.T) {
@@ -636,3 +1372,140 @@ func TestLoadDocument(t *testing.T) {
 		}
 	})
 }
+
+func TestLoadPolicyDocument(t *testing.T) {
+	t.Run("non-existing policy file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "trustpolicy.json")
+		_, err := LoadPolicyDocument(path)
+		var notFoundErr DocumentNotFoundError
+		if !errors.As(err, &notFoundErr) || !errors.Is(err, os.ErrNotExist) || notFoundErr.Path != path {
+			t.Fatalf("LoadPolicyDocument should return DocumentNotFoundError, got: %v", err)
+		}
+	})
+
+	t.Run("valid policy file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "trustpolicy.json")
+		policyJson, _ := json.Marshal(dummyPolicyDocument())
+		if err := os.WriteFile(path, policyJson, 0600); err != nil {
+			t.Fatalf("failed to write policy file: %v", err)
+		}
+		policyDoc, err := LoadPolicyDocument(path)
+		if err != nil {
+			t.Fatalf("LoadPolicyDocument should not return error for a valid policy file. Error: %v", err)
+		}
+		if !reflect.DeepEqual(*policyDoc, dummyPolicyDocument()) {
+			t.Fatalf("LoadPolicyDocument returned %+v, want %+v", *policyDoc, dummyPolicyDocument())
+		}
+	})
+}
+
+func TestParsePolicyDocument(t *testing.T) {
+	tests := []struct {
+		name      string
+		document  string
+		wantLine  int
+		malformed bool
+	}{
+		{
+			name:      "invalid json",
+			document:  "{\n  \"version\": \"1.0\",\n  \"trustPolicies\": [,]\n}",
+			wantLine:  3,
+			malformed: true,
+		},
+		{
+			name:      "wrong type",
+			document:  "{\n  \"version\": 1.0\n}",
+			wantLine:  2,
+			malformed: true,
+		},
+		{
+			name:      "unknown top level field",
+			document:  `{"version": "1.0", "trustPolicys": []}`,
+			malformed: true,
+		},
+		{
+			name:      "unknown statement field",
+			document:  `{"version": "1.0", "trustPolicies": [{"name": "test", "registryScope": ["*"]}]}`,
+			malformed: true,
+		},
+		{
+			name:      "trailing data",
+			document:  `{"version": "1.0", "trustPolicies": []} {}`,
+			wantLine:  1,
+			malformed: true,
+		},
+		{
+			name:     "invalid policy",
+			document: `{"version": "1.0", "trustPolicies": []}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParsePolicyDocument(strings.NewReader(tt.document))
+			var malformedErr MalformedDocumentError
+			var invalidErr InvalidDocumentError
+			if tt.malformed {
+				if !errors.As(err, &malformedErr) {
+					t.Fatalf("ParsePolicyDocument should return MalformedDocumentError, got: %v", err)
+				}
+				if tt.wantLine != 0 && malformedErr.Line != tt.wantLine {
+					t.Fatalf("ParsePolicyDocument reported line %d, want %d", malformedErr.Line, tt.wantLine)
+				}
+			} else if !errors.As(err, &invalidErr) {
+				t.Fatalf("ParsePolicyDocument should return InvalidDocumentError, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestRegisterValidator(t *testing.T) {
+	t.Cleanup(func() {
+		validators = nil
+	})
+	RegisterValidator(func(policyDoc *Document) error {
+		for _, statement := range policyDoc.TrustPolicies {
+			for _, trustStore := range statement.TrustStores {
+				if !strings.HasPrefix(trustStore, "ca:acme-") {
+					return fmt.Errorf("trust policy statement %q uses trust store %q without the required prefix", statement.Name, trustStore)
+				}
+			}
+		}
+		return nil
+	})
+	RegisterValidator(func(policyDoc *Document) error {
+		if len(policyDoc.TrustPolicies) > 1 {
+			return errors.New("only one trust policy statement is allowed")
+		}
+		return nil
+	})
+
+	t.Run("conforming document", func(t *testing.T) {
+		policyDoc := dummyPolicyDocument()
+		policyDoc.TrustPolicies[0].TrustStores = []string{"ca:acme-roots"}
+		if err := policyDoc.Validate(); err != nil {
+			t.Fatalf("expected conforming document to be valid, but got %v", err)
+		}
+	})
+
+	t.Run("non-conforming document", func(t *testing.T) {
+		policyDoc := dummyPolicyDocument()
+		statement := dummyPolicyStatement()
+		statement.Name = "test-statement-name-2"
+		statement.RegistryScopes = []string{"registry.acme-rockets.io/software/other"}
+		policyDoc.TrustPolicies = append(policyDoc.TrustPolicies, statement)
+		err := policyDoc.Validate()
+		expectedErr := "trust policy document violates custom validation rules: trust policy statement \"test-statement-name\" uses trust store \"ca:valid-trust-store\" without the required prefix\nonly one trust policy statement is allowed"
+		if err == nil || err.Error() != expectedErr {
+			t.Fatalf("expected error %q, but got %v", expectedErr, err)
+		}
+	})
+
+	t.Run("built-in rules run first", func(t *testing.T) {
+		policyDoc := dummyPolicyDocument()
+		policyDoc.Version = ""
+		err := policyDoc.Validate()
+		if err == nil || err.Error() != "trust policy document is missing or has empty version, it must be specified" {
+			t.Fatalf("expected built-in validation error, but got %v", err)
+		}
+	})
+}
@@ -16,12 +16,14 @@ package truststore
 
 import (
 	"context"
+	"crypto/sha256"
 	"crypto/x509"
 	"errors"
 	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 
 	corex509 "github.com/notaryproject/notation-core-go/x509"
 	"github.com/notaryproject/notation-go/dir"
@@ -30,18 +32,24 @@ import (
 )
 
 // Type is an enum for trust store types supported such as
-// "ca" and "signingAuthority"
+// "ca", "signingAuthority" and "tsa"
 type Type string
 
 const (
 	TypeCA               Type = "ca"
 	TypeSigningAuthority Type = "signingAuthority"
+	// TypeTSA trust stores hold the root certificates of RFC 3161
+	// timestamping authorities
+	TypeTSA Type = "tsa"
 )
 
 var (
+	// Types are the trust store types accepted in trust policy documents
+	// and by X509TrustStore
 	Types = []Type{
 		TypeCA,
 		TypeSigningAuthority,
+		TypeTSA,
 	}
 )
 
@@ -51,6 +59,44 @@ type X509TrustStore interface {
 	GetCertificates(ctx context.Context, storeType Type, namedStore string) ([]*x509.Certificate, error)
 }
 
+// DisabledCertificateSuffix is the file name suffix of certificate files that
+// are kept in a trust store but disabled, e.g. pending an investigation.
+// Disabled certificates are not trusted, even if they are also present in
+// other certificate files of the trust store.
+const DisabledCertificateSuffix = ".disabled"
+
+// DisabledCertificateLister is implemented by trust stores that can report
+// their disabled certificates
+type DisabledCertificateLister interface {
+	// DisabledCertificates returns the disabled certificates under
+	// storeType/namedStore
+	DisabledCertificates(ctx context.Context, storeType Type, namedStore string) ([]*x509.Certificate, error)
+}
+
+// LoadTrustStore returns the certificates of the trust store namedStore of
+// type storeType located under configDir, i.e. in
+// {configDir}/truststore/x509/{storeType}/{namedStore}. The certificates are
+// loaded and validated as by GetCertificates, a certificate present in
+// several files is returned once.
+func LoadTrustStore(configDir string, storeType Type, namedStore string) ([]*x509.Certificate, error) {
+	certs, err := NewX509TrustStore(dir.NewSysFS(configDir)).GetCertificates(context.Background(), storeType, namedStore)
+	if err != nil {
+		return nil, err
+	}
+
+	fingerprints := make(map[[sha256.Size]byte]struct{}, len(certs))
+	uniqueCerts := certs[:0]
+	for _, cert := range certs {
+		fingerprint := sha256.Sum256(cert.Raw)
+		if _, ok := fingerprints[fingerprint]; ok {
+			continue
+		}
+		fingerprints[fingerprint] = struct{}{}
+		uniqueCerts = append(uniqueCerts, cert)
+	}
+	return uniqueCerts, nil
+}
+
 // NewX509TrustStore generates a new X509TrustStore
 func NewX509TrustStore(trustStorefs dir.SysFS) X509TrustStore {
 	return &x509TrustStore{trustStorefs}
@@ -61,8 +107,48 @@ type x509TrustStore struct {
 	trustStorefs dir.SysFS
 }
 
-// GetCertificates returns certificates under storeType/namedStore
+// GetCertificates returns certificates under storeType/namedStore, excluding
+// the disabled certificates
 func (trustStore *x509TrustStore) GetCertificates(ctx context.Context, storeType Type, namedStore string) ([]*x509.Certificate, error) {
+	certificates, err := trustStore.loadCertificates(storeType, namedStore, false)
+	if err != nil {
+		return nil, err
+	}
+	disabledCertificates, err := trustStore.loadCertificates(storeType, namedStore, true)
+	if err != nil {
+		return nil, err
+	}
+	if len(disabledCertificates) > 0 {
+		// a disabled certificate is not trusted even if it is also present in
+		// an enabled file, e.g. a copy of or a symlink to the disabled file
+		disabled := make(map[[sha256.Size]byte]struct{}, len(disabledCertificates))
+		for _, cert := range disabledCertificates {
+			disabled[sha256.Sum256(cert.Raw)] = struct{}{}
+		}
+		enabledCertificates := certificates[:0]
+		for _, cert := range certificates {
+			if _, ok := disabled[sha256.Sum256(cert.Raw)]; !ok {
+				enabledCertificates = append(enabledCertificates, cert)
+			}
+		}
+		certificates = enabledCertificates
+	}
+	if len(certificates) < 1 {
+		return nil, CertificateError{InnerError: fs.ErrNotExist, Msg: fmt.Sprintf("no x509 certificates were found in trust store %q of type %q", namedStore, storeType)}
+	}
+	return certificates, nil
+}
+
+// DisabledCertificates returns the disabled certificates under
+// storeType/namedStore, i.e. the certificates in files with the
+// DisabledCertificateSuffix
+func (trustStore *x509TrustStore) DisabledCertificates(ctx context.Context, storeType Type, namedStore string) ([]*x509.Certificate, error) {
+	return trustStore.loadCertificates(storeType, namedStore, true)
+}
+
+// loadCertificates returns either the enabled or the disabled certificates
+// under storeType/namedStore
+func (trustStore *x509TrustStore) loadCertificates(storeType Type, namedStore string, disabled bool) ([]*x509.Certificate, error) {
 	if !isValidStoreType(storeType) {
 		return nil, TrustStoreError{Msg: fmt.Sprintf("unsupported trust store type: %s", storeType)}
 	}
@@ -93,9 +179,18 @@ func (trustStore *x509TrustStore) GetCertificates(ctx context.Context, storeType
 	var certificates []*x509.Certificate
 	for _, file := range files {
 		certFileName := file.Name()
+		if strings.HasSuffix(certFileName, DisabledCertificateSuffix) != disabled {
+			continue
+		}
 		joinedPath := filepath.Join(path, certFileName)
-		if file.IsDir() || file.Type()&fs.ModeSymlink != 0 {
-			return nil, CertificateError{Msg: fmt.Sprintf("trusted certificate %s in trust store %s of type %s is not a regular file (directories or symlinks are not supported)", certFileName, namedStore, storeType)}
+		if file.IsDir() {
+			return nil, CertificateError{Msg: fmt.Sprintf("trusted certificate %s in trust store %s of type %s is not a regular file (directories are not supported)", certFileName, namedStore, storeType)}
+		}
+		if file.Type()&fs.ModeSymlink != 0 {
+			joinedPath, err = resolveCertificateSymlink(path, joinedPath)
+			if err != nil {
+				return nil, CertificateError{InnerError: err, Msg: fmt.Sprintf("trusted certificate %s in trust store %s of type %s is not a valid symlink: %v", certFileName, namedStore, storeType, err)}
+			}
 		}
 		certs, err := corex509.ReadCertificateFile(joinedPath)
 		if err != nil {
@@ -106,12 +201,35 @@ func (trustStore *x509TrustStore) GetCertificates(ctx context.Context, storeType
 		}
 		certificates = append(certificates, certs...)
 	}
-	if len(certificates) < 1 {
-		return nil, CertificateError{InnerError: fs.ErrNotExist, Msg: fmt.Sprintf("no x509 certificates were found in trust store %q of type %q", namedStore, storeType)}
-	}
 	return certificates, nil
 }
 
+// resolveCertificateSymlink resolves the symlink at certPath to the path of
+// the regular file it points to. The file must be located inside storePath,
+// so that a crafted symlink cannot be used to read arbitrary files.
+func resolveCertificateSymlink(storePath, certPath string) (string, error) {
+	resolvedStorePath, err := filepath.EvalSymlinks(storePath)
+	if err != nil {
+		return "", err
+	}
+	resolvedPath, err := filepath.EvalSymlinks(certPath)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(resolvedStorePath, resolvedPath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", errors.New("the symlink points outside of the trust store")
+	}
+	fileInfo, err := os.Stat(resolvedPath)
+	if err != nil {
+		return "", err
+	}
+	if !fileInfo.Mode().IsRegular() {
+		return "", errors.New("the symlink does not point to a regular file")
+	}
+	return resolvedPath, nil
+}
+
 // ValidateCertificates ensures certificates from trust store are
 // CA certificates or self-signed.
 func ValidateCertificates(certs []*x509.Certificate) error {
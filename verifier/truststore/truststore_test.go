@@ -17,7 +17,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io/fs"
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	corex509 "github.com/notaryproject/notation-core-go/x509"
@@ -101,3 +104,202 @@ func TestValidateCertsWithLeafCert(t *testing.T) {
 		t.Fatalf("leaf cert in a trust store should return error %q, got: %v", expectedErr, err)
 	}
 }
+
+func TestLoadTrustStoreWithSymlinks(t *testing.T) {
+	certBytes, err := os.ReadFile(filepath.FromSlash("../testdata/truststore/x509/ca/valid-trust-store/GlobalSign.der"))
+	if err != nil {
+		t.Fatalf("failed to read the test certificate: %v", err)
+	}
+	newTrustStore := func(t *testing.T) (X509TrustStore, string, string) {
+		t.Helper()
+		root := t.TempDir()
+		storePath := filepath.Join(root, "truststore", "x509", "ca", "test-store")
+		if err := os.MkdirAll(storePath, 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(storePath, "GlobalSign.der"), certBytes, 0600); err != nil {
+			t.Fatal(err)
+		}
+		return NewX509TrustStore(dir.NewSysFS(root)), root, storePath
+	}
+
+	t.Run("symlink inside the trust store", func(t *testing.T) {
+		ts, _, storePath := newTrustStore(t)
+		if err := os.Symlink("GlobalSign.der", filepath.Join(storePath, "linked.der")); err != nil {
+			t.Fatal(err)
+		}
+		certs, err := ts.GetCertificates(context.Background(), "ca", "test-store")
+		if err != nil {
+			t.Fatalf("could not get certificates from trust store. %q", err)
+		}
+		if len(certs) != 2 {
+			t.Fatalf("unexpected number of certificates in the trust store, expected: %d, got: %d", 2, len(certs))
+		}
+	})
+
+	t.Run("symlink pointing outside the trust store", func(t *testing.T) {
+		ts, root, storePath := newTrustStore(t)
+		outsidePath := filepath.Join(root, "outside.der")
+		if err := os.WriteFile(outsidePath, certBytes, 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Symlink(outsidePath, filepath.Join(storePath, "malicious.der")); err != nil {
+			t.Fatal(err)
+		}
+		expectedErrMsg := "trusted certificate malicious.der in trust store test-store of type ca is not a valid symlink: the symlink points outside of the trust store"
+		_, err := ts.GetCertificates(context.Background(), "ca", "test-store")
+		var certErr CertificateError
+		if !errors.As(err, &certErr) || err.Error() != expectedErrMsg {
+			t.Fatalf("expected error %q, got: %v", expectedErrMsg, err)
+		}
+	})
+
+	t.Run("relative symlink escaping the trust store", func(t *testing.T) {
+		ts, root, storePath := newTrustStore(t)
+		if err := os.WriteFile(filepath.Join(root, "truststore", "x509", "ca", "outside.der"), certBytes, 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Symlink(filepath.Join("..", "outside.der"), filepath.Join(storePath, "malicious.der")); err != nil {
+			t.Fatal(err)
+		}
+		_, err := ts.GetCertificates(context.Background(), "ca", "test-store")
+		if err == nil || !strings.Contains(err.Error(), "the symlink points outside of the trust store") {
+			t.Fatalf("expected symlink error, got: %v", err)
+		}
+	})
+
+	t.Run("dangling symlink", func(t *testing.T) {
+		ts, _, storePath := newTrustStore(t)
+		if err := os.Symlink("missing.der", filepath.Join(storePath, "dangling.der")); err != nil {
+			t.Fatal(err)
+		}
+		_, err := ts.GetCertificates(context.Background(), "ca", "test-store")
+		if err == nil || !strings.Contains(err.Error(), "is not a valid symlink") {
+			t.Fatalf("expected symlink error, got: %v", err)
+		}
+	})
+}
+
+func TestDisabledCertificates(t *testing.T) {
+	root := t.TempDir()
+	storePath := filepath.Join(root, "truststore", "x509", "ca", "test-store")
+	if err := os.MkdirAll(storePath, 0700); err != nil {
+		t.Fatal(err)
+	}
+	for src, dst := range map[string]string{
+		"../testdata/truststore/x509/ca/valid-trust-store/GlobalSign.der":                              "GlobalSign.der",
+		"../testdata/truststore/x509/ca/valid-trust-store-self-signed/openssl-minimum-self-signed.pem": "self-signed.pem" + DisabledCertificateSuffix,
+	} {
+		certBytes, err := os.ReadFile(filepath.FromSlash(src))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(storePath, dst), certBytes, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ts := NewX509TrustStore(dir.NewSysFS(root))
+
+	certs, err := ts.GetCertificates(context.Background(), "ca", "test-store")
+	if err != nil {
+		t.Fatalf("could not get certificates from trust store. %q", err)
+	}
+	if len(certs) != 1 || certs[0].Subject.CommonName != "GlobalSign" {
+		t.Fatalf("expected only the enabled certificate, but got %v", certs)
+	}
+
+	disabledCerts, err := ts.(DisabledCertificateLister).DisabledCertificates(context.Background(), "ca", "test-store")
+	if err != nil {
+		t.Fatalf("could not get disabled certificates from trust store. %q", err)
+	}
+	if len(disabledCerts) != 1 || disabledCerts[0].Equal(certs[0]) {
+		t.Fatalf("expected only the disabled certificate, but got %v", disabledCerts)
+	}
+
+	// a disabled certificate is not trusted from a copy or a symlink of the
+	// disabled file
+	disabledBytes, err := os.ReadFile(filepath.Join(storePath, "self-signed.pem"+DisabledCertificateSuffix))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(storePath, "copy.pem"), disabledBytes, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("self-signed.pem"+DisabledCertificateSuffix, filepath.Join(storePath, "linked.pem")); err != nil {
+		t.Fatal(err)
+	}
+	certs, err = ts.GetCertificates(context.Background(), "ca", "test-store")
+	if err != nil {
+		t.Fatalf("could not get certificates from trust store. %q", err)
+	}
+	if len(certs) != 1 || certs[0].Subject.CommonName != "GlobalSign" {
+		t.Fatalf("expected only the enabled certificate, but got %v", certs)
+	}
+	for _, name := range []string{"copy.pem", "linked.pem"} {
+		if err := os.Remove(filepath.Join(storePath, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// a store with only disabled certificates has no trusted certificates
+	if err := os.Remove(filepath.Join(storePath, "GlobalSign.der")); err != nil {
+		t.Fatal(err)
+	}
+	_, err = ts.GetCertificates(context.Background(), "ca", "test-store")
+	if err == nil || err.Error() != `no x509 certificates were found in trust store "test-store" of type "ca"` {
+		t.Fatalf("expected no certificates error, got: %v", err)
+	}
+}
+
+func TestLoadTrustStore(t *testing.T) {
+	root := t.TempDir()
+	storePath := filepath.Join(root, "truststore", "x509", "ca", "test-store")
+	if err := os.MkdirAll(storePath, 0700); err != nil {
+		t.Fatal(err)
+	}
+	certBytes, err := os.ReadFile(filepath.FromSlash("../testdata/truststore/x509/ca/valid-trust-store/GlobalSign.der"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the same certificate in two files is returned once
+	for _, name := range []string{"GlobalSign.der", "GlobalSign-copy.der"} {
+		if err := os.WriteFile(filepath.Join(storePath, name), certBytes, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	certs, err := LoadTrustStore(root, TypeCA, "test-store")
+	if err != nil {
+		t.Fatalf("could not load trust store. %q", err)
+	}
+	if len(certs) != 1 || certs[0].Subject.CommonName != "GlobalSign" {
+		t.Fatalf("expected a single certificate, but got %v", certs)
+	}
+
+	// tsa trust stores are loaded like the other types
+	tsaStorePath := filepath.Join(root, "truststore", "x509", "tsa", "test-store")
+	if err := os.MkdirAll(tsaStorePath, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tsaStorePath, "GlobalSign.der"), certBytes, 0600); err != nil {
+		t.Fatal(err)
+	}
+	certs, err = LoadTrustStore(root, TypeTSA, "test-store")
+	if err != nil || len(certs) != 1 {
+		t.Fatalf("could not load tsa trust store, certs: %v, error: %v", certs, err)
+	}
+
+	_, err = LoadTrustStore(root, TypeSigningAuthority, "test-store")
+	var trustStoreErr TrustStoreError
+	if !errors.As(err, &trustStoreErr) || !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("expected trust store does not exist error, got: %v", err)
+	}
+
+	if err := os.MkdirAll(filepath.Join(root, "truststore", "x509", "ca", "empty-store"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	_, err = LoadTrustStore(root, TypeCA, "empty-store")
+	if err == nil || err.Error() != `no x509 certificates were found in trust store "empty-store" of type "ca"` {
+		t.Fatalf("expected no certificates error, got: %v", err)
+	}
+}
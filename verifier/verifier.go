@@ -17,12 +17,13 @@ package verifier
 import (
 	"context"
 	"crypto/x509"
+	"encoding/asn1"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math/big"
 	"net/http"
 	"reflect"
-	"strings"
 	"time"
 
 	"github.com/notaryproject/notation-core-go/revocation"
@@ -31,6 +32,7 @@ import (
 	"github.com/notaryproject/notation-go"
 	"github.com/notaryproject/notation-go/dir"
 	"github.com/notaryproject/notation-go/internal/envelope"
+	"github.com/notaryproject/notation-go/internal/lru"
 	"github.com/notaryproject/notation-go/internal/pkix"
 	"github.com/notaryproject/notation-go/internal/slices"
 	trustpolicyInternal "github.com/notaryproject/notation-go/internal/trustpolicy"
@@ -39,6 +41,7 @@ import (
 	"github.com/notaryproject/notation-go/plugin/proto"
 	"github.com/notaryproject/notation-go/verifier/trustpolicy"
 	"github.com/notaryproject/notation-go/verifier/truststore"
+	"github.com/opencontainers/go-digest"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"golang.org/x/mod/semver"
 	"oras.land/oras-go/v2/content"
@@ -50,14 +53,315 @@ type verifier struct {
 	trustStore       truststore.X509TrustStore
 	pluginManager    plugin.Manager
 	revocationClient revocation.Revocation
+
+	// revocationHTTPClient is the HTTP client of the default revocation
+	// client. It is nil if the revocation client is provided by the caller.
+	revocationHTTPClient *http.Client
+
+	requiredCertificatePolicies []asn1.ObjectIdentifier
+	blockedSerials              []BlockedSerial
+	maxPayloadAnnotationCount   int
+	maxPayloadAnnotationSize    int
+	envelopeCache               *lru.Cache[envelopeCacheKey, *envelope.Envelope]
+	envelopeParser              envelopeParser
+	trustedIdentityResolver     TrustedIdentityResolver
+	trustedIdentityCache        *trustedIdentityCache
+	certificateExpiryWindow     time.Duration
+	fipsOnly                    bool
+	checkTimestampAlgorithm     bool
+	equivalentMediaTypes        map[string][]string
+	outcomeCache                *outcomeCache
+	checkOrder                  []Check
+	clock                       Clock
+	expiryGracePeriod           time.Duration
+	maxSignatureAge             time.Duration
+	maxChainDepth               int
+	sandboxed                   bool
+	tolerateChainOrder          bool
+	unknownHeaderPolicy         UnknownHeaderPolicy
 }
 
+// envelopeParser parses a signature envelope blob of the given media type.
+type envelopeParser func(envelopeMediaType string, envelopeBytes []byte) (*envelope.Envelope, error)
+
+// envelopeCacheKey identifies a parsed signature envelope by its media type
+// and the digest of the raw envelope blob.
+type envelopeCacheKey struct {
+	mediaType string
+	digest    digest.Digest
+}
+
+const (
+	// defaultMaxPayloadAnnotationCount is the default maximum number of
+	// annotations allowed in the signature payload
+	defaultMaxPayloadAnnotationCount = 1024
+
+	// defaultMaxPayloadAnnotationSize is the default maximum total size in
+	// bytes of the keys and values of the annotations in the signature
+	// payload
+	defaultMaxPayloadAnnotationSize = 1024 * 1024
+
+	// defaultMaxChainDepth is the default maximum number of certificates in
+	// the certificate chain of a signature
+	defaultMaxChainDepth = 10
+
+	// maxExpiryGracePeriod is the maximum expiry grace period, it is meant
+	// to tolerate clock skew rather than to extend the validity of
+	// signatures
+	maxExpiryGracePeriod = time.Hour
+)
+
 // VerifierOptions specifies additional parameters that can be set when using
 // the NewWithOptions constructor
 type VerifierOptions struct {
 	// RevocationClient is an implementation of revocation.Revocation to use for
 	// verifying revocation
 	RevocationClient revocation.Revocation
+
+	// RevocationFetcher, if set, fetches the revocation information for the
+	// default revocation client instead of HTTP requests to the OCSP
+	// responders, e.g. to fetch it from a local mirror. It is not used if
+	// RevocationClient is set. The default revocation client checks OCSP
+	// only and does not pass the verification context to the fetcher.
+	RevocationFetcher RevocationFetcher
+
+	// RequiredCertificatePolicies is a list of certificate policy OIDs in
+	// dotted decimal form (e.g. "2.23.140.1.2.1") that the signing certificate
+	// must assert in its certificatePolicies extension. A signing certificate
+	// asserting anyPolicy (2.5.29.32.0) satisfies all required policies.
+	RequiredCertificatePolicies []string
+
+	// BlockedSerials is a list of certificates, identified by their issuer
+	// and serial number, that must not be part of the certificate chain of a
+	// signature. It is a local kill switch that does not depend on CRL or
+	// OCSP: signatures whose chain contains a blocked certificate fail
+	// verification with a CertificateBlockedError regardless of the
+	// verification level of the trust policy.
+	BlockedSerials []BlockedSerial
+
+	// MaxPayloadAnnotationCount is the maximum number of annotations allowed
+	// in the signature payload. The annotation limits are enforced by the
+	// integrity check, and exceeding them fails verification with a
+	// notation.ErrorVerificationFailed. If not set, defaults to 1024.
+	MaxPayloadAnnotationCount int
+
+	// MaxPayloadAnnotationSize is the maximum total size in bytes of the keys
+	// and values of the annotations in the signature payload. If not set,
+	// defaults to 1 MiB.
+	MaxPayloadAnnotationSize int
+
+	// EnvelopeCacheSize is the maximum number of parsed signature envelopes
+	// to be cached, keyed by the sha256 digest of the raw envelope blob.
+	// When the cache is full, the least recently used envelope is evicted.
+	// If not set, parsed signature envelopes are not cached.
+	EnvelopeCacheSize int
+
+	// TrustedIdentityResolver, if set, resolves the trusted identities of the
+	// applicable trust policy statement for each verification, overriding the
+	// static `trustedIdentities` of the statement. It is not used for
+	// statements with required signer groups.
+	TrustedIdentityResolver TrustedIdentityResolver
+
+	// TrustedIdentityCacheTTL is the duration for which the trusted
+	// identities resolved by TrustedIdentityResolver are cached per trust
+	// policy statement and artifact reference. At most 1024 entries are
+	// cached, evicting the least recently used one. If not set, resolved
+	// trusted identities are not cached.
+	TrustedIdentityCacheTTL time.Duration
+
+	// CertificateExpiryWarningWindow is the duration before the expiry of the
+	// signing certificate within which a warning is recorded in the
+	// verification outcome to prompt re-keying. The warning does not fail
+	// the verification. It only applies to the notary.x509 signing scheme.
+	// If not set, no warning is recorded.
+	CertificateExpiryWarningWindow time.Duration
+
+	// FIPSOnly restricts the accepted signature algorithms, certificate
+	// signature algorithms and public keys to the FIPS 140 approved subset,
+	// i.e. RSA and ECDSA with SHA-256, SHA-384 or SHA-512. Signatures using
+	// other algorithms such as Ed25519 or SHA-1 fail the integrity
+	// validation with NonFIPSAlgorithmError.
+	//
+	// FIPSOnly only restricts the algorithms. It does not switch the crypto
+	// backend, so the verifier must be built against a FIPS validated
+	// backend, e.g. with GOEXPERIMENT=boringcrypto, for the cryptographic
+	// operations to be FIPS compliant.
+	FIPSOnly bool
+
+	// CheckTimestampAlgorithmStrength enables checking that the hash
+	// algorithms used by the timestamp token of the signature are at least as
+	// strong as the hash algorithm of the signature algorithm. A downgraded
+	// timestamp fails the authentic timestamp validation, which is enforced
+	// or logged according to the verification level of the trust policy.
+	CheckTimestampAlgorithmStrength bool
+
+	// EquivalentMediaTypes maps a media type to the media types considered
+	// equivalent to it when matching the target artifact of the signature
+	// payload against the artifact being verified. The equivalence is
+	// symmetric. For example, mapping
+	// "application/vnd.docker.distribution.manifest.v2+json" to
+	// "application/vnd.oci.image.manifest.v1+json" lets a signature on an
+	// artifact with either media type verify an artifact served with the
+	// other one, as long as the digest and size match.
+	// If not set, the media types must match exactly.
+	EquivalentMediaTypes map[string][]string
+
+	// OutcomeCacheTTL is the duration for which successful verification
+	// outcomes are cached. The cache is keyed by the digest of the artifact,
+	// the signature, the content of the applicable trust policy statement and
+	// the verify options, so modifying the trust policy invalidates the
+	// cached outcomes. The trusted identities resolved by
+	// TrustedIdentityResolver are not part of the key.
+	// If not set, verification outcomes are not cached.
+	OutcomeCacheTTL time.Duration
+
+	// OutcomeCacheFailureTTL is the duration for which failed verification
+	// outcomes are cached. It should be shorter than OutcomeCacheTTL.
+	// Inconclusive verifications are never cached.
+	// If not set, failed verification outcomes are not cached.
+	OutcomeCacheFailureTTL time.Duration
+
+	// OutcomeCacheSize is the maximum number of cached verification
+	// outcomes. Defaults to 1024 if not set.
+	OutcomeCacheSize int
+
+	// CheckOrder is the order in which the trusted identity, expiry,
+	// authentic timestamp and revocation checks are performed. Integrity and
+	// trust store based authenticity are always verified first. It must
+	// contain each check exactly once, e.g. placing CheckRevocation first
+	// fails fast on revoked certificates.
+	// If not set, the checks are performed in the order defined by the
+	// specification, i.e. trusted identity, expiry, authentic timestamp and
+	// revocation.
+	CheckOrder []Check
+
+	// Clock is the source of the evaluation time, i.e. "now", used by the
+	// expiry check, the certificate expiry warning window and the authentic
+	// timestamp check of signatures without a timestamp. The authentic
+	// timestamp check of the notary.x509.signingAuthority signing scheme
+	// always uses the signed signing time and is not affected by Clock.
+	// Clock does not drive the revocation check: the freshness of OCSP
+	// responses is evaluated by the notation-core-go revocation client
+	// against the system clock, and its revocation.Revocation interface does
+	// not accept an evaluation time. Moving the clock therefore never changes
+	// the revocation result, and a warning is added to the verification
+	// outcome whenever the revocation check runs while Clock is set.
+	// If not set, the system clock is used.
+	Clock Clock
+
+	// ExpiryGracePeriod is the duration past the expiry of a signature during
+	// which the signature is still treated as unexpired, to tolerate clock
+	// skew between the signer and the verifier. A warning is recorded in the
+	// verification outcome when the grace period is applied. It must not
+	// exceed one hour.
+	// If not set, signatures are expired at their expiry time.
+	ExpiryGracePeriod time.Duration
+
+	// MaxSignatureAge is the maximum age of a signature, i.e. the duration
+	// between its signing time and the evaluation time. Older signatures fail
+	// the expiry validation, even if they have not expired yet. The failure
+	// is enforced regardless of the expiry validation action of the trust
+	// policy. The age is measured from the authentic signing time for the
+	// notary.x509.signingAuthority signing scheme, and from the signing time
+	// claimed by the signer for the notary.x509 signing scheme.
+	// If not set, the age of signatures is not checked.
+	MaxSignatureAge time.Duration
+
+	// MaxChainDepth is the maximum number of certificates, including the
+	// root certificate, in the certificate path validated against the trust
+	// store, including the trust store certificates issuing a trusted
+	// intermediate certificate. Longer paths fail the authenticity
+	// validation with a ChainDepthError. If not set, defaults to 10.
+	MaxChainDepth int
+
+	// Sandboxed guarantees that the verifier performs no network and no file
+	// system access of its own during verification:
+	//   - the trust stores referenced by the trust policy are pre-loaded when
+	//     the verifier is created, loading other trust stores fails.
+	//   - the default revocation client is not created, so revocation checks
+	//     fail unless RevocationClient or RevocationFetcher is set to an
+	//     offline implementation or the trust policy skips them.
+	//   - signatures that require a verification plugin fail.
+	// Disallowed operations fail with a SandboxViolationError. The caller is
+	// responsible for RevocationClient, RevocationFetcher and
+	// TrustedIdentityResolver not accessing the network or the file system.
+	Sandboxed bool
+
+	// TolerateChainOrder reorders the certificate chain embedded in the
+	// signature envelope if it is not ordered leaf-first with each
+	// certificate issued by the next one. Chains with unrelated or missing
+	// certificates are still rejected.
+	// If not set, disordered certificate chains are rejected.
+	TolerateChainOrder bool
+
+	// UnknownHeaderPolicy controls how non-critical protected headers of the
+	// signature envelope that are not recognized by notation are treated.
+	// Critical unrecognized headers are always rejected, unless they are
+	// processed by the verification plugin of the signature.
+	// If not set, non-critical unrecognized headers are ignored.
+	UnknownHeaderPolicy UnknownHeaderPolicy
+}
+
+// UnknownHeaderPolicy is the treatment of non-critical unrecognized protected
+// headers of a signature envelope.
+type UnknownHeaderPolicy string
+
+const (
+	// UnknownHeaderIgnore ignores non-critical unrecognized headers, as
+	// allowed by JWS and COSE.
+	UnknownHeaderIgnore UnknownHeaderPolicy = "ignore"
+
+	// UnknownHeaderWarn records a warning in the verification outcome for
+	// each non-critical unrecognized header.
+	UnknownHeaderWarn UnknownHeaderPolicy = "warn"
+
+	// UnknownHeaderReject fails the integrity validation of signatures with
+	// non-critical unrecognized headers.
+	UnknownHeaderReject UnknownHeaderPolicy = "reject"
+)
+
+// BlockedSerial identifies a certificate by its issuer and serial number.
+type BlockedSerial struct {
+	// Issuer is the distinguished name of the issuer of the certificate, as
+	// formatted by pkix.Name.String, e.g. "CN=Example CA,O=Example,C=US".
+	Issuer string
+
+	// SerialNumber is the serial number of the certificate.
+	SerialNumber *big.Int
+}
+
+// Clock provides the current time.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+}
+
+// Check is a verification check whose order is configurable.
+type Check string
+
+const (
+	// CheckTrustedIdentity verifies the signing certificate against the
+	// trusted identities of the trust policy.
+	CheckTrustedIdentity Check = "trustedIdentity"
+
+	// CheckExpiry verifies the expiry of the signature.
+	CheckExpiry Check = "expiry"
+
+	// CheckAuthenticTimestamp verifies the authentic timestamp of the
+	// signature.
+	CheckAuthenticTimestamp Check = "authenticTimestamp"
+
+	// CheckRevocation verifies the revocation status of the certificate
+	// chain.
+	CheckRevocation Check = "revocation"
+)
+
+// defaultCheckOrder is the order of checks defined by the specification.
+var defaultCheckOrder = []Check{
+	CheckTrustedIdentity,
+	CheckExpiry,
+	CheckAuthenticTimestamp,
+	CheckRevocation,
 }
 
 // NewFromConfig returns a verifier based on local file system
@@ -82,9 +386,17 @@ func New(trustPolicy *trustpolicy.Document, trustStore truststore.X509TrustStore
 // pluginManager, and VerifierOptions
 func NewWithOptions(trustPolicy *trustpolicy.Document, trustStore truststore.X509TrustStore, pluginManager plugin.Manager, opts VerifierOptions) (notation.Verifier, error) {
 	revocationClient := opts.RevocationClient
-	if revocationClient == nil {
+	var revocationHTTPClient *http.Client
+	if revocationClient == nil && opts.RevocationFetcher != nil {
 		var err error
-		revocationClient, err = revocation.New(&http.Client{Timeout: 2 * time.Second})
+		revocationClient, err = revocation.New(&http.Client{Transport: &fetcherTransport{fetcher: opts.RevocationFetcher}})
+		if err != nil {
+			return nil, err
+		}
+	} else if revocationClient == nil && !opts.Sandboxed {
+		var err error
+		revocationHTTPClient = &http.Client{Timeout: 2 * time.Second}
+		revocationClient, err = revocation.New(revocationHTTPClient)
 		if err != nil {
 			return nil, err
 		}
@@ -95,11 +407,80 @@ func NewWithOptions(trustPolicy *trustpolicy.Document, trustStore truststore.X50
 	if err := trustPolicy.Validate(); err != nil {
 		return nil, err
 	}
+	var requiredCertificatePolicies []asn1.ObjectIdentifier
+	for _, policy := range opts.RequiredCertificatePolicies {
+		oid, err := parseObjectIdentifier(policy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid required certificate policy: %w", err)
+		}
+		requiredCertificatePolicies = append(requiredCertificatePolicies, oid)
+	}
+	var envelopeCache *lru.Cache[envelopeCacheKey, *envelope.Envelope]
+	if opts.EnvelopeCacheSize > 0 {
+		envelopeCache = lru.New[envelopeCacheKey, *envelope.Envelope](opts.EnvelopeCacheSize)
+	}
+	var identityCache *trustedIdentityCache
+	if opts.TrustedIdentityResolver != nil && opts.TrustedIdentityCacheTTL > 0 {
+		identityCache = newTrustedIdentityCache(opts.TrustedIdentityCacheTTL)
+	}
+	if len(opts.CheckOrder) > 0 {
+		if err := validateCheckOrder(opts.CheckOrder); err != nil {
+			return nil, err
+		}
+	}
+	if opts.ExpiryGracePeriod < 0 || opts.ExpiryGracePeriod > maxExpiryGracePeriod {
+		return nil, fmt.Errorf("expiry grace period %v is out of range, it must be between 0 and %v", opts.ExpiryGracePeriod, maxExpiryGracePeriod)
+	}
+	for _, blocked := range opts.BlockedSerials {
+		if blocked.Issuer == "" || blocked.SerialNumber == nil {
+			return nil, errors.New("blocked serial must have an issuer and a serial number")
+		}
+	}
+	switch opts.UnknownHeaderPolicy {
+	case "", UnknownHeaderIgnore, UnknownHeaderWarn, UnknownHeaderReject:
+	default:
+		return nil, fmt.Errorf("unknown header policy %q is not supported", opts.UnknownHeaderPolicy)
+	}
+	if opts.MaxSignatureAge < 0 {
+		return nil, fmt.Errorf("maximum signature age %v is invalid, it must not be negative", opts.MaxSignatureAge)
+	}
+	if opts.Sandboxed {
+		var err error
+		trustStore, err = preloadTrustStores(context.Background(), trustPolicy, trustStore)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var verificationOutcomeCache *outcomeCache
+	if opts.OutcomeCacheTTL > 0 {
+		verificationOutcomeCache = newOutcomeCache(opts.OutcomeCacheSize, opts.OutcomeCacheTTL, opts.OutcomeCacheFailureTTL)
+	}
 	return &verifier{
-		trustPolicyDoc:   trustPolicy,
-		trustStore:       trustStore,
-		pluginManager:    pluginManager,
-		revocationClient: revocationClient,
+		trustPolicyDoc:              trustPolicy,
+		trustStore:                  trustStore,
+		pluginManager:               pluginManager,
+		revocationClient:            revocationClient,
+		revocationHTTPClient:        revocationHTTPClient,
+		requiredCertificatePolicies: requiredCertificatePolicies,
+		blockedSerials:              opts.BlockedSerials,
+		maxPayloadAnnotationCount:   opts.MaxPayloadAnnotationCount,
+		maxPayloadAnnotationSize:    opts.MaxPayloadAnnotationSize,
+		envelopeCache:               envelopeCache,
+		trustedIdentityResolver:     opts.TrustedIdentityResolver,
+		trustedIdentityCache:        identityCache,
+		certificateExpiryWindow:     opts.CertificateExpiryWarningWindow,
+		fipsOnly:                    opts.FIPSOnly,
+		checkTimestampAlgorithm:     opts.CheckTimestampAlgorithmStrength,
+		equivalentMediaTypes:        opts.EquivalentMediaTypes,
+		outcomeCache:                verificationOutcomeCache,
+		checkOrder:                  opts.CheckOrder,
+		clock:                       opts.Clock,
+		expiryGracePeriod:           opts.ExpiryGracePeriod,
+		maxSignatureAge:             opts.MaxSignatureAge,
+		maxChainDepth:               opts.MaxChainDepth,
+		sandboxed:                   opts.Sandboxed,
+		tolerateChainOrder:          opts.TolerateChainOrder,
+		unknownHeaderPolicy:         opts.UnknownHeaderPolicy,
 	}, nil
 }
 
@@ -108,9 +489,9 @@ func (v *verifier) SkipVerify(ctx context.Context, opts notation.VerifierVerifyO
 	logger := log.GetLogger(ctx)
 
 	logger.Debugf("Check verification level against artifact %v", opts.ArtifactReference)
-	trustPolicy, err := v.trustPolicyDoc.GetApplicableTrustPolicy(opts.ArtifactReference)
+	trustPolicy, err := v.getApplicableTrustPolicy(opts)
 	if err != nil {
-		return false, nil, notation.ErrorNoApplicableTrustPolicy{Msg: err.Error()}
+		return false, nil, err
 	}
 	logger.Infof("Trust policy configuration: %+v", trustPolicy)
 	// ignore the error since we already validated the policy document
@@ -124,36 +505,95 @@ func (v *verifier) SkipVerify(ctx context.Context, opts notation.VerifierVerifyO
 	return false, verificationLevel, nil
 }
 
+// RequiredSignerGroups returns the names of the signer groups required by the
+// trust policy statement that applies to the artifact.
+func (v *verifier) RequiredSignerGroups(ctx context.Context, opts notation.VerifierVerifyOptions) ([]string, error) {
+	trustPolicy, err := v.getApplicableTrustPolicy(opts)
+	if err != nil {
+		return nil, err
+	}
+	var groups []string
+	for _, group := range trustPolicy.RequiredSignerGroups {
+		groups = append(groups, group.Name)
+	}
+	return groups, nil
+}
+
 // Verify verifies the signature blob `signature` against the target OCI
 // artifact with manifest descriptor `desc`, and returns the outcome upon
 // successful verification.
 // If nil signature is present and the verification level is not 'skip',
 // an error will be returned.
 func (v *verifier) Verify(ctx context.Context, desc ocispec.Descriptor, signature []byte, opts notation.VerifierVerifyOptions) (*notation.VerificationOutcome, error) {
+	if v.outcomeCache == nil {
+		return v.verify(ctx, desc, signature, opts)
+	}
+
+	logger := log.GetLogger(ctx)
+	trustPolicy, err := v.getApplicableTrustPolicy(opts)
+	if err != nil {
+		return nil, err
+	}
+	cacheKey, err := outcomeCacheKey(desc, signature, trustPolicy, opts)
+	if err != nil {
+		return nil, notation.ErrorVerificationInconclusive{Msg: fmt.Sprintf("failed to compute the verification outcome cache key: %s", err)}
+	}
+	if entry, ok := v.outcomeCache.get(cacheKey); ok {
+		logger.Debugf("Using cached verification outcome of signature against artifact %v", desc.Digest)
+		return entry.outcome, entry.err
+	}
+	outcome, err := v.verify(ctx, desc, signature, opts)
+	if outcome != nil {
+		v.outcomeCache.add(cacheKey, outcome, err)
+	}
+	return outcome, err
+}
+
+// verify verifies the signature without consulting the outcome cache.
+func (v *verifier) verify(ctx context.Context, desc ocispec.Descriptor, signature []byte, opts notation.VerifierVerifyOptions) (*notation.VerificationOutcome, error) {
 	artifactRef := opts.ArtifactReference
 	envelopeMediaType := opts.SignatureMediaType
 	pluginConfig := opts.PluginConfig
 	logger := log.GetLogger(ctx)
 
 	logger.Debugf("Verify signature against artifact %v referenced as %s in signature media type %v", desc.Digest, artifactRef, envelopeMediaType)
-	trustPolicy, err := v.trustPolicyDoc.GetApplicableTrustPolicy(artifactRef)
+	trustPolicy, err := v.getApplicableTrustPolicy(opts)
 	if err != nil {
-		return nil, notation.ErrorNoApplicableTrustPolicy{Msg: err.Error()}
+		return nil, err
+	}
+	if opts.ForcePolicyStatement != "" {
+		logger.Warnf("Trust policy statement %q is forced for artifact %v, registry scopes are not evaluated", opts.ForcePolicyStatement, artifactRef)
 	}
 	logger.Infof("Trust policy configuration: %+v", trustPolicy)
 	// ignore the error since we already validated the policy document
 	verificationLevel, _ := trustPolicy.SignatureVerification.GetVerificationLevel()
 
 	outcome := &notation.VerificationOutcome{
-		RawSignature:      signature,
-		VerificationLevel: verificationLevel,
+		RawSignature:          signature,
+		VerificationLevel:     verificationLevel,
+		ForcedPolicyStatement: opts.ForcePolicyStatement,
+		PolicyStatement:       trustPolicy.Name,
 	}
 	// verificationLevel is skip
 	if reflect.DeepEqual(verificationLevel, trustpolicy.LevelSkip) {
 		logger.Debug("Skipping signature verification")
 		return outcome, nil
 	}
-	err = v.processSignature(ctx, signature, envelopeMediaType, trustPolicy, pluginConfig, outcome)
+	if len(trustPolicy.RequiredSignerGroups) > 0 {
+		outcome, err = v.processSignerGroups(ctx, signature, envelopeMediaType, opts.CertificateChain, trustPolicy, pluginConfig, outcome)
+	} else {
+		if v.trustedIdentityResolver != nil {
+			identities, err := v.resolveTrustedIdentities(ctx, trustPolicy, artifactRef)
+			if err != nil {
+				outcome.Error = err
+				return outcome, err
+			}
+			logger.Debugf("Resolved trusted identities %v for trust policy statement %q", identities, trustPolicy.Name)
+			// trustPolicy is a deep copy, so it is safe to override
+			trustPolicy.TrustedIdentities = identities
+		}
+		err = v.processSignature(ctx, signature, envelopeMediaType, opts.CertificateChain, trustPolicy, pluginConfig, outcome)
+	}
 
 	if err != nil {
 		outcome.Error = err
@@ -168,7 +608,7 @@ func (v *verifier) Verify(ctx context.Context, desc ocispec.Descriptor, signatur
 		return outcome, err
 	}
 
-	if !content.Equal(payload.TargetArtifact, desc) {
+	if !v.targetArtifactMatches(payload.TargetArtifact, desc) {
 		logger.Infof("payload.TargetArtifact in signature: %+v", payload.TargetArtifact)
 		logger.Infof("Target artifact that want to be verified: %+v", desc)
 		outcome.Error = errors.New("content descriptor mismatch")
@@ -184,14 +624,123 @@ func (v *verifier) Verify(ctx context.Context, desc ocispec.Descriptor, signatur
 	return outcome, outcome.Error
 }
 
-func (v *verifier) processSignature(ctx context.Context, sigBlob []byte, envelopeMediaType string, trustPolicy *trustpolicy.TrustPolicy, pluginConfig map[string]string, outcome *notation.VerificationOutcome) error {
+// processSignerGroups verifies the signature against the trust stores and
+// trusted identities of every required signer group of the trust policy
+// statement, and records the satisfied groups in the outcome. It fails if the
+// signature satisfies none of the groups.
+func (v *verifier) processSignerGroups(ctx context.Context, sigBlob []byte, envelopeMediaType string, certChain []*x509.Certificate, trustPolicy *trustpolicy.TrustPolicy, pluginConfig map[string]string, outcome *notation.VerificationOutcome) (*notation.VerificationOutcome, error) {
+	logger := log.GetLogger(ctx)
+
+	var satisfiedOutcome *notation.VerificationOutcome
+	var groupErrs []error
+	for _, group := range trustPolicy.RequiredSignerGroups {
+		logger.Debugf("Validating signature against signer group %q", group.Name)
+		// trustPolicy is a deep copy, so it is safe to override
+		trustPolicy.TrustStores = group.TrustStores
+		trustPolicy.TrustedIdentities = group.TrustedIdentities
+		groupOutcome := &notation.VerificationOutcome{
+			RawSignature:          outcome.RawSignature,
+			VerificationLevel:     outcome.VerificationLevel,
+			ForcedPolicyStatement: outcome.ForcedPolicyStatement,
+			PolicyStatement:       outcome.PolicyStatement,
+		}
+		if err := v.processSignature(ctx, sigBlob, envelopeMediaType, certChain, trustPolicy, pluginConfig, groupOutcome); err != nil {
+			var errInconclusive notation.ErrorVerificationInconclusive
+			if errors.As(err, &errInconclusive) {
+				groupOutcome.Error = err
+				return groupOutcome, err
+			}
+			logger.Debugf("Signature does not satisfy signer group %q, error: %v", group.Name, err)
+			groupErrs = append(groupErrs, fmt.Errorf("signer group %q: %w", group.Name, err))
+			if satisfiedOutcome == nil {
+				outcome = groupOutcome
+			}
+			continue
+		}
+		if satisfiedOutcome == nil {
+			satisfiedOutcome = groupOutcome
+		}
+		satisfiedOutcome.SignerGroups = append(satisfiedOutcome.SignerGroups, group.Name)
+	}
+	if satisfiedOutcome == nil {
+		return outcome, fmt.Errorf("signature does not satisfy any required signer group of trust policy statement %q: %w", trustPolicy.Name, errors.Join(groupErrs...))
+	}
+	return satisfiedOutcome, nil
+}
+
+// resolveTrustedIdentities resolves the trusted identities of the trust policy
+// statement for the artifact using the trusted identity resolver of the
+// verifier, reusing the cached ones if they have not expired.
+func (v *verifier) resolveTrustedIdentities(ctx context.Context, trustPolicy *trustpolicy.TrustPolicy, artifactRef string) ([]string, error) {
+	key := trustedIdentityCacheKey{statement: trustPolicy.Name, artifactReference: artifactRef}
+	if v.trustedIdentityCache != nil {
+		if identities, ok := v.trustedIdentityCache.get(key); ok {
+			return identities, nil
+		}
+	}
+	identities, err := v.trustedIdentityResolver.Resolve(ctx, trustPolicy, artifactRef)
+	if err != nil {
+		return nil, notation.ErrorVerificationInconclusive{Msg: fmt.Sprintf("failed to resolve trusted identities for trust policy statement %q, error: %s", trustPolicy.Name, err)}
+	}
+	if len(identities) == 0 {
+		return nil, notation.ErrorVerificationInconclusive{Msg: fmt.Sprintf("no trusted identities are resolved for trust policy statement %q", trustPolicy.Name)}
+	}
+	// resolved identities must follow the same rules as the static ones
+	resolved := *trustPolicy
+	resolved.TrustedIdentities = identities
+	if err := trustpolicy.ValidateTrustedIdentities(resolved); err != nil {
+		return nil, notation.ErrorVerificationInconclusive{Msg: fmt.Sprintf("invalid trusted identities are resolved for trust policy statement %q, error: %s", trustPolicy.Name, err)}
+	}
+	if v.trustedIdentityCache != nil {
+		v.trustedIdentityCache.add(key, identities)
+	}
+	return identities, nil
+}
+
+// getApplicableTrustPolicy returns the trust policy statement forced by
+// opts.ForcePolicyStatement if set, otherwise the statement that applies to
+// opts.ArtifactReference.
+func (v *verifier) getApplicableTrustPolicy(opts notation.VerifierVerifyOptions) (*trustpolicy.TrustPolicy, error) {
+	if opts.ForcePolicyStatement == "" {
+		trustPolicy, err := v.trustPolicyDoc.GetApplicableTrustPolicy(opts.ArtifactReference)
+		if err != nil {
+			return nil, notation.ErrorNoApplicableTrustPolicy{Msg: err.Error()}
+		}
+		return trustPolicy, nil
+	}
+
+	trustPolicy, err := v.trustPolicyDoc.GetTrustPolicy(opts.ForcePolicyStatement)
+	if err != nil {
+		return nil, notation.ErrorNoApplicableTrustPolicy{Msg: err.Error()}
+	}
+	// ignore the error since we already validated the policy document
+	verificationLevel, _ := trustPolicy.SignatureVerification.GetVerificationLevel()
+	if verificationLevel.Name == trustpolicy.LevelSkip.Name {
+		return nil, notation.ErrorNoApplicableTrustPolicy{Msg: fmt.Sprintf("trust policy statement %q skips signature verification and cannot be forced", opts.ForcePolicyStatement)}
+	}
+	return trustPolicy, nil
+}
+
+func (v *verifier) processSignature(ctx context.Context, sigBlob []byte, envelopeMediaType string, certChain []*x509.Certificate, trustPolicy *trustpolicy.TrustPolicy, pluginConfig map[string]string, outcome *notation.VerificationOutcome) error {
 	logger := log.GetLogger(ctx)
 
 	// verify integrity first. notation will always verify integrity no matter
 	// what the signing scheme is
-	envContent, integrityResult := verifyIntegrity(sigBlob, envelopeMediaType, outcome)
+	start := time.Now()
+	// reuse the envelope parsed by the caller, e.g. notation.Verify
+	parse := func(envelopeMediaType string, sigBlob []byte) (*envelope.Envelope, error) {
+		if sigEnv, ok := envelope.ParsedEnvelopeFromContext(ctx, envelopeMediaType, sigBlob); ok {
+			return sigEnv, nil
+		}
+		return v.parseEnvelope(envelopeMediaType, sigBlob)
+	}
+	envContent, integrityResult := verifyIntegrity(sigBlob, envelopeMediaType, certChain, v.tolerateChainOrder, parse, v.verifyPayloadAnnotations, outcome)
 	outcome.EnvelopeContent = envContent
 	outcome.VerificationResults = append(outcome.VerificationResults, integrityResult)
+	if integrityResult.Error == nil && v.fipsOnly {
+		integrityResult.Error = verifyFIPSAlgorithms(&envContent.SignerInfo)
+	}
+	recordDuration(outcome, trustpolicy.TypeIntegrity, start)
 	if integrityResult.Error != nil {
 		logVerificationResult(logger, integrityResult)
 		return integrityResult.Error
@@ -205,8 +754,21 @@ func (v *verifier) processSignature(ctx context.Context, sigBlob []byte, envelop
 		return err
 	}
 
+	// verify the protected headers not recognized by notation
+	start = time.Now()
+	err = v.verifyUnknownHeaders(logger, verificationPluginName != "", outcome)
+	recordDuration(outcome, trustpolicy.TypeIntegrity, start)
+	if err != nil {
+		integrityResult.Error = err
+		logVerificationResult(logger, integrityResult)
+		return err
+	}
+
 	var installedPlugin plugin.VerifyPlugin
 	if verificationPluginName != "" {
+		if v.sandboxed {
+			return SandboxViolationError{Msg: fmt.Sprintf("digital signature requires verification plugin %q, running plugins is not allowed in sandboxed mode", verificationPluginName)}
+		}
 		logger.Debugf("Finding verification plugin %s", verificationPluginName)
 		verificationPluginMinVersion, err := getVerificationPluginMinVersion(&outcome.EnvelopeContent.SignerInfo)
 		if err != nil && err != errExtendedAttributeNotExist {
@@ -252,18 +814,24 @@ func (v *verifier) processSignature(ctx context.Context, sigBlob []byte, envelop
 
 	// verify x509 trust store based authenticity
 	logger.Debug("Validating cert chain")
-	authenticityResult := verifyAuthenticity(ctx, trustPolicy, v.trustStore, outcome)
+	start = time.Now()
+	authenticityResult := verifyAuthenticity(ctx, trustPolicy, v.trustStore, v.maxChainDepth, outcome)
+	recordDuration(outcome, trustpolicy.TypeAuthenticity, start)
 	outcome.VerificationResults = append(outcome.VerificationResults, authenticityResult)
 	logVerificationResult(logger, authenticityResult)
 	if isCriticalFailure(authenticityResult) {
 		return authenticityResult.Error
 	}
+	if authenticityResult.Error == nil {
+		outcome.SignerIdentity = x509SignerIdentity(outcome.EnvelopeContent.SignerInfo.CertificateChain[0])
+	}
 
-	// verify x509 trusted identity based authenticity (only if notation needs
-	// to perform this verification rather than a plugin)
-	if !slices.Contains(pluginCapabilities, proto.CapabilityTrustedIdentityVerifier) {
-		logger.Debug("Validating trust identity")
-		err = verifyX509TrustedIdentities(outcome.EnvelopeContent.SignerInfo.CertificateChain, trustPolicy)
+	// verify the certificate policies asserted by the signing certificate
+	if len(v.requiredCertificatePolicies) > 0 {
+		logger.Debug("Validating certificate policies")
+		start = time.Now()
+		err := verifyCertificatePolicies(outcome.EnvelopeContent.SignerInfo.CertificateChain, v.requiredCertificatePolicies)
+		recordDuration(outcome, trustpolicy.TypeAuthenticity, start)
 		if err != nil {
 			authenticityResult.Error = err
 			logVerificationResult(logger, authenticityResult)
@@ -273,36 +841,127 @@ func (v *verifier) processSignature(ctx context.Context, sigBlob []byte, envelop
 		}
 	}
 
-	// verify expiry
-	logger.Debug("Validating expiry")
-	expiryResult := verifyExpiry(outcome)
-	outcome.VerificationResults = append(outcome.VerificationResults, expiryResult)
-	logVerificationResult(logger, expiryResult)
-	if isCriticalFailure(expiryResult) {
-		return expiryResult.Error
+	// verify that the certificate chain contains no blocked certificate,
+	// regardless of the verification level
+	if len(v.blockedSerials) > 0 {
+		logger.Debug("Validating blocked certificates")
+		start = time.Now()
+		err := verifyBlockedSerials(outcome.EnvelopeContent.SignerInfo.CertificateChain, v.blockedSerials)
+		recordDuration(outcome, trustpolicy.TypeAuthenticity, start)
+		if err != nil {
+			authenticityResult.Error = err
+			logVerificationResult(logger, authenticityResult)
+			return err
+		}
 	}
 
-	// verify authentic timestamp
-	logger.Debug("Validating authentic timestamp")
-	authenticTimestampResult := verifyAuthenticTimestamp(outcome)
-	outcome.VerificationResults = append(outcome.VerificationResults, authenticTimestampResult)
-	logVerificationResult(logger, authenticTimestampResult)
-	if isCriticalFailure(authenticTimestampResult) {
-		return authenticTimestampResult.Error
-	}
+	// all checks of the signature are evaluated at the same time
+	now := v.now()
+	for _, check := range v.getCheckOrder() {
+		switch check {
+		case CheckTrustedIdentity:
+			// verify x509 trusted identity based authenticity (only if
+			// notation needs to perform this verification rather than a
+			// plugin)
+			if !slices.Contains(pluginCapabilities, proto.CapabilityTrustedIdentityVerifier) {
+				logger.Debug("Validating trust identity")
+				start = time.Now()
+				err = verifyX509TrustedIdentities(outcome.EnvelopeContent.SignerInfo.CertificateChain, trustPolicy)
+				recordDuration(outcome, trustpolicy.TypeAuthenticity, start)
+				if err != nil {
+					authenticityResult.Error = err
+					logVerificationResult(logger, authenticityResult)
+				}
+				if isCriticalFailure(authenticityResult) {
+					return authenticityResult.Error
+				}
+			}
+		case CheckExpiry:
+			// verify expiry
+			logger.Debug("Validating expiry")
+			start = time.Now()
+			expiryResult := verifyExpiry(outcome, now)
+			if expiryResult.Error != nil && v.expiryGracePeriod > 0 {
+				if graceResult := verifyExpiry(outcome, now.Add(-v.expiryGracePeriod)); graceResult.Error == nil {
+					warning := fmt.Errorf("%s, but it is accepted within the expiry grace period of %v", expiryResult.Error, v.expiryGracePeriod)
+					logger.Warn(warning)
+					outcome.Warnings = append(outcome.Warnings, warning)
+					expiryResult = graceResult
+				}
+			}
+			recordDuration(outcome, trustpolicy.TypeExpiry, start)
+			outcome.VerificationResults = append(outcome.VerificationResults, expiryResult)
+			logVerificationResult(logger, expiryResult)
+			if isCriticalFailure(expiryResult) {
+				return expiryResult.Error
+			}
 
-	// verify revocation
-	// check if we need to bypass the revocation check, since revocation can be
-	// skipped using a trust policy or a plugin may override the check
-	if outcome.VerificationLevel.Enforcement[trustpolicy.TypeRevocation] != trustpolicy.ActionSkip &&
-		!slices.Contains(pluginCapabilities, proto.CapabilityRevocationCheckVerifier) {
+			// verify the signature age, regardless of the verification
+			// level
+			if v.maxSignatureAge > 0 {
+				if err := verifySignatureAge(&outcome.EnvelopeContent.SignerInfo, v.maxSignatureAge, now); err != nil {
+					expiryResult.Error = err
+					expiryResult.Action = trustpolicy.ActionEnforce
+					logVerificationResult(logger, expiryResult)
+					return err
+				}
+			}
 
-		logger.Debug("Validating revocation")
-		revocationResult := verifyRevocation(outcome, v.revocationClient, logger)
-		outcome.VerificationResults = append(outcome.VerificationResults, revocationResult)
-		logVerificationResult(logger, revocationResult)
-		if isCriticalFailure(revocationResult) {
-			return revocationResult.Error
+			// warn about signing certificate nearing its expiry
+			if v.certificateExpiryWindow > 0 {
+				if err := checkCertificateExpiryWindow(&outcome.EnvelopeContent.SignerInfo, v.certificateExpiryWindow, now); err != nil {
+					logger.Warn(err)
+					outcome.Warnings = append(outcome.Warnings, err)
+				}
+			}
+		case CheckAuthenticTimestamp:
+			// verify authentic timestamp
+			logger.Debug("Validating authentic timestamp")
+			start = time.Now()
+			authenticTimestampResult := verifyAuthenticTimestamp(ctx, trustPolicy, v.trustStore, outcome, now)
+			if authenticTimestampResult.Error == nil && v.checkTimestampAlgorithm {
+				authenticTimestampResult.Error = verifyTimestampAlgorithmStrength(&outcome.EnvelopeContent.SignerInfo)
+			}
+			recordDuration(outcome, trustpolicy.TypeAuthenticTimestamp, start)
+			outcome.VerificationResults = append(outcome.VerificationResults, authenticTimestampResult)
+			logVerificationResult(logger, authenticTimestampResult)
+			if isCriticalFailure(authenticTimestampResult) {
+				return authenticTimestampResult.Error
+			}
+		case CheckRevocation:
+			// verify revocation
+			// check if we need to bypass the revocation check, since
+			// revocation can be skipped using a trust policy or a plugin may
+			// override the check
+			if outcome.VerificationLevel.Enforcement[trustpolicy.TypeRevocation] != trustpolicy.ActionSkip &&
+				!slices.Contains(pluginCapabilities, proto.CapabilityRevocationCheckVerifier) {
+
+				logger.Debug("Validating revocation")
+				start = time.Now()
+				var revocationResult *notation.ValidationResult
+				if v.sandboxed && v.revocationClient == nil {
+					revocationResult = &notation.ValidationResult{
+						Type:   trustpolicy.TypeRevocation,
+						Action: outcome.VerificationLevel.Enforcement[trustpolicy.TypeRevocation],
+						Error:  SandboxViolationError{Msg: "unable to check revocation status, online revocation checks are not allowed in sandboxed mode"},
+					}
+				} else {
+					if v.clock != nil {
+						// the revocation status is not evaluated at the
+						// time of the clock
+						err := fmt.Errorf("revocation status is checked at the system time, not at the evaluation time %q of the configured clock", now.Format(time.RFC1123Z))
+						logger.Warn(err)
+						outcome.Warnings = append(outcome.Warnings, err)
+					}
+					revocationResult = verifyRevocation(outcome, v.getRevocationClient(ctx), logger)
+				}
+				recordDuration(outcome, trustpolicy.TypeRevocation, start)
+				outcome.VerificationResults = append(outcome.VerificationResults, revocationResult)
+				logVerificationResult(logger, revocationResult)
+				if isCriticalFailure(revocationResult) {
+					return revocationResult.Error
+				}
+			}
 		}
 	}
 
@@ -332,6 +991,63 @@ func (v *verifier) processSignature(ctx context.Context, sigBlob []byte, envelop
 	return nil
 }
 
+// recordDuration adds the time elapsed since start to the duration of the
+// validation type in the verification outcome.
+func recordDuration(outcome *notation.VerificationOutcome, validationType trustpolicy.ValidationType, start time.Time) {
+	if outcome.Durations == nil {
+		outcome.Durations = make(map[trustpolicy.ValidationType]time.Duration)
+	}
+	outcome.Durations[validationType] += time.Since(start)
+}
+
+// now returns the evaluation time of the verification.
+func (v *verifier) now() time.Time {
+	if v.clock == nil {
+		return time.Now()
+	}
+	return v.clock.Now()
+}
+
+// getCheckOrder returns the order of the configurable checks.
+func (v *verifier) getCheckOrder() []Check {
+	if len(v.checkOrder) == 0 {
+		return defaultCheckOrder
+	}
+	return v.checkOrder
+}
+
+// targetArtifactMatches reports whether the target artifact of the signature
+// payload matches the artifact being verified. The media types may differ if
+// they are configured as equivalent.
+func (v *verifier) targetArtifactMatches(targetArtifact, desc ocispec.Descriptor) bool {
+	if content.Equal(targetArtifact, desc) {
+		return true
+	}
+	if targetArtifact.Digest != desc.Digest || targetArtifact.Size != desc.Size {
+		return false
+	}
+	return slices.Contains(v.equivalentMediaTypes[targetArtifact.MediaType], desc.MediaType) ||
+		slices.Contains(v.equivalentMediaTypes[desc.MediaType], targetArtifact.MediaType)
+}
+
+// getRevocationClient returns a revocation client recording its network
+// requests in the network trace carried by ctx. If there is no network trace
+// or the revocation client is provided by the caller, the revocation client
+// of the verifier is returned.
+func (v *verifier) getRevocationClient(ctx context.Context) revocation.Revocation {
+	trace := notation.NetworkTraceFromContext(ctx)
+	if trace == nil || v.revocationHTTPClient == nil {
+		return v.revocationClient
+	}
+	tracedClient := *v.revocationHTTPClient
+	tracedClient.Transport = &notation.TraceTransport{Base: tracedClient.Transport, Trace: trace}
+	revocationClient, err := revocation.New(&tracedClient)
+	if err != nil {
+		return v.revocationClient
+	}
+	return revocationClient
+}
+
 func processPluginResponse(logger log.Logger, capabilitiesToVerify []proto.Capability, response *proto.VerifySignatureResponse, outcome *notation.VerificationOutcome) error {
 	verificationPluginName, err := getVerificationPlugin(&outcome.EnvelopeContent.SignerInfo)
 	if err != nil {
@@ -394,9 +1110,33 @@ func processPluginResponse(logger log.Logger, capabilitiesToVerify []proto.Capab
 	return nil
 }
 
-func verifyIntegrity(sigBlob []byte, envelopeMediaType string, outcome *notation.VerificationOutcome) (*signature.EnvelopeContent, *notation.ValidationResult) {
+// parseEnvelope parses the signature envelope blob. If the envelope cache is
+// enabled, the previously parsed envelope of the same blob is returned.
+func (v *verifier) parseEnvelope(envelopeMediaType string, sigBlob []byte) (*envelope.Envelope, error) {
+	parse := v.envelopeParser
+	if parse == nil {
+		parse = envelope.Parse
+	}
+	if v.envelopeCache == nil {
+		return parse(envelopeMediaType, sigBlob)
+	}
+
+	// parsing is pure, so it is safe to reuse the parsed envelope
+	key := envelopeCacheKey{mediaType: envelopeMediaType, digest: digest.FromBytes(sigBlob)}
+	if sigEnv, ok := v.envelopeCache.Get(key); ok {
+		return sigEnv, nil
+	}
+	sigEnv, err := parse(envelopeMediaType, sigBlob)
+	if err != nil {
+		return nil, err
+	}
+	v.envelopeCache.Add(key, sigEnv)
+	return sigEnv, nil
+}
+
+func verifyIntegrity(sigBlob []byte, envelopeMediaType string, certChain []*x509.Certificate, tolerateChainOrder bool, parse envelopeParser, verifyAnnotations func(*envelope.Payload) error, outcome *notation.VerificationOutcome) (*signature.EnvelopeContent, *notation.ValidationResult) {
 	// parse the signature
-	sigEnv, err := signature.ParseEnvelope(envelopeMediaType, sigBlob)
+	sigEnv, err := parse(envelopeMediaType, sigBlob)
 	if err != nil {
 		return nil, &notation.ValidationResult{
 			Error:  fmt.Errorf("unable to parse the digital signature, error : %s", err),
@@ -405,6 +1145,40 @@ func verifyIntegrity(sigBlob []byte, envelopeMediaType string, outcome *notation
 		}
 	}
 
+	// verify the order of the embedded certificate chain
+	if sigEnv.CertificateChain != nil {
+		if err := verifyCertificateChainOrder(sigEnv.CertificateChain); err != nil {
+			if tolerateChainOrder {
+				var orderedChain []*x509.Certificate
+				orderedChain, err = orderCertificateChain(sigEnv.CertificateChain)
+				if err == nil {
+					// the envelope with the reordered certificate chain has
+					// to be parsed again
+					if sigBlob, err = envelope.ReplaceCertificateChain(envelopeMediaType, sigBlob, orderedChain); err == nil {
+						sigEnv, err = parse(envelopeMediaType, sigBlob)
+					}
+				}
+			}
+			if err != nil {
+				return nil, &notation.ValidationResult{
+					Error:  err,
+					Type:   trustpolicy.TypeIntegrity,
+					Action: outcome.VerificationLevel.Enforcement[trustpolicy.TypeIntegrity],
+				}
+			}
+		}
+
+		// reject a CA certificate used as the signing certificate before the
+		// certificate chain is validated
+		if err := verifyLeafIsNotCA(sigEnv.CertificateChain[0]); err != nil {
+			return nil, &notation.ValidationResult{
+				Error:  err,
+				Type:   trustpolicy.TypeIntegrity,
+				Action: outcome.VerificationLevel.Enforcement[trustpolicy.TypeIntegrity],
+			}
+		}
+	}
+
 	// verify integrity
 	envContent, err := sigEnv.Verify()
 	if err != nil {
@@ -425,6 +1199,18 @@ func verifyIntegrity(sigBlob []byte, envelopeMediaType string, outcome *notation
 		}
 	}
 
+	// use the certificate chain supplied by the caller
+	if len(certChain) > 0 {
+		if err := verifySuppliedCertificateChain(envContent.SignerInfo.CertificateChain, certChain); err != nil {
+			return nil, &notation.ValidationResult{
+				Error:  err,
+				Type:   trustpolicy.TypeIntegrity,
+				Action: outcome.VerificationLevel.Enforcement[trustpolicy.TypeIntegrity],
+			}
+		}
+		envContent.SignerInfo.CertificateChain = certChain
+	}
+
 	if err := envelope.ValidatePayloadContentType(&envContent.Payload); err != nil {
 		return nil, &notation.ValidationResult{
 			Error:  err,
@@ -433,6 +1219,24 @@ func verifyIntegrity(sigBlob []byte, envelopeMediaType string, outcome *notation
 		}
 	}
 
+	// enforce the annotation limits as soon as the payload is parsed, before
+	// the payload is exposed to callers
+	var payload envelope.Payload
+	if err := json.Unmarshal(envContent.Payload.Content, &payload); err != nil {
+		return nil, &notation.ValidationResult{
+			Error:  fmt.Errorf("signature envelope payload can't be unmarshalled: %w", err),
+			Type:   trustpolicy.TypeIntegrity,
+			Action: outcome.VerificationLevel.Enforcement[trustpolicy.TypeIntegrity],
+		}
+	}
+	if err := verifyAnnotations(&payload); err != nil {
+		return nil, &notation.ValidationResult{
+			Error:  err,
+			Type:   trustpolicy.TypeIntegrity,
+			Action: outcome.VerificationLevel.Enforcement[trustpolicy.TypeIntegrity],
+		}
+	}
+
 	// integrity has been verified successfully
 	return envContent, &notation.ValidationResult{
 		Type:   trustpolicy.TypeIntegrity,
@@ -440,7 +1244,7 @@ func verifyIntegrity(sigBlob []byte, envelopeMediaType string, outcome *notation
 	}
 }
 
-func verifyAuthenticity(ctx context.Context, trustPolicy *trustpolicy.TrustPolicy, x509TrustStore truststore.X509TrustStore, outcome *notation.VerificationOutcome) *notation.ValidationResult {
+func verifyAuthenticity(ctx context.Context, trustPolicy *trustpolicy.TrustPolicy, x509TrustStore truststore.X509TrustStore, maxChainDepth int, outcome *notation.VerificationOutcome) *notation.ValidationResult {
 	// verify authenticity
 	trustCerts, err := loadX509TrustStores(ctx, outcome.EnvelopeContent.SignerInfo.SignedAttributes.SigningScheme, trustPolicy, x509TrustStore)
 
@@ -459,7 +1263,7 @@ func verifyAuthenticity(ctx context.Context, trustPolicy *trustpolicy.TrustPolic
 			Action: outcome.VerificationLevel.Enforcement[trustpolicy.TypeAuthenticity],
 		}
 	}
-	_, err = signature.VerifyAuthenticity(&outcome.EnvelopeContent.SignerInfo, trustCerts)
+	trustedCert, err := signature.VerifyAuthenticity(&outcome.EnvelopeContent.SignerInfo, trustCerts)
 	if err != nil {
 		switch err.(type) {
 		case *signature.SignatureAuthenticityError:
@@ -477,12 +1281,86 @@ func verifyAuthenticity(ctx context.Context, trustPolicy *trustpolicy.TrustPolic
 		}
 	}
 
+	if err := verifyChainDepth(outcome.EnvelopeContent.SignerInfo.CertificateChain, trustedCert, trustCerts, maxChainDepth); err != nil {
+		return &notation.ValidationResult{
+			Error:  err,
+			Type:   trustpolicy.TypeAuthenticity,
+			Action: outcome.VerificationLevel.Enforcement[trustpolicy.TypeAuthenticity],
+		}
+	}
+
 	return &notation.ValidationResult{
 		Type:   trustpolicy.TypeAuthenticity,
 		Action: outcome.VerificationLevel.Enforcement[trustpolicy.TypeAuthenticity],
 	}
 }
 
+// verifyChainDepth verifies that the certificate path validated against the
+// trust store does not exceed maxDepth. The path runs from the signing
+// certificate to the trusted certificate and, if the trusted certificate is
+// not a root certificate, on through the trust store certificates issuing it.
+// The path is never considered shorter than the certificate chain.
+func verifyChainDepth(certChain []*x509.Certificate, trustedCert *x509.Certificate, trustCerts []*x509.Certificate, maxDepth int) error {
+	if maxDepth <= 0 {
+		maxDepth = defaultMaxChainDepth
+	}
+	depth := 0
+	for _, cert := range certChain {
+		depth++
+		if cert.Equal(trustedCert) {
+			break
+		}
+	}
+	// stop building once the path is too deep, which also breaks issuer
+	// cycles in the trust store
+	for cert := trustedCert; depth <= maxDepth && !isIssuedBy(cert, cert); depth++ {
+		var issuer *x509.Certificate
+		for _, trustCert := range trustCerts {
+			if !trustCert.Equal(cert) && isIssuedBy(cert, trustCert) {
+				issuer = trustCert
+				break
+			}
+		}
+		if issuer == nil {
+			break
+		}
+		cert = issuer
+	}
+	if len(certChain) > depth {
+		depth = len(certChain)
+	}
+	if depth > maxDepth {
+		return ChainDepthError{Msg: fmt.Sprintf("certificate chain has %d certificates, which exceeds the maximum chain depth of %d", depth, maxDepth)}
+	}
+	return nil
+}
+
+// verifyPayloadAnnotations verifies that the annotations of the signature
+// payload do not exceed the annotation count and size limits of the verifier.
+func (v *verifier) verifyPayloadAnnotations(payload *envelope.Payload) error {
+	maxCount := v.maxPayloadAnnotationCount
+	if maxCount <= 0 {
+		maxCount = defaultMaxPayloadAnnotationCount
+	}
+	maxSize := v.maxPayloadAnnotationSize
+	if maxSize <= 0 {
+		maxSize = defaultMaxPayloadAnnotationSize
+	}
+
+	annotations := payload.TargetArtifact.Annotations
+	if len(annotations) > maxCount {
+		return notation.ErrorVerificationFailed{Msg: fmt.Sprintf("signature payload has %d annotations, which exceeds the limit of %d annotations", len(annotations), maxCount)}
+	}
+	size := 0
+	for k, v := range annotations {
+		size += len(k) + len(v)
+	}
+	if size > maxSize {
+		return notation.ErrorVerificationFailed{Msg: fmt.Sprintf("signature payload annotations have a total size of %d bytes, which exceeds the limit of %d bytes", size, maxSize)}
+	}
+	return nil
+}
+
 func verifyUserMetadata(logger log.Logger, payload *envelope.Payload, userMetadata map[string]string) error {
 	logger.Debugf("Verifying that metadata %v is present in signature", userMetadata)
 	logger.Debugf("Signature metadata: %v", payload.TargetArtifact.Annotations)
@@ -497,8 +1375,8 @@ func verifyUserMetadata(logger log.Logger, payload *envelope.Payload, userMetada
 	return nil
 }
 
-func verifyExpiry(outcome *notation.VerificationOutcome) *notation.ValidationResult {
-	if expiry := outcome.EnvelopeContent.SignerInfo.SignedAttributes.Expiry; !expiry.IsZero() && !time.Now().Before(expiry) {
+func verifyExpiry(outcome *notation.VerificationOutcome, now time.Time) *notation.ValidationResult {
+	if expiry := outcome.EnvelopeContent.SignerInfo.SignedAttributes.Expiry; !expiry.IsZero() && !now.Before(expiry) {
 		return &notation.ValidationResult{
 			Error:  fmt.Errorf("digital signature has expired on %q", expiry.Format(time.RFC1123Z)),
 			Type:   trustpolicy.TypeExpiry,
@@ -512,17 +1390,114 @@ func verifyExpiry(outcome *notation.VerificationOutcome) *notation.ValidationRes
 	}
 }
 
-func verifyAuthenticTimestamp(outcome *notation.VerificationOutcome) *notation.ValidationResult {
+// verifyUnknownHeaders applies the unknown header policy to the extended
+// attributes of the signature, i.e. the protected headers not recognized by
+// notation. Critical extended attributes are rejected unless a verification
+// plugin processes them.
+func (v *verifier) verifyUnknownHeaders(logger log.Logger, hasPlugin bool, outcome *notation.VerificationOutcome) error {
+	for _, attr := range outcome.EnvelopeContent.SignerInfo.SignedAttributes.ExtendedAttributes {
+		if key, ok := attr.Key.(string); ok && slices.Contains(VerificationPluginHeaders, key) {
+			continue
+		}
+		if attr.Critical {
+			if !hasPlugin {
+				return &signature.InvalidSignatureError{Msg: fmt.Sprintf("protected header %v is marked critical but not recognized", attr.Key)}
+			}
+			continue
+		}
+		switch v.unknownHeaderPolicy {
+		case UnknownHeaderWarn:
+			warning := fmt.Errorf("protected header %v is not recognized", attr.Key)
+			logger.Warn(warning)
+			outcome.Warnings = append(outcome.Warnings, warning)
+		case UnknownHeaderReject:
+			return &signature.InvalidSignatureError{Msg: fmt.Sprintf("protected header %v is not recognized, unrecognized protected headers are not allowed", attr.Key)}
+		}
+	}
+	return nil
+}
+
+// verifySignatureAge returns an error if the signature was signed more than
+// maxAge before now. The age is measured from the authentic signing time if
+// the signing scheme provides one, i.e. for notary.x509.signingAuthority.
+// Otherwise, it is measured from the signing time claimed by the signer, as
+// the timestamp signature of the notary.x509 signing scheme is not verified.
+func verifySignatureAge(signerInfo *signature.SignerInfo, maxAge time.Duration, now time.Time) error {
+	signingTime, err := signerInfo.AuthenticSigningTime()
+	if err != nil {
+		signingTime = signerInfo.SignedAttributes.SigningTime
+	}
+	if signingTime.IsZero() {
+		return errors.New("digital signature does not have a signing time, its age cannot be verified")
+	}
+	if age := now.Sub(signingTime); age > maxAge {
+		return fmt.Errorf("digital signature was signed on %q, which is more than the maximum signature age of %v ago", signingTime.Format(time.RFC1123Z), maxAge)
+	}
+	return nil
+}
+
+// checkCertificateExpiryWindow returns an error if the signing certificate
+// of the notary.x509 signing scheme expires within the window from now.
+func checkCertificateExpiryWindow(signerInfo *signature.SignerInfo, window time.Duration, now time.Time) error {
+	if signerInfo.SignedAttributes.SigningScheme != signature.SigningSchemeX509 || len(signerInfo.CertificateChain) == 0 {
+		return nil
+	}
+	leafCert := signerInfo.CertificateChain[0]
+	if leafCert.NotAfter.Before(now.Add(window)) {
+		return fmt.Errorf("signing certificate %q expires at %q, which is within %v of the verification time", leafCert.Subject, leafCert.NotAfter.Format(time.RFC1123Z), window)
+	}
+	return nil
+}
+
+func verifyAuthenticTimestamp(ctx context.Context, trustPolicy *trustpolicy.TrustPolicy, x509TrustStore truststore.X509TrustStore, outcome *notation.VerificationOutcome, now time.Time) *notation.ValidationResult {
 	invalidTimestamp := false
 	var err error
 
 	if signerInfo := outcome.EnvelopeContent.SignerInfo; signerInfo.SignedAttributes.SigningScheme == signature.SigningSchemeX509 {
-		// TODO verify RFC3161 TSA signature if present (not in RC1)
-		// https://github.com/notaryproject/notation-go/issues/78
-		if len(signerInfo.UnsignedAttributes.TimestampSignature) == 0 {
+		// the RFC 3161 timestamp countersignature is verified against the
+		// "tsa" trust stores, it is ignored if there is none
+		var tsaCerts []*x509.Certificate
+		if len(signerInfo.UnsignedAttributes.TimestampSignature) > 0 {
+			tsaCerts, err = loadX509TrustStoresOfType(ctx, truststore.TypeTSA, trustPolicy, x509TrustStore)
+			if err != nil {
+				return &notation.ValidationResult{
+					Error:  err,
+					Type:   trustpolicy.TypeAuthenticTimestamp,
+					Action: outcome.VerificationLevel.Enforcement[trustpolicy.TypeAuthenticTimestamp],
+				}
+			}
+		}
+		if len(tsaCerts) > 0 {
+			// verify the RFC 3161 timestamp against the TSA trust stores,
+			// every certificate should be valid at the time of timestamping
+			var timestamp time.Time
+			timestamp, err = verifyTimestampToken(signerInfo.UnsignedAttributes.TimestampSignature, signerInfo.Signature, tsaCerts)
+			if err != nil {
+				invalidTimestamp = true
+				err = fmt.Errorf("failed to verify the timestamp countersignature: %w", err)
+			} else {
+				for _, cert := range signerInfo.CertificateChain {
+					if timestamp.Before(cert.NotBefore) || timestamp.After(cert.NotAfter) {
+						invalidTimestamp = true
+						err = fmt.Errorf("certificate %q was not valid when the digital signature was timestamped at %q", cert.Subject, timestamp.Format(time.RFC1123Z))
+						break
+					}
+				}
+				if !invalidTimestamp {
+					// the verified timestamp extended the validity of the
+					// certificate chain if any certificate is not valid at
+					// the time of verification
+					for _, cert := range signerInfo.CertificateChain {
+						if now.Before(cert.NotBefore) || now.After(cert.NotAfter) {
+							outcome.TimestampExtendedValidity = true
+							break
+						}
+					}
+				}
+			}
+		} else if len(signerInfo.UnsignedAttributes.TimestampSignature) == 0 {
 			// if there is no TSA signature, then every certificate should be
 			// valid at the time of verification
-			now := time.Now()
 			for _, cert := range signerInfo.CertificateChain {
 				if now.Before(cert.NotBefore) {
 					invalidTimestamp = true
@@ -547,6 +1522,17 @@ func verifyAuthenticTimestamp(outcome *notation.VerificationOutcome) *notation.V
 				break
 			}
 		}
+		if !invalidTimestamp {
+			// the validated authentic signing time extended the validity of
+			// the certificate chain if any certificate is not valid at the
+			// time of verification
+			for _, cert := range signerInfo.CertificateChain {
+				if now.Before(cert.NotBefore) || now.After(cert.NotAfter) {
+					outcome.TimestampExtendedValidity = true
+					break
+				}
+			}
+		}
 	}
 
 	if invalidTimestamp {
@@ -686,50 +1672,64 @@ func executePlugin(ctx context.Context, installedPlugin plugin.VerifyPlugin, tru
 	return installedPlugin.VerifySignature(ctx, req)
 }
 
+// x509SignerIdentity returns the trusted identity in the x509.subject format
+// that pins the signing certificate. It returns an empty string if the subject
+// of the signing certificate is not a valid x509.subject identity value, e.g.
+// if it lacks the mandatory C, ST or O attributes, since such an identity
+// would be rejected in a trust policy.
+func x509SignerIdentity(leafCert *x509.Certificate) string {
+	subject := leafCert.Subject.String()
+	if _, err := pkix.ParseDistinguishedName(subject); err != nil {
+		return ""
+	}
+	return trustpolicyInternal.X509Subject + ":" + subject
+}
+
 func verifyX509TrustedIdentities(certs []*x509.Certificate, trustPolicy *trustpolicy.TrustPolicy) error {
-	if slices.Contains(trustPolicy.TrustedIdentities, trustpolicyInternal.Wildcard) {
-		return nil
+	// trusted identities only supported on the leaf cert
+	var leafCert *x509.Certificate
+	if len(certs) > 0 {
+		leafCert = certs[0]
 	}
+	return trustpolicy.VerifyTrustedIdentity(leafCert, *trustPolicy)
+}
 
-	var trustedX509Identities []map[string]string
-	for _, identity := range trustPolicy.TrustedIdentities {
-		identityPrefix, identityValue, found := strings.Cut(identity, ":")
-		if !found {
-			return fmt.Errorf("trust policy statement %q has trusted identity %q missing separator", trustPolicy.Name, identity)
+func verifyCertificatePolicies(certs []*x509.Certificate, requiredPolicies []asn1.ObjectIdentifier) error {
+	if len(certs) == 0 {
+		return CertificatePolicyError{Msg: "certificate chain is empty, unable to verify certificate policies"}
+	}
+	leafCert := certs[0] // certificate policies are only verified on the leaf cert
+	for _, policy := range leafCert.PolicyIdentifiers {
+		if policy.Equal(oidAnyPolicy) {
+			return nil
 		}
-
-		// notation natively supports x509.subject identities only
-		if identityPrefix == trustpolicyInternal.X509Subject {
-			// identityValue cannot be empty
-			if identityValue == "" {
-				return fmt.Errorf("trust policy statement %q has trusted identity %q without an identity value", trustPolicy.Name, identity)
-			}
-			parsedSubject, err := pkix.ParseDistinguishedName(identityValue)
-			if err != nil {
-				return err
+	}
+	for _, required := range requiredPolicies {
+		found := false
+		for _, policy := range leafCert.PolicyIdentifiers {
+			if policy.Equal(required) {
+				found = true
+				break
 			}
-			trustedX509Identities = append(trustedX509Identities, parsedSubject)
+		}
+		if !found {
+			return CertificatePolicyError{Msg: fmt.Sprintf("signing certificate with subject %q does not assert the required certificate policy %q", leafCert.Subject, required)}
 		}
 	}
+	return nil
+}
 
-	if len(trustedX509Identities) == 0 {
-		return fmt.Errorf("no x509 trusted identities are configured in the trust policy %q", trustPolicy.Name)
-	}
-
-	leafCert := certs[0] // trusted identities only supported on the leaf cert
-
-	// parse the certificate subject following rfc 4514 DN syntax
-	leafCertDN, err := pkix.ParseDistinguishedName(leafCert.Subject.String())
-	if err != nil {
-		return fmt.Errorf("error while parsing the certificate subject from the digital signature. error : %q", err)
-	}
-	for _, trustedX509Identity := range trustedX509Identities {
-		if pkix.IsSubsetDN(trustedX509Identity, leafCertDN) {
-			return nil
+// verifyBlockedSerials returns a CertificateBlockedError if any certificate of
+// the chain is blocked.
+func verifyBlockedSerials(certs []*x509.Certificate, blockedSerials []BlockedSerial) error {
+	for _, cert := range certs {
+		for _, blocked := range blockedSerials {
+			if cert.SerialNumber.Cmp(blocked.SerialNumber) == 0 && cert.Issuer.String() == blocked.Issuer {
+				return CertificateBlockedError{Msg: fmt.Sprintf("certificate with subject %q and serial number %s issued by %q is blocked", cert.Subject, cert.SerialNumber, cert.Issuer)}
+			}
 		}
 	}
-
-	return fmt.Errorf("signing certificate from the digital signature does not match the X.509 trusted identities %q defined in the trust policy %q", trustedX509Identities, trustPolicy.Name)
+	return nil
 }
 
 func logVerificationResult(logger log.Logger, result *notation.ValidationResult) {
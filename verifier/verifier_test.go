@@ -15,17 +15,29 @@ package verifier
 
 import (
 	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
 	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"math/big"
 	"net/http"
+	"os"
 	"path/filepath"
 	"reflect"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/notaryproject/notation-core-go/revocation"
+	revocationresult "github.com/notaryproject/notation-core-go/revocation/result"
 	"github.com/notaryproject/notation-core-go/signature"
 	"github.com/notaryproject/notation-core-go/testhelper"
 	corex509 "github.com/notaryproject/notation-core-go/x509"
@@ -33,11 +45,13 @@ import (
 	"github.com/notaryproject/notation-go/dir"
 	"github.com/notaryproject/notation-go/internal/envelope"
 	"github.com/notaryproject/notation-go/internal/mock"
+	"github.com/notaryproject/notation-go/internal/slices"
 	"github.com/notaryproject/notation-go/log"
 	"github.com/notaryproject/notation-go/plugin/proto"
 	"github.com/notaryproject/notation-go/signer"
 	"github.com/notaryproject/notation-go/verifier/trustpolicy"
 	"github.com/notaryproject/notation-go/verifier/truststore"
+	"github.com/opencontainers/go-digest"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"golang.org/x/crypto/ocsp"
 
@@ -239,7 +253,7 @@ func assertNotationVerification(t *testing.T, scheme signature.SigningScheme) {
 	for _, level := range verificationLevels {
 		policyDocument := dummyPolicyDocument()
 		policyDocument.TrustPolicies[0].TrustedIdentities = []string{"x509.subject:CN=LOL,O=DummyOrg,L=Hyderabad,ST=TG,C=IN"} // configure policy to not trust "CN=Notation Test Leaf Cert,O=Notary,L=Seattle,ST=WA,C=US" which is the subject of the signature's signing certificate
-		expectedErr := fmt.Errorf("signing certificate from the digital signature does not match the X.509 trusted identities [map[\"C\":\"IN\" \"CN\":\"LOL\" \"L\":\"Hyderabad\" \"O\":\"DummyOrg\" \"ST\":\"TG\"]] defined in the trust policy \"test-statement-name\"")
+		expectedErr := fmt.Errorf("signing certificate from the digital signature does not match the X.509 trusted identities [\"CN=LOL,O=DummyOrg,L=Hyderabad,ST=TG,C=IN\"] defined in the trust policy \"test-statement-name\", the subject of the signing certificate is \"CN=Notation Test Root,O=Notary,L=Seattle,ST=WA,C=US\"")
 		testCases = append(testCases, testCase{
 			signatureBlob:     validSigEnv,
 			verificationType:  trustpolicy.TypeAuthenticity,
@@ -337,6 +351,80 @@ func assertNotationVerification(t *testing.T, scheme signature.SigningScheme) {
 	}
 }
 
+func TestForcePolicyStatement(t *testing.T) {
+	permissiveStatement := dummyPolicyStatement()
+	permissiveStatement.SignatureVerification = trustpolicy.SignatureVerification{VerificationLevel: trustpolicy.LevelPermissive.Name}
+	strictStatement := dummyPolicyStatement()
+	strictStatement.Name = "incident-statement"
+	strictStatement.RegistryScopes = []string{"registry.acme-rockets.io/software/incident"}
+	skipStatement := trustpolicy.TrustPolicy{
+		Name:                  "skip-statement",
+		RegistryScopes:        []string{"registry.acme-rockets.io/software/skip"},
+		SignatureVerification: trustpolicy.SignatureVerification{VerificationLevel: trustpolicy.LevelSkip.Name},
+	}
+	policyDocument := trustpolicy.Document{
+		Version:       "1.0",
+		TrustPolicies: []trustpolicy.TrustPolicy{permissiveStatement, strictStatement, skipStatement},
+	}
+	dir.UserConfigDir = "testdata"
+	revocationClient, err := revocation.New(&http.Client{Timeout: 2 * time.Second})
+	if err != nil {
+		t.Fatalf("unexpected error while creating revocation object: %v", err)
+	}
+	v := verifier{
+		trustPolicyDoc:   &policyDocument,
+		trustStore:       truststore.NewX509TrustStore(dir.ConfigFS()),
+		pluginManager:    mock.PluginManager{},
+		revocationClient: revocationClient,
+	}
+	opts := notation.VerifierVerifyOptions{ArtifactReference: mock.SampleArtifactUri, SignatureMediaType: "application/jose+json"}
+
+	t.Run("scope matched statement", func(t *testing.T) {
+		outcome, err := v.Verify(context.Background(), ocispec.Descriptor{}, mock.MockCaExpiredSigEnv, opts)
+		if outcome == nil || outcome.VerificationLevel.Name != trustpolicy.LevelPermissive.Name || outcome.ForcedPolicyStatement != "" {
+			t.Fatalf("expected the scope matched permissive statement to be used, got outcome: %+v", outcome)
+		}
+		if err != nil && err.Error() == "digital signature has expired on \"Fri, 29 Jul 2022 23:59:00 +0000\"" {
+			t.Fatalf("expected expiry to be logged only, got: %v", err)
+		}
+	})
+
+	t.Run("forced strict statement", func(t *testing.T) {
+		forcedOpts := opts
+		forcedOpts.ForcePolicyStatement = strictStatement.Name
+		outcome, err := v.Verify(context.Background(), ocispec.Descriptor{}, mock.MockCaExpiredSigEnv, forcedOpts)
+		if outcome == nil || outcome.VerificationLevel.Name != trustpolicy.LevelStrict.Name || outcome.ForcedPolicyStatement != strictStatement.Name {
+			t.Fatalf("expected the forced strict statement to be used, got outcome: %+v", outcome)
+		}
+		expectedErr := "digital signature has expired on \"Fri, 29 Jul 2022 23:59:00 +0000\""
+		if err == nil || err.Error() != expectedErr {
+			t.Fatalf("expected error %q, got: %v", expectedErr, err)
+		}
+		skip, level, err := v.SkipVerify(context.Background(), forcedOpts)
+		if err != nil || skip || level.Name != trustpolicy.LevelStrict.Name {
+			t.Fatalf("expected forced strict statement not to be skipped, got skip: %v, level: %v, error: %v", skip, level, err)
+		}
+	})
+
+	t.Run("forced skip statement", func(t *testing.T) {
+		forcedOpts := opts
+		forcedOpts.ForcePolicyStatement = skipStatement.Name
+		_, err := v.Verify(context.Background(), ocispec.Descriptor{}, mock.MockCaValidSigEnv, forcedOpts)
+		if !errors.As(err, &notation.ErrorNoApplicableTrustPolicy{}) {
+			t.Fatalf("expected ErrorNoApplicableTrustPolicy, got: %v", err)
+		}
+	})
+
+	t.Run("forced non-existing statement", func(t *testing.T) {
+		forcedOpts := opts
+		forcedOpts.ForcePolicyStatement = "non-existing-statement"
+		_, _, err := v.SkipVerify(context.Background(), forcedOpts)
+		if !errors.As(err, &notation.ErrorNoApplicableTrustPolicy{}) {
+			t.Fatalf("expected ErrorNoApplicableTrustPolicy, got: %v", err)
+		}
+	})
+}
+
 func TestVerifyRevocationEnvelope(t *testing.T) {
 	// Test values
 	desc := ocispec.Descriptor{
@@ -353,114 +441,1812 @@ func TestVerifyRevocationEnvelope(t *testing.T) {
 		TargetArtifact: desc,
 	}
 	opts := notation.VerifierVerifyOptions{ArtifactReference: mock.SampleArtifactUri, SignatureMediaType: "application/jose+json"}
-	pluginManager := mock.PluginManager{}
-	pluginManager.GetPluginError = errors.New("plugin should not be invoked when verification plugin is not specified in the signature")
-	pluginManager.PluginRunnerLoadError = errors.New("plugin should not be invoked when verification plugin is not specified in the signature")
+	pluginManager := mock.PluginManager{}
+	pluginManager.GetPluginError = errors.New("plugin should not be invoked when verification plugin is not specified in the signature")
+	pluginManager.PluginRunnerLoadError = errors.New("plugin should not be invoked when verification plugin is not specified in the signature")
+
+	// Get revokable certs and set up mock client (will always say certs are revoked)
+	revokableChain := testhelper.GetRevokableRSAChain(2)
+	httpClient := testhelper.MockClient(revokableChain, []ocsp.ResponseStatus{ocsp.Revoked}, nil, true)
+	revocationClient, err := revocation.New(httpClient)
+	if err != nil {
+		t.Fatalf("unexpected error while creating revocation object: %v", err)
+	}
+
+	// Generate blob with revokable certs
+	internalSigner, err := signer.New(revokableChain[0].PrivateKey, []*x509.Certificate{revokableChain[0].Cert, revokableChain[1].Cert})
+	if err != nil {
+		t.Fatalf("Unexpected error while creating signer: %v", err)
+	}
+	envelopeBlob, _, err := internalSigner.Sign(context.Background(), payload.TargetArtifact, notation.SignerSignOptions{ExpiryDuration: 24 * time.Hour, SignatureMediaType: "application/jose+json"})
+	if err != nil {
+		t.Fatalf("Unexpected error while generating blob: %v", err)
+	}
+
+	t.Run("enforced revoked cert", func(t *testing.T) {
+		testedLevel := trustpolicy.LevelStrict
+		policyDoc := dummyPolicyDocument()
+		policyDoc.TrustPolicies[0].SignatureVerification.VerificationLevel = testedLevel.Name
+		policyDoc.TrustPolicies[0].SignatureVerification.Override = map[trustpolicy.ValidationType]trustpolicy.ValidationAction{
+			trustpolicy.TypeAuthenticity: trustpolicy.ActionLog,
+			trustpolicy.TypeRevocation:   trustpolicy.ActionEnforce,
+		}
+		var expectedErr error = fmt.Errorf("signing certificate with subject %q is revoked", revokableChain[0].Cert.Subject.String())
+		expectedResult := notation.ValidationResult{
+			Type:   trustpolicy.TypeRevocation,
+			Action: trustpolicy.ActionEnforce,
+			Error:  expectedErr,
+		}
+
+		dir.UserConfigDir = "testdata"
+
+		verifier := verifier{
+			trustPolicyDoc:   &policyDoc,
+			trustStore:       truststore.NewX509TrustStore(dir.ConfigFS()),
+			pluginManager:    pluginManager,
+			revocationClient: revocationClient,
+		}
+		outcome, err := verifier.Verify(context.Background(), desc, envelopeBlob, opts)
+		if err == nil || err.Error() != expectedErr.Error() {
+			t.Fatalf("Expected verify to fail with %v, but got %v", expectedErr, err)
+		}
+		verifyResult(outcome, expectedResult, expectedErr, t)
+	})
+	t.Run("log revoked cert", func(t *testing.T) {
+		testedLevel := trustpolicy.LevelStrict
+		policyDoc := dummyPolicyDocument()
+		policyDoc.TrustPolicies[0].SignatureVerification.VerificationLevel = testedLevel.Name
+		policyDoc.TrustPolicies[0].SignatureVerification.Override = map[trustpolicy.ValidationType]trustpolicy.ValidationAction{
+			trustpolicy.TypeAuthenticity: trustpolicy.ActionLog,
+			trustpolicy.TypeRevocation:   trustpolicy.ActionLog,
+		}
+		var expectedErr error = fmt.Errorf("signing certificate with subject %q is revoked", revokableChain[0].Cert.Subject.String())
+		expectedResult := notation.ValidationResult{
+			Type:   trustpolicy.TypeRevocation,
+			Action: trustpolicy.ActionLog,
+			Error:  expectedErr,
+		}
+
+		dir.UserConfigDir = "testdata"
+
+		verifier := verifier{
+			trustPolicyDoc:   &policyDoc,
+			trustStore:       truststore.NewX509TrustStore(dir.ConfigFS()),
+			pluginManager:    pluginManager,
+			revocationClient: revocationClient,
+		}
+		ctx := context.Background()
+		outcome, err := verifier.Verify(ctx, desc, envelopeBlob, opts)
+		if err != nil {
+			t.Fatalf("Unexpected error while verifying: %v", err)
+		}
+		verifyResult(outcome, expectedResult, expectedErr, t)
+	})
+	t.Run("network trace records revocation request", func(t *testing.T) {
+		testedLevel := trustpolicy.LevelStrict
+		policyDoc := dummyPolicyDocument()
+		policyDoc.TrustPolicies[0].SignatureVerification.VerificationLevel = testedLevel.Name
+		policyDoc.TrustPolicies[0].SignatureVerification.Override = map[trustpolicy.ValidationType]trustpolicy.ValidationAction{
+			trustpolicy.TypeAuthenticity: trustpolicy.ActionLog,
+			trustpolicy.TypeRevocation:   trustpolicy.ActionLog,
+		}
+
+		dir.UserConfigDir = "testdata"
+
+		verifier := verifier{
+			trustPolicyDoc:       &policyDoc,
+			trustStore:           truststore.NewX509TrustStore(dir.ConfigFS()),
+			pluginManager:        pluginManager,
+			revocationClient:     revocationClient,
+			revocationHTTPClient: httpClient,
+		}
+		trace := &notation.NetworkTrace{}
+		ctx := notation.WithNetworkTrace(context.Background(), trace)
+		if _, err := verifier.Verify(ctx, desc, envelopeBlob, opts); err != nil {
+			t.Fatalf("Unexpected error while verifying: %v", err)
+		}
+		requests := trace.Requests()
+		if len(requests) == 0 {
+			t.Fatal("expected the revocation request to be recorded in the network trace")
+		}
+		ocspServer := revokableChain[0].Cert.OCSPServer[0]
+		for _, req := range requests {
+			if !strings.HasPrefix(req.URL, ocspServer) {
+				t.Errorf("expected request to OCSP server %q, but got %q", ocspServer, req.URL)
+			}
+			if req.Method != http.MethodGet && req.Method != http.MethodPost {
+				t.Errorf("unexpected request method %q", req.Method)
+			}
+			if req.StatusCode != http.StatusOK || req.Error != nil {
+				t.Errorf("expected successful request, but got status %d and error %v", req.StatusCode, req.Error)
+			}
+		}
+	})
+	t.Run("skip revoked cert", func(t *testing.T) {
+		testedLevel := trustpolicy.LevelStrict
+		policyDoc := dummyPolicyDocument()
+		policyDoc.TrustPolicies[0].SignatureVerification.VerificationLevel = testedLevel.Name
+		policyDoc.TrustPolicies[0].SignatureVerification.Override = map[trustpolicy.ValidationType]trustpolicy.ValidationAction{
+			trustpolicy.TypeAuthenticity: trustpolicy.ActionLog,
+			trustpolicy.TypeRevocation:   trustpolicy.ActionSkip,
+		}
+
+		dir.UserConfigDir = "testdata"
+
+		verifier := verifier{
+			trustPolicyDoc:   &policyDoc,
+			trustStore:       truststore.NewX509TrustStore(dir.ConfigFS()),
+			pluginManager:    pluginManager,
+			revocationClient: revocationClient,
+		}
+		outcome, err := verifier.Verify(context.Background(), desc, envelopeBlob, opts)
+		if err != nil {
+			t.Fatalf("Unexpected error while verifying: %v", err)
+		}
+		for _, result := range outcome.VerificationResults {
+			if result.Type == trustpolicy.TypeRevocation {
+				t.Fatal("expected no result for TypeRevocation after skip")
+			}
+		}
+	})
+}
+
+func TestVerifyWithSuppliedCertificateChain(t *testing.T) {
+	desc := ocispec.Descriptor{
+		MediaType: "application/vnd.docker.distribution.manifest.v2+json",
+		Digest:    "sha256:60043cf45eaebc4c0867fea485a039b598f52fd09fd5b07b0b2d2f88fad9d74e",
+		Size:      528,
+	}
+	rootCert := testhelper.GetRSARootCertificate()
+	leafCert := testhelper.GetRSALeafCertificate()
+	internalSigner, err := signer.New(leafCert.PrivateKey, []*x509.Certificate{leafCert.Cert, rootCert.Cert})
+	if err != nil {
+		t.Fatalf("Unexpected error while creating signer: %v", err)
+	}
+	envelopeBlob, _, err := internalSigner.Sign(context.Background(), desc, notation.SignerSignOptions{ExpiryDuration: 24 * time.Hour, SignatureMediaType: "application/jose+json"})
+	if err != nil {
+		t.Fatalf("Unexpected error while generating blob: %v", err)
+	}
+
+	policyDoc := dummyPolicyDocument()
+	policyDoc.TrustPolicies[0].SignatureVerification.Override = map[trustpolicy.ValidationType]trustpolicy.ValidationAction{
+		trustpolicy.TypeAuthenticity: trustpolicy.ActionLog,
+		trustpolicy.TypeRevocation:   trustpolicy.ActionSkip,
+	}
+	dir.UserConfigDir = "testdata"
+	v := verifier{
+		trustPolicyDoc: &policyDoc,
+		trustStore:     truststore.NewX509TrustStore(dir.ConfigFS()),
+		pluginManager:  mock.PluginManager{},
+	}
+
+	t.Run("supplied chain matches the signature", func(t *testing.T) {
+		suppliedLeaf := testhelper.GetRSACertTupleWithPK(leafCert.PrivateKey, "Supplied Leaf", &rootCert)
+		suppliedChain := []*x509.Certificate{suppliedLeaf.Cert, rootCert.Cert}
+		opts := notation.VerifierVerifyOptions{ArtifactReference: mock.SampleArtifactUri, SignatureMediaType: "application/jose+json", CertificateChain: suppliedChain}
+		outcome, err := v.Verify(context.Background(), desc, envelopeBlob, opts)
+		if err != nil {
+			t.Fatalf("Unexpected error while verifying: %v", err)
+		}
+		if !reflect.DeepEqual(outcome.EnvelopeContent.SignerInfo.CertificateChain, suppliedChain) {
+			t.Fatal("expected the supplied certificate chain to be used for verification")
+		}
+	})
+
+	t.Run("supplied chain does not match the signature", func(t *testing.T) {
+		key, err := rsa.GenerateKey(rand.Reader, 3072)
+		if err != nil {
+			t.Fatal(err)
+		}
+		suppliedLeaf := testhelper.GetRSACertTupleWithPK(key, "Supplied Leaf", &rootCert)
+		opts := notation.VerifierVerifyOptions{ArtifactReference: mock.SampleArtifactUri, SignatureMediaType: "application/jose+json", CertificateChain: []*x509.Certificate{suppliedLeaf.Cert, rootCert.Cert}}
+		outcome, err := v.Verify(context.Background(), desc, envelopeBlob, opts)
+		expectedErr := &signature.InvalidSignatureError{Msg: `the key of the supplied leaf certificate with subject "CN=Supplied Leaf,O=Notary,L=Seattle,ST=WA,C=US" does not match the signature`}
+		if err == nil || err.Error() != expectedErr.Error() {
+			t.Fatalf("Expected verify to fail with %v, but got %v", expectedErr, err)
+		}
+		if outcome.VerificationResults[0].Type != trustpolicy.TypeIntegrity {
+			t.Fatalf("expected integrity failure, but got %v", outcome.VerificationResults[0].Type)
+		}
+	})
+
+	t.Run("invalid supplied chain", func(t *testing.T) {
+		opts := notation.VerifierVerifyOptions{ArtifactReference: mock.SampleArtifactUri, SignatureMediaType: "application/jose+json", CertificateChain: []*x509.Certificate{rootCert.Cert, leafCert.Cert}}
+		if _, err := v.Verify(context.Background(), desc, envelopeBlob, opts); err == nil {
+			t.Fatal("expected verify to fail with an invalid supplied certificate chain")
+		}
+	})
+}
+
+func TestVerifyFIPSAlgorithms(t *testing.T) {
+	t.Run("approved envelope passes in FIPS mode", func(t *testing.T) {
+		policyDocument := dummyPolicyDocument()
+		dir.UserConfigDir = "testdata"
+		v, err := NewWithOptions(&policyDocument, truststore.NewX509TrustStore(dir.ConfigFS()), mock.PluginManager{}, VerifierOptions{FIPSOnly: true})
+		if err != nil {
+			t.Fatalf("unexpected error while creating verifier: %v", err)
+		}
+		opts := notation.VerifierVerifyOptions{ArtifactReference: mock.SampleArtifactUri, SignatureMediaType: "application/jose+json"}
+		if _, err := v.Verify(context.Background(), mock.ImageDescriptor, mock.MockCaValidSigEnv, opts); err != nil {
+			t.Fatalf("expected verification to succeed in FIPS mode, but got %v", err)
+		}
+	})
+
+	rsaCert := testhelper.GetRSALeafCertificate().Cert
+	_, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "Ed25519 Leaf"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	ed25519CertBytes, err := x509.CreateCertificate(rand.Reader, template, template, ed25519Key.Public(), ed25519Key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ed25519Cert, err := x509.ParseCertificate(ed25519CertBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sha1Cert := *rsaCert
+	sha1Cert.SignatureAlgorithm = x509.SHA1WithRSA
+
+	tests := []struct {
+		name       string
+		signerInfo signature.SignerInfo
+		wantErr    bool
+	}{
+		{
+			name:       "approved algorithms",
+			signerInfo: signature.SignerInfo{SignatureAlgorithm: signature.AlgorithmPS256, CertificateChain: []*x509.Certificate{rsaCert}},
+		},
+		{
+			name:       "non-approved signature algorithm",
+			signerInfo: signature.SignerInfo{SignatureAlgorithm: signature.Algorithm(0), CertificateChain: []*x509.Certificate{rsaCert}},
+			wantErr:    true,
+		},
+		{
+			name:       "SHA-1 certificate",
+			signerInfo: signature.SignerInfo{SignatureAlgorithm: signature.AlgorithmPS256, CertificateChain: []*x509.Certificate{&sha1Cert}},
+			wantErr:    true,
+		},
+		{
+			name:       "Ed25519 certificate",
+			signerInfo: signature.SignerInfo{SignatureAlgorithm: signature.AlgorithmPS256, CertificateChain: []*x509.Certificate{ed25519Cert}},
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifyFIPSAlgorithms(&tt.signerInfo)
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			var fipsErr NonFIPSAlgorithmError
+			if !errors.As(err, &fipsErr) {
+				t.Fatalf("expected NonFIPSAlgorithmError, but got %v", err)
+			}
+		})
+	}
+}
+
+func TestVerifyEquivalentMediaTypes(t *testing.T) {
+	policyDocument := dummyPolicyDocument()
+	policyDocument.TrustPolicies[0].SignatureVerification.Override = map[trustpolicy.ValidationType]trustpolicy.ValidationAction{
+		trustpolicy.TypeRevocation: trustpolicy.ActionSkip,
+	}
+	dir.UserConfigDir = "testdata"
+	v := verifier{
+		trustPolicyDoc: &policyDocument,
+		trustStore:     truststore.NewX509TrustStore(dir.ConfigFS()),
+		pluginManager:  mock.PluginManager{},
+		equivalentMediaTypes: map[string][]string{
+			ocispec.MediaTypeImageManifest: {"application/vnd.docker.distribution.manifest.v2+json"},
+		},
+	}
+	opts := notation.VerifierVerifyOptions{ArtifactReference: mock.SampleArtifactUri, SignatureMediaType: "application/jose+json"}
+
+	t.Run("equivalent media type", func(t *testing.T) {
+		desc := mock.ImageDescriptor
+		desc.MediaType = ocispec.MediaTypeImageManifest
+		if _, err := v.Verify(context.Background(), desc, mock.MockCaValidSigEnv, opts); err != nil {
+			t.Fatalf("expected verification to succeed with an equivalent media type, but got %v", err)
+		}
+	})
+
+	t.Run("non-equivalent media type", func(t *testing.T) {
+		desc := mock.ImageDescriptor
+		desc.MediaType = ocispec.MediaTypeImageIndex
+		_, err := v.Verify(context.Background(), desc, mock.MockCaValidSigEnv, opts)
+		if err == nil || err.Error() != "content descriptor mismatch" {
+			t.Fatalf("expected content descriptor mismatch, but got %v", err)
+		}
+	})
+
+	t.Run("equivalent media type with different digest", func(t *testing.T) {
+		desc := mock.ImageDescriptor
+		desc.MediaType = ocispec.MediaTypeImageManifest
+		desc.Digest = "sha256:c6b7ab1bb5ba1bbf42d0e9d3c1a5a0aa6c4e1fd4b8e5e6f8a4fb6fcd2d9c6e0a"
+		_, err := v.Verify(context.Background(), desc, mock.MockCaValidSigEnv, opts)
+		if err == nil || err.Error() != "content descriptor mismatch" {
+			t.Fatalf("expected content descriptor mismatch, but got %v", err)
+		}
+	})
+
+	t.Run("media types must match by default", func(t *testing.T) {
+		defaultVerifier := v
+		defaultVerifier.equivalentMediaTypes = nil
+		desc := mock.ImageDescriptor
+		desc.MediaType = ocispec.MediaTypeImageManifest
+		_, err := defaultVerifier.Verify(context.Background(), desc, mock.MockCaValidSigEnv, opts)
+		if err == nil || err.Error() != "content descriptor mismatch" {
+			t.Fatalf("expected content descriptor mismatch, but got %v", err)
+		}
+	})
+}
+
+// staticTrustStore is a truststore.X509TrustStore returning the same
+// certificates for every named store.
+type staticTrustStore []*x509.Certificate
+
+func (s staticTrustStore) GetCertificates(ctx context.Context, storeType truststore.Type, namedStore string) ([]*x509.Certificate, error) {
+	return s, nil
+}
+
+// createRotationCertificate creates a code signing certificate valid between
+// notBefore and notAfter. It creates a self-signed root certificate if
+// issuer is nil.
+func createRotationCertificate(t *testing.T, cn string, notBefore, notAfter time.Time, issuer *testhelper.RSACertTuple) testhelper.RSACertTuple {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 3072)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn, Organization: []string{"Notary"}, Province: []string{"WA"}, Country: []string{"US"}},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+	}
+	parent, parentKey := template, key
+	if issuer == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage = x509.KeyUsageCertSign
+		template.ExtKeyUsage = nil
+	} else {
+		parent, parentKey = issuer.Cert, issuer.PrivateKey
+	}
+	certBytes, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(certBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testhelper.RSACertTuple{Cert: cert, PrivateKey: key}
+}
+
+func TestVerifyAcrossKeyRotation(t *testing.T) {
+	now := time.Now()
+	rootCert := createRotationCertificate(t, "Rotation Root", now.AddDate(-3, 0, 0), now.AddDate(10, 0, 0), nil)
+	// the old signing certificate has expired after the key rotation
+	oldCert := createRotationCertificate(t, "Old Signer", now.AddDate(-2, 0, 0), now.AddDate(0, -6, 0), &rootCert)
+	newCert := createRotationCertificate(t, "New Signer", now.AddDate(0, -7, 0), now.AddDate(2, 0, 0), &rootCert)
+
+	desc := mock.ImageDescriptor
+	payloadBytes, err := json.Marshal(envelope.Payload{TargetArtifact: envelope.SanitizeTargetArtifact(desc)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sign := func(leaf testhelper.RSACertTuple, signingTime time.Time, signingScheme signature.SigningScheme) []byte {
+		t.Helper()
+		localSigner, err := signature.NewLocalSigner([]*x509.Certificate{leaf.Cert, rootCert.Cert}, leaf.PrivateKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sigEnv, err := signature.NewEnvelope("application/jose+json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig, err := sigEnv.Sign(&signature.SignRequest{
+			Payload: signature.Payload{
+				ContentType: envelope.MediaTypePayloadV1,
+				Content:     payloadBytes,
+			},
+			Signer:        localSigner,
+			SigningTime:   signingTime,
+			SigningScheme: signingScheme,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sig
+	}
+	// the old key signed while its certificate was valid, with an authentic
+	// signing time
+	oldSignature := sign(oldCert, now.AddDate(-1, 0, 0), signature.SigningSchemeX509SigningAuthority)
+	newSignature := sign(newCert, now.Add(-time.Hour), signature.SigningSchemeX509)
+
+	policyDoc := dummyPolicyDocument()
+	policyDoc.TrustPolicies[0].TrustStores = []string{"signingAuthority:rotation", "ca:rotation"}
+	policyDoc.TrustPolicies[0].TrustedIdentities = []string{
+		"x509.subject:CN=Old Signer,O=Notary,ST=WA,C=US",
+		"x509.subject:CN=New Signer,O=Notary,ST=WA,C=US",
+	}
+	policyDoc.TrustPolicies[0].SignatureVerification.Override = map[trustpolicy.ValidationType]trustpolicy.ValidationAction{
+		trustpolicy.TypeRevocation: trustpolicy.ActionSkip,
+	}
+	v := verifier{
+		trustPolicyDoc: &policyDoc,
+		trustStore:     staticTrustStore{rootCert.Cert},
+		pluginManager:  mock.PluginManager{},
+	}
+	opts := notation.VerifierVerifyOptions{ArtifactReference: mock.SampleArtifactUri, SignatureMediaType: "application/jose+json"}
+
+	t.Run("old key signature verifies with authentic signing time", func(t *testing.T) {
+		if _, err := v.Verify(context.Background(), desc, oldSignature, opts); err != nil {
+			t.Fatalf("expected signature of the old key to verify, but got %v", err)
+		}
+	})
+
+	t.Run("new key signature verifies", func(t *testing.T) {
+		if _, err := v.Verify(context.Background(), desc, newSignature, opts); err != nil {
+			t.Fatalf("expected signature of the new key to verify, but got %v", err)
+		}
+	})
+
+	t.Run("old key signature without authentic signing time fails", func(t *testing.T) {
+		// without a timestamp, the certificates must be valid at the time of
+		// verification
+		sig := sign(oldCert, now.AddDate(-1, 0, 0), signature.SigningSchemeX509)
+		_, err := v.Verify(context.Background(), desc, sig, opts)
+		if err == nil || !strings.Contains(err.Error(), "is not valid anymore") {
+			t.Fatalf("expected signature of the expired old key to fail, but got %v", err)
+		}
+	})
+
+	t.Run("old key signature fails once its identity is removed", func(t *testing.T) {
+		rotatedPolicyDoc := dummyPolicyDocument()
+		rotatedPolicyDoc.TrustPolicies[0] = policyDoc.TrustPolicies[0]
+		rotatedPolicyDoc.TrustPolicies[0].TrustedIdentities = []string{"x509.subject:CN=New Signer,O=Notary,ST=WA,C=US"}
+		rotatedVerifier := v
+		rotatedVerifier.trustPolicyDoc = &rotatedPolicyDoc
+		if _, err := rotatedVerifier.Verify(context.Background(), desc, oldSignature, opts); err == nil {
+			t.Fatal("expected signature of the old key to fail after its identity is removed")
+		}
+		if _, err := rotatedVerifier.Verify(context.Background(), desc, newSignature, opts); err != nil {
+			t.Fatalf("expected signature of the new key to verify, but got %v", err)
+		}
+	})
+}
+
+// namedTrustStore is a trust store keyed by "<TrustStoreType>:<TrustStoreName>".
+type namedTrustStore map[string][]*x509.Certificate
+
+func (s namedTrustStore) GetCertificates(ctx context.Context, storeType truststore.Type, namedStore string) ([]*x509.Certificate, error) {
+	return s[string(storeType)+":"+namedStore], nil
+}
+
+// signaturesRepository is a mock repository serving a signature blob per
+// signature manifest.
+type signaturesRepository struct {
+	mock.Repository
+	signatures map[digest.Digest][]byte
+}
+
+func (r signaturesRepository) ListSignatures(ctx context.Context, desc ocispec.Descriptor, fn func(signatureManifests []ocispec.Descriptor) error) error {
+	var signatureManifests []ocispec.Descriptor
+	for dgst := range r.signatures {
+		signatureManifests = append(signatureManifests, ocispec.Descriptor{MediaType: ocispec.MediaTypeImageManifest, Digest: dgst})
+	}
+	return fn(signatureManifests)
+}
+
+func (r signaturesRepository) FetchSignatureBlob(ctx context.Context, desc ocispec.Descriptor) ([]byte, ocispec.Descriptor, error) {
+	return r.signatures[desc.Digest], mock.JwsSigEnvDescriptor, nil
+}
+
+func TestVerifyRequiredSignerGroups(t *testing.T) {
+	now := time.Now()
+	sign := func(rootName, leafName string) (*x509.Certificate, []byte) {
+		t.Helper()
+		rootCert := createRotationCertificate(t, rootName, now.AddDate(-1, 0, 0), now.AddDate(1, 0, 0), nil)
+		leafCert := createRotationCertificate(t, leafName, now.AddDate(-1, 0, 0), now.AddDate(1, 0, 0), &rootCert)
+		internalSigner, err := signer.New(leafCert.PrivateKey, []*x509.Certificate{leafCert.Cert, rootCert.Cert})
+		if err != nil {
+			t.Fatalf("Unexpected error while creating signer: %v", err)
+		}
+		sig, _, err := internalSigner.Sign(context.Background(), mock.ImageDescriptor, notation.SignerSignOptions{SignatureMediaType: "application/jose+json"})
+		if err != nil {
+			t.Fatalf("Unexpected error while generating blob: %v", err)
+		}
+		return rootCert.Cert, sig
+	}
+	buildRoot, buildSignature := sign("Build Root", "Build Signer")
+	securityRoot, securitySignature := sign("Security Root", "Security Signer")
+
+	policyDoc := dummyPolicyDocument()
+	policyDoc.TrustPolicies[0].TrustStores = nil
+	policyDoc.TrustPolicies[0].TrustedIdentities = nil
+	policyDoc.TrustPolicies[0].RequiredSignerGroups = []trustpolicy.SignerGroup{
+		{
+			Name:              "build",
+			TrustStores:       []string{"ca:build"},
+			TrustedIdentities: []string{"x509.subject:CN=Build Signer,O=Notary,ST=WA,C=US"},
+		},
+		{
+			Name:              "security",
+			TrustStores:       []string{"ca:security"},
+			TrustedIdentities: []string{"x509.subject:CN=Security Signer,O=Notary,ST=WA,C=US"},
+		},
+	}
+	policyDoc.TrustPolicies[0].SignatureVerification.Override = map[trustpolicy.ValidationType]trustpolicy.ValidationAction{
+		trustpolicy.TypeRevocation: trustpolicy.ActionSkip,
+	}
+	v, err := NewWithOptions(&policyDoc, namedTrustStore{
+		"ca:build":    {buildRoot},
+		"ca:security": {securityRoot},
+	}, mock.PluginManager{}, VerifierOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error while creating verifier: %v", err)
+	}
+	verifyOpts := notation.VerifyOptions{ArtifactReference: mock.SampleArtifactUri, MaxSignatureAttempts: 50}
+
+	t.Run("all signer groups satisfied", func(t *testing.T) {
+		repo := signaturesRepository{
+			Repository: mock.NewRepository(),
+			signatures: map[digest.Digest][]byte{
+				digest.FromBytes(buildSignature):    buildSignature,
+				digest.FromBytes(securitySignature): securitySignature,
+			},
+		}
+		_, outcomes, err := notation.Verify(context.Background(), v, repo, verifyOpts)
+		if err != nil {
+			t.Fatalf("expected verification to succeed, but got %v", err)
+		}
+		var groups []string
+		for _, outcome := range outcomes {
+			groups = append(groups, outcome.SignerGroups...)
+		}
+		if len(outcomes) != 2 || !slices.Contains(groups, "build") || !slices.Contains(groups, "security") {
+			t.Fatalf("expected outcomes satisfying both signer groups, but got groups %v", groups)
+		}
+	})
+
+	t.Run("signature of a signer group missing", func(t *testing.T) {
+		repo := signaturesRepository{
+			Repository: mock.NewRepository(),
+			signatures: map[digest.Digest][]byte{
+				digest.FromBytes(buildSignature): buildSignature,
+			},
+		}
+		_, _, err := notation.Verify(context.Background(), v, repo, verifyOpts)
+		if err == nil || !strings.Contains(err.Error(), `no valid signature is found for the required signer groups ["security"]`) {
+			t.Fatalf("expected missing signer group error, but got %v", err)
+		}
+	})
+
+	t.Run("signature satisfying no signer group", func(t *testing.T) {
+		_, untrustedSignature := sign("Untrusted Root", "Build Signer")
+		_, err := v.Verify(context.Background(), mock.ImageDescriptor, untrustedSignature, notation.VerifierVerifyOptions{ArtifactReference: mock.SampleArtifactUri, SignatureMediaType: "application/jose+json"})
+		if err == nil || !strings.Contains(err.Error(), "signature does not satisfy any required signer group") {
+			t.Fatalf("expected signer group error, but got %v", err)
+		}
+	})
+}
+
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time {
+	return time.Time(c)
+}
+
+func TestVerifyWithClock(t *testing.T) {
+	now := time.Now()
+	rootCert := createRotationCertificate(t, "Clock Root", now.AddDate(-2, 0, 0), now.AddDate(10, 0, 0), nil)
+	leafCert := createRotationCertificate(t, "Clock Signer", now.AddDate(-1, 0, 0), now.AddDate(1, 0, 0), &rootCert)
+
+	desc := mock.ImageDescriptor
+	payloadBytes, err := json.Marshal(envelope.Payload{TargetArtifact: envelope.SanitizeTargetArtifact(desc)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sign := func(signingScheme signature.SigningScheme) []byte {
+		t.Helper()
+		localSigner, err := signature.NewLocalSigner([]*x509.Certificate{leafCert.Cert, rootCert.Cert}, leafCert.PrivateKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sigEnv, err := signature.NewEnvelope("application/jose+json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig, err := sigEnv.Sign(&signature.SignRequest{
+			Payload: signature.Payload{
+				ContentType: envelope.MediaTypePayloadV1,
+				Content:     payloadBytes,
+			},
+			Signer:        localSigner,
+			SigningTime:   now.Add(-time.Hour),
+			Expiry:        now.AddDate(0, 0, 1),
+			SigningScheme: signingScheme,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sig
+	}
+
+	policyDoc := dummyPolicyDocument()
+	policyDoc.TrustPolicies[0].TrustStores = []string{"signingAuthority:clock", "ca:clock"}
+	policyDoc.TrustPolicies[0].TrustedIdentities = []string{"x509.subject:CN=Clock Signer,O=Notary,ST=WA,C=US"}
+	policyDoc.TrustPolicies[0].SignatureVerification.Override = map[trustpolicy.ValidationType]trustpolicy.ValidationAction{
+		trustpolicy.TypeExpiry:             trustpolicy.ActionLog,
+		trustpolicy.TypeAuthenticTimestamp: trustpolicy.ActionLog,
+		trustpolicy.TypeRevocation:         trustpolicy.ActionSkip,
+	}
+	opts := notation.VerifierVerifyOptions{ArtifactReference: mock.SampleArtifactUri, SignatureMediaType: "application/jose+json"}
+	verify := func(t *testing.T, clockTime time.Time, sig []byte) (expiryErr, authenticTimestampErr error) {
+		t.Helper()
+		v := verifier{
+			trustPolicyDoc: &policyDoc,
+			trustStore:     staticTrustStore{rootCert.Cert},
+			pluginManager:  mock.PluginManager{},
+			clock:          fixedClock(clockTime),
+		}
+		outcome, err := v.Verify(context.Background(), desc, sig, opts)
+		if err != nil {
+			t.Fatalf("expected verification to succeed, but got %v", err)
+		}
+		for _, result := range outcome.VerificationResults {
+			switch result.Type {
+			case trustpolicy.TypeExpiry:
+				expiryErr = result.Error
+			case trustpolicy.TypeAuthenticTimestamp:
+				authenticTimestampErr = result.Error
+			}
+		}
+		return
+	}
+
+	t.Run("current clock", func(t *testing.T) {
+		expiryErr, authenticTimestampErr := verify(t, now, sign(signature.SigningSchemeX509SigningAuthority))
+		if expiryErr != nil || authenticTimestampErr != nil {
+			t.Fatalf("expected no expiry and authentic timestamp errors, but got %v and %v", expiryErr, authenticTimestampErr)
+		}
+	})
+
+	t.Run("clock moved forward does not affect the signed signing time", func(t *testing.T) {
+		// the signing certificate has expired at the evaluation time, but it
+		// was valid at the authentic signing time
+		expiryErr, authenticTimestampErr := verify(t, now.AddDate(2, 0, 0), sign(signature.SigningSchemeX509SigningAuthority))
+		if expiryErr == nil || !strings.Contains(expiryErr.Error(), "digital signature has expired") {
+			t.Fatalf("expected expiry error, but got %v", expiryErr)
+		}
+		if authenticTimestampErr != nil {
+			t.Fatalf("expected no authentic timestamp error, but got %v", authenticTimestampErr)
+		}
+	})
+
+	t.Run("clock moved forward affects signatures without timestamp", func(t *testing.T) {
+		expiryErr, authenticTimestampErr := verify(t, now.AddDate(2, 0, 0), sign(signature.SigningSchemeX509))
+		if expiryErr == nil {
+			t.Fatal("expected expiry error")
+		}
+		if authenticTimestampErr == nil || !strings.Contains(authenticTimestampErr.Error(), "is not valid anymore") {
+			t.Fatalf("expected authentic timestamp error, but got %v", authenticTimestampErr)
+		}
+	})
+
+	t.Run("certificate expiry window uses the clock", func(t *testing.T) {
+		v := verifier{
+			trustPolicyDoc:          &policyDoc,
+			trustStore:              staticTrustStore{rootCert.Cert},
+			pluginManager:           mock.PluginManager{},
+			certificateExpiryWindow: 30 * 24 * time.Hour,
+			clock:                   fixedClock(now.AddDate(0, 11, 15)),
+		}
+		outcome, err := v.Verify(context.Background(), desc, sign(signature.SigningSchemeX509), opts)
+		if err != nil {
+			t.Fatalf("expected verification to succeed, but got %v", err)
+		}
+		if len(outcome.Warnings) == 0 {
+			t.Fatal("expected certificate expiry warning")
+		}
+	})
+}
+
+// clockRevocation is a revocation.Revocation reporting every certificate
+// as not revoked and recording the signing times it is asked about.
+type clockRevocation struct {
+	signingTimes []time.Time
+}
+
+func (r *clockRevocation) Validate(certChain []*x509.Certificate, signingTime time.Time) ([]*revocationresult.CertRevocationResult, error) {
+	r.signingTimes = append(r.signingTimes, signingTime)
+	results := make([]*revocationresult.CertRevocationResult, len(certChain))
+	for i := range certChain {
+		results[i] = &revocationresult.CertRevocationResult{Result: revocationresult.ResultOK}
+	}
+	return results, nil
+}
+
+func TestVerifyWithClockAcrossChecks(t *testing.T) {
+	now := time.Now()
+	rootCert := createRotationCertificate(t, "Clock Root", now.AddDate(-2, 0, 0), now.AddDate(10, 0, 0), nil)
+	leafCert := createRotationCertificate(t, "Clock Signer", now.AddDate(-1, 0, 0), now.AddDate(1, 0, 0), &rootCert)
+	payloadBytes, err := json.Marshal(envelope.Payload{TargetArtifact: envelope.SanitizeTargetArtifact(mock.ImageDescriptor)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	localSigner, err := signature.NewLocalSigner([]*x509.Certificate{leafCert.Cert, rootCert.Cert}, leafCert.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sigEnv, err := signature.NewEnvelope("application/jose+json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the signature expires together with the signing certificate
+	sig, err := sigEnv.Sign(&signature.SignRequest{
+		Payload: signature.Payload{
+			ContentType: envelope.MediaTypePayloadV1,
+			Content:     payloadBytes,
+		},
+		Signer:        localSigner,
+		SigningTime:   now,
+		Expiry:        leafCert.Cert.NotAfter,
+		SigningScheme: signature.SigningSchemeX509,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	policyDoc := dummyPolicyDocument()
+	policyDoc.TrustPolicies[0].TrustedIdentities = []string{"x509.subject:CN=Clock Signer,O=Notary,ST=WA,C=US"}
+	policyDoc.TrustPolicies[0].SignatureVerification.Override = map[trustpolicy.ValidationType]trustpolicy.ValidationAction{
+		trustpolicy.TypeExpiry:             trustpolicy.ActionLog,
+		trustpolicy.TypeAuthenticTimestamp: trustpolicy.ActionLog,
+		trustpolicy.TypeRevocation:         trustpolicy.ActionLog,
+	}
+	opts := notation.VerifierVerifyOptions{ArtifactReference: mock.SampleArtifactUri, SignatureMediaType: "application/jose+json"}
+
+	tests := []struct {
+		name        string
+		clockTime   time.Time
+		wantExpired bool
+	}{
+		{"just before certificate expiry", leafCert.Cert.NotAfter.Add(-time.Minute), false},
+		{"just after certificate expiry", leafCert.Cert.NotAfter.Add(time.Minute), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the outcome is the same whenever the verification runs
+			for i := 0; i < 2; i++ {
+				revocationClient := &clockRevocation{}
+				v, err := NewWithOptions(&policyDoc, staticTrustStore{rootCert.Cert}, mock.PluginManager{}, VerifierOptions{
+					Clock:            fixedClock(tt.clockTime),
+					RevocationClient: revocationClient,
+				})
+				if err != nil {
+					t.Fatalf("unexpected error while creating verifier: %v", err)
+				}
+				outcome, err := v.Verify(context.Background(), mock.ImageDescriptor, sig, opts)
+				if err != nil {
+					t.Fatalf("expected verification to succeed, but got %v", err)
+				}
+				results := make(map[trustpolicy.ValidationType]error)
+				for _, result := range outcome.VerificationResults {
+					results[result.Type] = result.Error
+				}
+				if expired := results[trustpolicy.TypeExpiry] != nil; expired != tt.wantExpired {
+					t.Fatalf("expected signature expired to be %v, but got expiry error %v", tt.wantExpired, results[trustpolicy.TypeExpiry])
+				}
+				if expired := results[trustpolicy.TypeAuthenticTimestamp] != nil; expired != tt.wantExpired {
+					t.Fatalf("expected certificate expired to be %v, but got authentic timestamp error %v", tt.wantExpired, results[trustpolicy.TypeAuthenticTimestamp])
+				}
+
+				// revocation is exempt from the clock, and the outcome
+				// says so
+				if err, ok := results[trustpolicy.TypeRevocation]; !ok || err != nil {
+					t.Fatalf("expected revocation to be checked successfully, but got %v", err)
+				}
+				if len(revocationClient.signingTimes) != 1 || !revocationClient.signingTimes[0].IsZero() {
+					t.Fatalf("expected revocation to be checked once without the clock time, but got %v", revocationClient.signingTimes)
+				}
+				if len(outcome.Warnings) != 1 || !strings.Contains(outcome.Warnings[0].Error(), "revocation status is checked at the system time") {
+					t.Fatalf("expected a warning about the revocation check ignoring the clock, but got %v", outcome.Warnings)
+				}
+			}
+		})
+	}
+}
+
+func TestExpiryGracePeriod(t *testing.T) {
+	now := time.Now()
+	rootCert := createRotationCertificate(t, "Grace Root", now.AddDate(-1, 0, 0), now.AddDate(1, 0, 0), nil)
+	leafCert := createRotationCertificate(t, "Grace Signer", now.AddDate(-1, 0, 0), now.AddDate(1, 0, 0), &rootCert)
+	expiry := now.Add(time.Hour)
+	internalSigner, err := signer.New(leafCert.PrivateKey, []*x509.Certificate{leafCert.Cert, rootCert.Cert})
+	if err != nil {
+		t.Fatalf("Unexpected error while creating signer: %v", err)
+	}
+	sig, _, err := internalSigner.Sign(context.Background(), mock.ImageDescriptor, notation.SignerSignOptions{
+		SignatureMediaType: "application/jose+json",
+		ExpiryDuration:     time.Until(expiry),
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error while generating blob: %v", err)
+	}
+
+	policyDoc := dummyPolicyDocument()
+	policyDoc.TrustPolicies[0].TrustedIdentities = []string{"x509.subject:CN=Grace Signer,O=Notary,ST=WA,C=US"}
+	policyDoc.TrustPolicies[0].SignatureVerification.Override = map[trustpolicy.ValidationType]trustpolicy.ValidationAction{
+		trustpolicy.TypeRevocation: trustpolicy.ActionSkip,
+	}
+	opts := notation.VerifierVerifyOptions{ArtifactReference: mock.SampleArtifactUri, SignatureMediaType: "application/jose+json"}
+	newVerifier := func(t *testing.T, clockTime time.Time) notation.Verifier {
+		t.Helper()
+		v, err := NewWithOptions(&policyDoc, staticTrustStore{rootCert.Cert}, mock.PluginManager{}, VerifierOptions{
+			ExpiryGracePeriod: 10 * time.Minute,
+			Clock:             fixedClock(clockTime),
+		})
+		if err != nil {
+			t.Fatalf("unexpected error while creating verifier: %v", err)
+		}
+		return v
+	}
+
+	t.Run("within grace period", func(t *testing.T) {
+		outcome, err := newVerifier(t, expiry.Add(5*time.Minute)).Verify(context.Background(), mock.ImageDescriptor, sig, opts)
+		if err != nil {
+			t.Fatalf("expected verification to succeed, but got %v", err)
+		}
+		if len(outcome.Warnings) != 1 || !strings.Contains(outcome.Warnings[0].Error(), "expiry grace period") {
+			t.Fatalf("expected expiry grace period warning, but got %v", outcome.Warnings)
+		}
+	})
+
+	t.Run("beyond grace period", func(t *testing.T) {
+		_, err := newVerifier(t, expiry.Add(20*time.Minute)).Verify(context.Background(), mock.ImageDescriptor, sig, opts)
+		if err == nil || !strings.Contains(err.Error(), "digital signature has expired") {
+			t.Fatalf("expected expiry error, but got %v", err)
+		}
+	})
+
+	t.Run("grace period out of range", func(t *testing.T) {
+		_, err := NewWithOptions(&policyDoc, staticTrustStore{rootCert.Cert}, mock.PluginManager{}, VerifierOptions{ExpiryGracePeriod: 2 * time.Hour})
+		if err == nil || !strings.Contains(err.Error(), "expiry grace period 2h0m0s is out of range") {
+			t.Fatalf("expected out of range error, but got %v", err)
+		}
+	})
+}
+
+func TestMaxSignatureAge(t *testing.T) {
+	now := time.Now()
+	rootCert := createRotationCertificate(t, "Age Root", now.AddDate(-1, 0, 0), now.AddDate(1, 0, 0), nil)
+	leafCert := createRotationCertificate(t, "Age Signer", now.AddDate(-1, 0, 0), now.AddDate(1, 0, 0), &rootCert)
+	internalSigner, err := signer.New(leafCert.PrivateKey, []*x509.Certificate{leafCert.Cert, rootCert.Cert})
+	if err != nil {
+		t.Fatalf("Unexpected error while creating signer: %v", err)
+	}
+	sig, _, err := internalSigner.Sign(context.Background(), mock.ImageDescriptor, notation.SignerSignOptions{
+		SignatureMediaType: "application/jose+json",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error while generating blob: %v", err)
+	}
+
+	policyDoc := dummyPolicyDocument()
+	policyDoc.TrustPolicies[0].TrustedIdentities = []string{"x509.subject:CN=Age Signer,O=Notary,ST=WA,C=US"}
+	policyDoc.TrustPolicies[0].SignatureVerification.Override = map[trustpolicy.ValidationType]trustpolicy.ValidationAction{
+		trustpolicy.TypeRevocation: trustpolicy.ActionSkip,
+	}
+	opts := notation.VerifierVerifyOptions{ArtifactReference: mock.SampleArtifactUri, SignatureMediaType: "application/jose+json"}
+	newVerifier := func(t *testing.T, clockTime time.Time) notation.Verifier {
+		t.Helper()
+		v, err := NewWithOptions(&policyDoc, staticTrustStore{rootCert.Cert}, mock.PluginManager{}, VerifierOptions{
+			MaxSignatureAge: 30 * 24 * time.Hour,
+			Clock:           fixedClock(clockTime),
+		})
+		if err != nil {
+			t.Fatalf("unexpected error while creating verifier: %v", err)
+		}
+		return v
+	}
+
+	t.Run("fresh signature", func(t *testing.T) {
+		if _, err := newVerifier(t, now.AddDate(0, 0, 29)).Verify(context.Background(), mock.ImageDescriptor, sig, opts); err != nil {
+			t.Fatalf("expected verification to succeed, but got %v", err)
+		}
+	})
+
+	t.Run("signature too old", func(t *testing.T) {
+		_, err := newVerifier(t, now.AddDate(0, 0, 31)).Verify(context.Background(), mock.ImageDescriptor, sig, opts)
+		if err == nil || !strings.Contains(err.Error(), "more than the maximum signature age of 720h0m0s ago") {
+			t.Fatalf("expected signature age error, but got %v", err)
+		}
+	})
+
+	// the signature age is enforced even if the expiry validation is only
+	// logged
+	for _, level := range []*trustpolicy.VerificationLevel{trustpolicy.LevelPermissive, trustpolicy.LevelAudit} {
+		t.Run(fmt.Sprintf("signature too old with %s verification level", level.Name), func(t *testing.T) {
+			policyDoc := dummyPolicyDocument()
+			policyDoc.TrustPolicies[0].TrustedIdentities = []string{"x509.subject:CN=Age Signer,O=Notary,ST=WA,C=US"}
+			policyDoc.TrustPolicies[0].SignatureVerification = trustpolicy.SignatureVerification{
+				VerificationLevel: level.Name,
+				Override: map[trustpolicy.ValidationType]trustpolicy.ValidationAction{
+					trustpolicy.TypeRevocation: trustpolicy.ActionSkip,
+				},
+			}
+			v, err := NewWithOptions(&policyDoc, staticTrustStore{rootCert.Cert}, mock.PluginManager{}, VerifierOptions{
+				MaxSignatureAge: time.Hour,
+				Clock:           fixedClock(now.Add(2 * time.Hour)),
+			})
+			if err != nil {
+				t.Fatalf("unexpected error while creating verifier: %v", err)
+			}
+			outcome, err := v.Verify(context.Background(), mock.ImageDescriptor, sig, opts)
+			if err == nil || !strings.Contains(err.Error(), "more than the maximum signature age of 1h0m0s ago") {
+				t.Fatalf("expected signature age error, but got %v", err)
+			}
+			if result := outcome.VerificationResults[2]; result.Type != trustpolicy.TypeExpiry || result.Action != trustpolicy.ActionEnforce || result.Error != err {
+				t.Fatalf("expected enforced signature age error, but got %+v", result)
+			}
+		})
+	}
+
+	t.Run("negative maximum signature age", func(t *testing.T) {
+		_, err := NewWithOptions(&policyDoc, staticTrustStore{rootCert.Cert}, mock.PluginManager{}, VerifierOptions{MaxSignatureAge: -time.Hour})
+		if err == nil || !strings.Contains(err.Error(), "maximum signature age -1h0m0s is invalid") {
+			t.Fatalf("expected invalid maximum signature age error, but got %v", err)
+		}
+	})
+}
+
+func TestVerifyWithDisabledRoot(t *testing.T) {
+	now := time.Now()
+	rootCert := createRotationCertificate(t, "Disabled Root", now.AddDate(-1, 0, 0), now.AddDate(1, 0, 0), nil)
+	leafCert := createRotationCertificate(t, "Disabled Signer", now.AddDate(-1, 0, 0), now.AddDate(1, 0, 0), &rootCert)
+	otherRootCert := createRotationCertificate(t, "Other Root", now.AddDate(-1, 0, 0), now.AddDate(1, 0, 0), nil)
+	internalSigner, err := signer.New(leafCert.PrivateKey, []*x509.Certificate{leafCert.Cert, rootCert.Cert})
+	if err != nil {
+		t.Fatalf("Unexpected error while creating signer: %v", err)
+	}
+	sig, _, err := internalSigner.Sign(context.Background(), mock.ImageDescriptor, notation.SignerSignOptions{
+		SignatureMediaType: "application/jose+json",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error while generating blob: %v", err)
+	}
+
+	policyDoc := dummyPolicyDocument()
+	policyDoc.TrustPolicies[0].TrustStores = []string{"ca:valid-trust-store"}
+	policyDoc.TrustPolicies[0].TrustedIdentities = []string{"x509.subject:CN=Disabled Signer,O=Notary,ST=WA,C=US"}
+	policyDoc.TrustPolicies[0].SignatureVerification.Override = map[trustpolicy.ValidationType]trustpolicy.ValidationAction{
+		trustpolicy.TypeRevocation: trustpolicy.ActionSkip,
+	}
+	opts := notation.VerifierVerifyOptions{ArtifactReference: mock.SampleArtifactUri, SignatureMediaType: "application/jose+json"}
+	verify := func(t *testing.T, rootFileName string) error {
+		t.Helper()
+		configDir := t.TempDir()
+		storePath := filepath.Join(configDir, "truststore", "x509", "ca", "valid-trust-store")
+		if err := os.MkdirAll(storePath, 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(storePath, "other-root.crt"), otherRootCert.Cert.Raw, 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(storePath, rootFileName), rootCert.Cert.Raw, 0600); err != nil {
+			t.Fatal(err)
+		}
+		v, err := New(&policyDoc, truststore.NewX509TrustStore(dir.NewSysFS(configDir)), mock.PluginManager{})
+		if err != nil {
+			t.Fatalf("unexpected error while creating verifier: %v", err)
+		}
+		_, err = v.Verify(context.Background(), mock.ImageDescriptor, sig, opts)
+		return err
+	}
+
+	t.Run("enabled root", func(t *testing.T) {
+		if err := verify(t, "root.crt"); err != nil {
+			t.Fatalf("expected verification to succeed, but got %v", err)
+		}
+	})
+
+	t.Run("disabled root", func(t *testing.T) {
+		err := verify(t, "root.crt"+truststore.DisabledCertificateSuffix)
+		if err == nil || err.Error() != "signature is not produced by a trusted signer" {
+			t.Fatalf("expected authenticity error, but got %v", err)
+		}
+	})
+}
+
+func TestUnknownHeaderPolicy(t *testing.T) {
+	now := time.Now()
+	rootCert := createRotationCertificate(t, "Header Root", now.AddDate(-1, 0, 0), now.AddDate(1, 0, 0), nil)
+	leafCert := createRotationCertificate(t, "Header Signer", now.AddDate(-1, 0, 0), now.AddDate(1, 0, 0), &rootCert)
+	payloadBytes, err := json.Marshal(envelope.Payload{TargetArtifact: envelope.SanitizeTargetArtifact(mock.ImageDescriptor)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sign := func(t *testing.T, critical bool) []byte {
+		t.Helper()
+		localSigner, err := signature.NewLocalSigner([]*x509.Certificate{leafCert.Cert, rootCert.Cert}, leafCert.PrivateKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sigEnv, err := signature.NewEnvelope("application/jose+json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig, err := sigEnv.Sign(&signature.SignRequest{
+			Payload: signature.Payload{
+				ContentType: envelope.MediaTypePayloadV1,
+				Content:     payloadBytes,
+			},
+			Signer:        localSigner,
+			SigningTime:   now,
+			SigningScheme: signature.SigningSchemeX509,
+			ExtendedSignedAttributes: []signature.Attribute{
+				{Key: "io.example.buildId", Value: "42", Critical: critical},
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sig
+	}
+	benignSig := sign(t, false)
+	criticalSig := sign(t, true)
+
+	policyDoc := dummyPolicyDocument()
+	policyDoc.TrustPolicies[0].TrustedIdentities = []string{"x509.subject:CN=Header Signer,O=Notary,ST=WA,C=US"}
+	policyDoc.TrustPolicies[0].SignatureVerification.Override = map[trustpolicy.ValidationType]trustpolicy.ValidationAction{
+		trustpolicy.TypeRevocation: trustpolicy.ActionSkip,
+	}
+	opts := notation.VerifierVerifyOptions{ArtifactReference: mock.SampleArtifactUri, SignatureMediaType: "application/jose+json"}
+	verify := func(t *testing.T, policy UnknownHeaderPolicy, sig []byte) (*notation.VerificationOutcome, error) {
+		t.Helper()
+		v, err := NewWithOptions(&policyDoc, staticTrustStore{rootCert.Cert}, mock.PluginManager{}, VerifierOptions{UnknownHeaderPolicy: policy})
+		if err != nil {
+			t.Fatalf("unexpected error while creating verifier: %v", err)
+		}
+		return v.Verify(context.Background(), mock.ImageDescriptor, sig, opts)
+	}
+
+	for _, policy := range []UnknownHeaderPolicy{"", UnknownHeaderIgnore} {
+		t.Run("ignore "+string(policy), func(t *testing.T) {
+			outcome, err := verify(t, policy, benignSig)
+			if err != nil {
+				t.Fatalf("expected verification to succeed, but got %v", err)
+			}
+			if len(outcome.Warnings) != 0 {
+				t.Fatalf("expected no warnings, but got %v", outcome.Warnings)
+			}
+		})
+	}
+
+	t.Run("warn", func(t *testing.T) {
+		outcome, err := verify(t, UnknownHeaderWarn, benignSig)
+		if err != nil {
+			t.Fatalf("expected verification to succeed, but got %v", err)
+		}
+		if len(outcome.Warnings) != 1 || outcome.Warnings[0].Error() != "protected header io.example.buildId is not recognized" {
+			t.Fatalf("expected unknown header warning, but got %v", outcome.Warnings)
+		}
+	})
+
+	t.Run("reject", func(t *testing.T) {
+		_, err := verify(t, UnknownHeaderReject, benignSig)
+		var invalidSigErr *signature.InvalidSignatureError
+		if !errors.As(err, &invalidSigErr) || !strings.Contains(err.Error(), "protected header io.example.buildId is not recognized") {
+			t.Fatalf("expected unknown header error, but got %v", err)
+		}
+	})
+
+	for _, policy := range []UnknownHeaderPolicy{UnknownHeaderIgnore, UnknownHeaderWarn, UnknownHeaderReject} {
+		t.Run("critical with "+string(policy), func(t *testing.T) {
+			_, err := verify(t, policy, criticalSig)
+			if err == nil || err.Error() != "protected header io.example.buildId is marked critical but not recognized" {
+				t.Fatalf("expected critical unknown header error, but got %v", err)
+			}
+		})
+	}
+
+	t.Run("unsupported policy", func(t *testing.T) {
+		_, err := NewWithOptions(&policyDoc, staticTrustStore{rootCert.Cert}, mock.PluginManager{}, VerifierOptions{UnknownHeaderPolicy: "drop"})
+		if err == nil || err.Error() != `unknown header policy "drop" is not supported` {
+			t.Fatalf("expected unsupported policy error, but got %v", err)
+		}
+	})
+}
+
+func TestVerificationDurations(t *testing.T) {
+	policyDocument := dummyPolicyDocument()
+	dir.UserConfigDir = "testdata"
+	x509TrustStore := truststore.NewX509TrustStore(dir.ConfigFS())
+	v, err := New(&policyDocument, x509TrustStore, mock.PluginManager{})
+	if err != nil {
+		t.Fatalf("unexpected error while creating verifier: %v", err)
+	}
+	opts := notation.VerifierVerifyOptions{ArtifactReference: mock.SampleArtifactUri, SignatureMediaType: "application/jose+json"}
+
+	t.Run("all validations", func(t *testing.T) {
+		outcome, err := v.Verify(context.Background(), mock.ImageDescriptor, mock.MockCaValidSigEnv, opts)
+		if err != nil {
+			t.Fatalf("expected verification to succeed, but got %v", err)
+		}
+		for _, result := range outcome.VerificationResults {
+			if _, ok := outcome.Durations[result.Type]; !ok {
+				t.Fatalf("expected duration of %v validation to be recorded, but got %v", result.Type, outcome.Durations)
+			}
+		}
+		if len(outcome.Durations) != 5 {
+			t.Fatalf("expected durations of 5 validation types, but got %v", outcome.Durations)
+		}
+	})
+
+	t.Run("short-circuited validations", func(t *testing.T) {
+		outcome, err := v.Verify(context.Background(), mock.ImageDescriptor, mock.MockCaExpiredSigEnv, opts)
+		if err == nil {
+			t.Fatal("expected verification to fail")
+		}
+		for _, validationType := range []trustpolicy.ValidationType{trustpolicy.TypeIntegrity, trustpolicy.TypeAuthenticity, trustpolicy.TypeExpiry} {
+			if _, ok := outcome.Durations[validationType]; !ok {
+				t.Fatalf("expected duration of %v validation to be recorded, but got %v", validationType, outcome.Durations)
+			}
+		}
+		if _, ok := outcome.Durations[trustpolicy.TypeRevocation]; ok {
+			t.Fatalf("expected no duration of the revocation validation after the expiry failure, but got %v", outcome.Durations)
+		}
+	})
+}
+
+func TestBlockedSerials(t *testing.T) {
+	chain := testhelper.GetRevokableRSAChain(3)
+	leaf, intermediate, root := chain[0].Cert, chain[1].Cert, chain[2].Cert
+	internalSigner, err := signer.New(chain[0].PrivateKey, []*x509.Certificate{leaf, intermediate, root})
+	if err != nil {
+		t.Fatalf("Unexpected error while creating signer: %v", err)
+	}
+	sig, _, err := internalSigner.Sign(context.Background(), mock.ImageDescriptor, notation.SignerSignOptions{
+		SignatureMediaType: "application/jose+json",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error while generating blob: %v", err)
+	}
+
+	policyDoc := dummyPolicyDocument()
+	policyDoc.TrustPolicies[0].TrustedIdentities = []string{"*"}
+	policyDoc.TrustPolicies[0].SignatureVerification = trustpolicy.SignatureVerification{
+		VerificationLevel: trustpolicy.LevelAudit.Name,
+		Override:          map[trustpolicy.ValidationType]trustpolicy.ValidationAction{trustpolicy.TypeRevocation: trustpolicy.ActionSkip},
+	}
+	opts := notation.VerifierVerifyOptions{ArtifactReference: mock.SampleArtifactUri, SignatureMediaType: "application/jose+json"}
+	verify := func(t *testing.T, blockedSerials []BlockedSerial) error {
+		t.Helper()
+		v, err := NewWithOptions(&policyDoc, staticTrustStore{root}, mock.PluginManager{}, VerifierOptions{BlockedSerials: blockedSerials})
+		if err != nil {
+			t.Fatalf("unexpected error while creating verifier: %v", err)
+		}
+		_, err = v.Verify(context.Background(), mock.ImageDescriptor, sig, opts)
+		return err
+	}
+
+	t.Run("no blocked certificate", func(t *testing.T) {
+		err := verify(t, []BlockedSerial{
+			{Issuer: leaf.Subject.String(), SerialNumber: leaf.SerialNumber},
+			{Issuer: intermediate.Issuer.String(), SerialNumber: big.NewInt(0).Add(intermediate.SerialNumber, big.NewInt(1))},
+		})
+		if err != nil {
+			t.Fatalf("expected verification to succeed, but got %v", err)
+		}
+	})
+
+	for name, cert := range map[string]*x509.Certificate{"blocked leaf": leaf, "blocked intermediate": intermediate} {
+		t.Run(name, func(t *testing.T) {
+			err := verify(t, []BlockedSerial{{Issuer: cert.Issuer.String(), SerialNumber: cert.SerialNumber}})
+			if !errors.As(err, &CertificateBlockedError{}) {
+				t.Fatalf("expected CertificateBlockedError, but got %v", err)
+			}
+		})
+	}
+
+	t.Run("invalid blocked serial", func(t *testing.T) {
+		_, err := NewWithOptions(&policyDoc, staticTrustStore{root}, mock.PluginManager{}, VerifierOptions{BlockedSerials: []BlockedSerial{{Issuer: leaf.Issuer.String()}}})
+		if err == nil || err.Error() != "blocked serial must have an issuer and a serial number" {
+			t.Fatalf("expected invalid blocked serial error, but got %v", err)
+		}
+	})
+}
+
+func TestTimestampExtendedValidity(t *testing.T) {
+	now := time.Now()
+	rootCert := createRotationCertificate(t, "Timestamp Root", now.AddDate(-1, 0, 0), now.AddDate(1, 0, 0), nil)
+	leafCert := createRotationCertificate(t, "Timestamp Signer", now.AddDate(-1, 0, 0), now.AddDate(1, 0, 0), &rootCert)
+	payloadBytes, err := json.Marshal(envelope.Payload{TargetArtifact: envelope.SanitizeTargetArtifact(mock.ImageDescriptor)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	localSigner, err := signature.NewLocalSigner([]*x509.Certificate{leafCert.Cert, rootCert.Cert}, leafCert.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sigEnv, err := signature.NewEnvelope("application/jose+json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := sigEnv.Sign(&signature.SignRequest{
+		Payload: signature.Payload{
+			ContentType: envelope.MediaTypePayloadV1,
+			Content:     payloadBytes,
+		},
+		Signer:        localSigner,
+		SigningTime:   now,
+		SigningScheme: signature.SigningSchemeX509SigningAuthority,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	policyDoc := dummyPolicyDocument()
+	policyDoc.TrustPolicies[0].TrustedIdentities = []string{"x509.subject:CN=Timestamp Signer,O=Notary,ST=WA,C=US"}
+	policyDoc.TrustPolicies[0].SignatureVerification.Override = map[trustpolicy.ValidationType]trustpolicy.ValidationAction{
+		trustpolicy.TypeRevocation: trustpolicy.ActionSkip,
+	}
+	opts := notation.VerifierVerifyOptions{ArtifactReference: mock.SampleArtifactUri, SignatureMediaType: "application/jose+json"}
+	verify := func(t *testing.T, clockTime time.Time) *notation.VerificationOutcome {
+		t.Helper()
+		v, err := NewWithOptions(&policyDoc, staticTrustStore{rootCert.Cert}, mock.PluginManager{}, VerifierOptions{Clock: fixedClock(clockTime)})
+		if err != nil {
+			t.Fatalf("unexpected error while creating verifier: %v", err)
+		}
+		outcome, err := v.Verify(context.Background(), mock.ImageDescriptor, sig, opts)
+		if err != nil {
+			t.Fatalf("expected verification to succeed, but got %v", err)
+		}
+		return outcome
+	}
+
+	t.Run("certificates valid at verification time", func(t *testing.T) {
+		if outcome := verify(t, now.Add(time.Hour)); outcome.TimestampExtendedValidity {
+			t.Fatal("expected validity not to be extended by the timestamp")
+		}
+	})
+
+	t.Run("certificates expired at verification time", func(t *testing.T) {
+		if outcome := verify(t, now.AddDate(2, 0, 0)); !outcome.TimestampExtendedValidity {
+			t.Fatal("expected validity to be extended by the timestamp")
+		}
+	})
+
+	t.Run("unverified timestamp countersignature", func(t *testing.T) {
+		x509Sig, err := sigEnv.Sign(&signature.SignRequest{
+			Payload: signature.Payload{
+				ContentType: envelope.MediaTypePayloadV1,
+				Content:     payloadBytes,
+			},
+			Signer:        localSigner,
+			SigningTime:   now,
+			SigningScheme: signature.SigningSchemeX509,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		// attach a timestamp countersignature, which is not verified
+		var envelopeJSON map[string]any
+		if err := json.Unmarshal(x509Sig, &envelopeJSON); err != nil {
+			t.Fatal(err)
+		}
+		envelopeJSON["header"].(map[string]any)["io.cncf.notary.timestampSignature"] = []byte("unverified timestamp")
+		if x509Sig, err = json.Marshal(envelopeJSON); err != nil {
+			t.Fatal(err)
+		}
+
+		v, err := NewWithOptions(&policyDoc, staticTrustStore{rootCert.Cert}, mock.PluginManager{}, VerifierOptions{Clock: fixedClock(now.AddDate(2, 0, 0))})
+		if err != nil {
+			t.Fatalf("unexpected error while creating verifier: %v", err)
+		}
+		outcome, _ := v.Verify(context.Background(), mock.ImageDescriptor, x509Sig, opts)
+		if len(outcome.EnvelopeContent.SignerInfo.UnsignedAttributes.TimestampSignature) == 0 {
+			t.Fatal("expected the signature to have a timestamp countersignature")
+		}
+		if outcome.TimestampExtendedValidity {
+			t.Fatal("expected validity not to be extended by an unverified timestamp")
+		}
+	})
+
+	t.Run("verified timestamp countersignature", func(t *testing.T) {
+		x509Sig, err := sigEnv.Sign(&signature.SignRequest{
+			Payload: signature.Payload{
+				ContentType: envelope.MediaTypePayloadV1,
+				Content:     payloadBytes,
+			},
+			Signer:        localSigner,
+			SigningTime:   now,
+			SigningScheme: signature.SigningSchemeX509,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsedEnv, err := signature.ParseEnvelope("application/jose+json", x509Sig)
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := parsedEnv.Content()
+		if err != nil {
+			t.Fatal(err)
+		}
+		tsaRoot := createRotationCertificate(t, "Timestamp Authority Root", now.AddDate(-1, 0, 0), now.AddDate(5, 0, 0), nil)
+		tsa := createTimestampingCertificate(t, tsaRoot)
+		timestamp := func(t *testing.T, signatureValue []byte, genTime time.Time) []byte {
+			t.Helper()
+			var envelopeJSON map[string]any
+			if err := json.Unmarshal(x509Sig, &envelopeJSON); err != nil {
+				t.Fatal(err)
+			}
+			envelopeJSON["header"].(map[string]any)["io.cncf.notary.timestampSignature"] = createSignedTimestampToken(t, signatureValue, genTime, tsa)
+			timestamped, err := json.Marshal(envelopeJSON)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return timestamped
+		}
+
+		tsaPolicyDoc := dummyPolicyDocument()
+		tsaPolicyDoc.TrustPolicies[0].TrustStores = append(tsaPolicyDoc.TrustPolicies[0].TrustStores, "tsa:test-tsa")
+		tsaPolicyDoc.TrustPolicies[0].TrustedIdentities = policyDoc.TrustPolicies[0].TrustedIdentities
+		tsaPolicyDoc.TrustPolicies[0].SignatureVerification = policyDoc.TrustPolicies[0].SignatureVerification
+		verifyTimestamped := func(t *testing.T, sig []byte, clockTime time.Time) (*notation.VerificationOutcome, error) {
+			t.Helper()
+			v, err := NewWithOptions(&tsaPolicyDoc, staticTrustStore{rootCert.Cert, tsaRoot.Cert}, mock.PluginManager{}, VerifierOptions{Clock: fixedClock(clockTime)})
+			if err != nil {
+				t.Fatalf("unexpected error while creating verifier: %v", err)
+			}
+			return v.Verify(context.Background(), mock.ImageDescriptor, sig, opts)
+		}
+
+		validSig := timestamp(t, content.SignerInfo.Signature, now)
+		outcome, err := verifyTimestamped(t, validSig, now.Add(time.Hour))
+		if err != nil {
+			t.Fatalf("expected verification to succeed, but got %v", err)
+		}
+		if outcome.TimestampExtendedValidity {
+			t.Fatal("expected validity not to be extended by the timestamp")
+		}
+
+		// the signing certificate has expired at the time of verification
+		outcome, err = verifyTimestamped(t, validSig, now.AddDate(2, 0, 0))
+		if err != nil {
+			t.Fatalf("expected verification to succeed, but got %v", err)
+		}
+		if !outcome.TimestampExtendedValidity {
+			t.Fatal("expected validity to be extended by the timestamp")
+		}
+
+		// the timestamp does not cover the signature
+		if _, err := verifyTimestamped(t, timestamp(t, []byte("other signature"), now), now.AddDate(2, 0, 0)); err == nil || !strings.Contains(err.Error(), "failed to verify the timestamp countersignature") {
+			t.Fatalf("expected timestamp countersignature verification to fail, but got %v", err)
+		}
+
+		// the signing certificate had expired when the signature was
+		// timestamped
+		if _, err := verifyTimestamped(t, timestamp(t, content.SignerInfo.Signature, now.AddDate(1, 1, 0)), now.AddDate(2, 0, 0)); err == nil || !strings.Contains(err.Error(), "was not valid when the digital signature was timestamped") {
+			t.Fatalf("expected authentic timestamp verification to fail, but got %v", err)
+		}
+	})
+}
+
+func TestSignerIdentity(t *testing.T) {
+	policyDocument := dummyPolicyDocument()
+	dir.UserConfigDir = "testdata"
+	x509TrustStore := truststore.NewX509TrustStore(dir.ConfigFS())
+	v, err := New(&policyDocument, x509TrustStore, mock.PluginManager{})
+	if err != nil {
+		t.Fatalf("unexpected error while creating verifier: %v", err)
+	}
+	opts := notation.VerifierVerifyOptions{ArtifactReference: mock.SampleArtifactUri, SignatureMediaType: "application/jose+json"}
+	outcome, err := v.Verify(context.Background(), mock.ImageDescriptor, mock.MockCaValidSigEnv, opts)
+	if err != nil {
+		t.Fatalf("expected verification to succeed, but got %v", err)
+	}
+	if want := "x509.subject:CN=Notation Test Root,O=Notary,L=Seattle,ST=WA,C=US"; outcome.SignerIdentity != want {
+		t.Fatalf("expected signer identity %q, but got %q", want, outcome.SignerIdentity)
+	}
+
+	// the reported signer identity pins the signer
+	trustPolicy := dummyPolicyStatement()
+	trustPolicy.TrustedIdentities = []string{outcome.SignerIdentity}
+	policyDocument.TrustPolicies = []trustpolicy.TrustPolicy{trustPolicy}
+	if err := policyDocument.Validate(); err != nil {
+		t.Fatalf("expected signer identity to be a valid trusted identity, but got %v", err)
+	}
+	if err := trustpolicy.VerifyTrustedIdentity(outcome.EnvelopeContent.SignerInfo.CertificateChain[0], trustPolicy); err != nil {
+		t.Fatalf("expected signer identity to match the signing certificate, but got %v", err)
+	}
+
+	// subjects that are not valid x509.subject identity values are not
+	// reported
+	for _, subject := range []pkix.Name{
+		{CommonName: "Signer"},
+		{CommonName: "Signer", Organization: []string{"Notary"}, Province: []string{"WA"}, Country: []string{"US", "CA"}},
+	} {
+		if identity := x509SignerIdentity(&x509.Certificate{Subject: subject}); identity != "" {
+			t.Fatalf("expected no signer identity for subject %q, but got %q", subject, identity)
+		}
+	}
+}
+
+func TestCertificateChainOrder(t *testing.T) {
+	chain := testhelper.GetRevokableRSAChain(3)
+	certChain := []*x509.Certificate{chain[0].Cert, chain[1].Cert, chain[2].Cert}
+	internalSigner, err := signer.New(chain[0].PrivateKey, certChain)
+	if err != nil {
+		t.Fatalf("Unexpected error while creating signer: %v", err)
+	}
+	envelopeBlob, _, err := internalSigner.Sign(context.Background(), mock.ImageDescriptor, notation.SignerSignOptions{SignatureMediaType: "application/jose+json"})
+	if err != nil {
+		t.Fatalf("Unexpected error while generating blob: %v", err)
+	}
+	withChain := func(t *testing.T, certs ...*x509.Certificate) []byte {
+		t.Helper()
+		sig, err := envelope.ReplaceCertificateChain("application/jose+json", envelopeBlob, certs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sig
+	}
+	unrelatedCert := testhelper.GetRSARootCertificate().Cert
+
+	policyDoc := dummyPolicyDocument()
+	policyDoc.TrustPolicies[0].TrustedIdentities = []string{"*"}
+	policyDoc.TrustPolicies[0].SignatureVerification.Override = map[trustpolicy.ValidationType]trustpolicy.ValidationAction{
+		trustpolicy.TypeRevocation: trustpolicy.ActionSkip,
+	}
+	opts := notation.VerifierVerifyOptions{ArtifactReference: mock.SampleArtifactUri, SignatureMediaType: "application/jose+json"}
+	verify := func(ctx context.Context, sig []byte, tolerateChainOrder bool) (int, error) {
+		parseCount := 0
+		v := verifier{
+			trustPolicyDoc:     &policyDoc,
+			trustStore:         staticTrustStore{chain[2].Cert},
+			pluginManager:      mock.PluginManager{},
+			tolerateChainOrder: tolerateChainOrder,
+			envelopeParser: func(envelopeMediaType string, envelopeBytes []byte) (*envelope.Envelope, error) {
+				parseCount++
+				return envelope.Parse(envelopeMediaType, envelopeBytes)
+			},
+		}
+		_, err := v.Verify(ctx, mock.ImageDescriptor, sig, opts)
+		return parseCount, err
+	}
+
+	tests := []struct {
+		name               string
+		signature          []byte
+		tolerateChainOrder bool
+		wantParseCount     int
+		wantErr            string
+	}{
+		{
+			name:           "ordered chain",
+			signature:      envelopeBlob,
+			wantParseCount: 1,
+		},
+		{
+			name:           "reversed chain rejected",
+			signature:      withChain(t, chain[2].Cert, chain[1].Cert, chain[0].Cert),
+			wantParseCount: 1,
+			wantErr:        fmt.Sprintf("certificate chain is not ordered leaf-first with each certificate issued by the next one, certificate %q is not issued by certificate %q", chain[2].Cert.Subject, chain[1].Cert.Subject),
+		},
+		{
+			// the envelope with the reordered chain is parsed again
+			name:               "reversed chain tolerated",
+			signature:          withChain(t, chain[2].Cert, chain[1].Cert, chain[0].Cert),
+			tolerateChainOrder: true,
+			wantParseCount:     2,
+		},
+		{
+			name:               "unrelated certificate spliced in",
+			signature:          withChain(t, chain[0].Cert, unrelatedCert, chain[1].Cert, chain[2].Cert),
+			tolerateChainOrder: true,
+			wantParseCount:     1,
+			wantErr:            "do not belong to a single chain",
+		},
+		{
+			name:               "intermediate certificate missing",
+			signature:          withChain(t, chain[2].Cert, chain[0].Cert),
+			tolerateChainOrder: true,
+			wantParseCount:     1,
+			wantErr:            "do not belong to a single chain",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parseCount, err := verify(context.Background(), tt.signature, tt.tolerateChainOrder)
+			if parseCount != tt.wantParseCount {
+				t.Fatalf("expected envelope to be parsed %d times, but got %d", tt.wantParseCount, parseCount)
+			}
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("expected verification to succeed, but got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, but got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	t.Run("envelope parsed by the caller", func(t *testing.T) {
+		sigEnv, err := envelope.Parse("application/jose+json", envelopeBlob)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx := envelope.WithParsedEnvelope(context.Background(), "application/jose+json", envelopeBlob, sigEnv)
+		parseCount, err := verify(ctx, envelopeBlob, false)
+		if err != nil {
+			t.Fatalf("expected verification to succeed, but got %v", err)
+		}
+		if parseCount != 0 {
+			t.Fatalf("expected the parsed envelope to be reused, but the envelope was parsed %d times", parseCount)
+		}
+
+		// the parsed envelope is only reused for the same blob
+		parseCount, _ = verify(ctx, withChain(t, chain[0].Cert, chain[1].Cert, chain[2].Cert), false)
+		if parseCount != 1 {
+			t.Fatalf("expected envelope to be parsed once, but got %d", parseCount)
+		}
+	})
+}
+
+func TestMaxChainDepth(t *testing.T) {
+	chain := testhelper.GetRevokableRSAChain(4)
+	var certChain []*x509.Certificate
+	for _, certTuple := range chain {
+		certChain = append(certChain, certTuple.Cert)
+	}
+	internalSigner, err := signer.New(chain[0].PrivateKey, certChain)
+	if err != nil {
+		t.Fatalf("Unexpected error while creating signer: %v", err)
+	}
+	envelopeBlob, _, err := internalSigner.Sign(context.Background(), mock.ImageDescriptor, notation.SignerSignOptions{SignatureMediaType: "application/jose+json"})
+	if err != nil {
+		t.Fatalf("Unexpected error while generating blob: %v", err)
+	}
+
+	policyDoc := dummyPolicyDocument()
+	policyDoc.TrustPolicies[0].TrustedIdentities = []string{"*"}
+	policyDoc.TrustPolicies[0].SignatureVerification.Override = map[trustpolicy.ValidationType]trustpolicy.ValidationAction{
+		trustpolicy.TypeRevocation: trustpolicy.ActionSkip,
+	}
+	opts := notation.VerifierVerifyOptions{ArtifactReference: mock.SampleArtifactUri, SignatureMediaType: "application/jose+json"}
+
+	t.Run("chain within default depth", func(t *testing.T) {
+		v := verifier{
+			trustPolicyDoc: &policyDoc,
+			trustStore:     staticTrustStore{certChain[len(certChain)-1]},
+			pluginManager:  mock.PluginManager{},
+		}
+		if _, err := v.Verify(context.Background(), mock.ImageDescriptor, envelopeBlob, opts); err != nil {
+			t.Fatalf("expected verification to succeed, but got %v", err)
+		}
+	})
+
+	t.Run("chain exceeding depth", func(t *testing.T) {
+		v := verifier{
+			trustPolicyDoc: &policyDoc,
+			trustStore:     staticTrustStore{certChain[len(certChain)-1]},
+			pluginManager:  mock.PluginManager{},
+			maxChainDepth:  3,
+		}
+		outcome, err := v.Verify(context.Background(), mock.ImageDescriptor, envelopeBlob, opts)
+		var chainDepthErr ChainDepthError
+		if !errors.As(err, &chainDepthErr) {
+			t.Fatalf("expected ChainDepthError, but got %v", err)
+		}
+		if want := "certificate chain has 4 certificates, which exceeds the maximum chain depth of 3"; err.Error() != want {
+			t.Fatalf("expected error %q, but got %q", want, err)
+		}
+		if result := outcome.VerificationResults[len(outcome.VerificationResults)-1]; result.Type != trustpolicy.TypeAuthenticity || result.Error != err {
+			t.Fatalf("expected authenticity failure, but got %+v", result)
+		}
+	})
+
+	t.Run("path built through the trust store exceeding depth", func(t *testing.T) {
+		// the trust store trusts the first intermediate certificate and
+		// holds a cross-signed second intermediate certificate issued by a
+		// deeper hierarchy than the one embedded in the signature
+		createCA := func(template *x509.Certificate, pub any, parent *x509.Certificate, parentKey any) *x509.Certificate {
+			t.Helper()
+			certBytes, err := x509.CreateCertificate(rand.Reader, template, parent, pub, parentKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cert, err := x509.ParseCertificate(certBytes)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return cert
+		}
+		now := time.Now()
+		crossRoot := createRotationCertificate(t, "Cross Root", now.AddDate(-1, 0, 0), now.AddDate(1, 0, 0), nil)
+		crossKey, err := rsa.GenerateKey(rand.Reader, 3072)
+		if err != nil {
+			t.Fatal(err)
+		}
+		crossIntermediate := createCA(&x509.Certificate{
+			SerialNumber:          big.NewInt(time.Now().UnixNano()),
+			Subject:               pkix.Name{CommonName: "Cross Intermediate", Organization: []string{"Notary"}},
+			NotBefore:             now.AddDate(-1, 0, 0),
+			NotAfter:              now.AddDate(1, 0, 0),
+			IsCA:                  true,
+			BasicConstraintsValid: true,
+			KeyUsage:              x509.KeyUsageCertSign,
+		}, &crossKey.PublicKey, crossRoot.Cert, crossRoot.PrivateKey)
+		crossSigned := *chain[2].Cert
+		crossSigned.SerialNumber = big.NewInt(time.Now().UnixNano())
+		crossSignedCert := createCA(&crossSigned, chain[2].Cert.PublicKey, crossIntermediate, crossKey)
+		trustCerts := []*x509.Certificate{certChain[1], crossSignedCert, crossIntermediate, crossRoot.Cert}
+
+		// leaf, trusted intermediate, cross-signed intermediate, cross
+		// intermediate and cross root
+		if err := verifyChainDepth(certChain, certChain[1], trustCerts, 5); err != nil {
+			t.Fatalf("expected path within depth, but got %v", err)
+		}
+		err = verifyChainDepth(certChain, certChain[1], trustCerts, 4)
+		if want := "certificate chain has 5 certificates, which exceeds the maximum chain depth of 4"; err == nil || err.Error() != want {
+			t.Fatalf("expected error %q, but got %v", want, err)
+		}
+
+		v := verifier{
+			trustPolicyDoc: &policyDoc,
+			trustStore:     staticTrustStore(trustCerts),
+			pluginManager:  mock.PluginManager{},
+			maxChainDepth:  4,
+		}
+		if _, err := v.Verify(context.Background(), mock.ImageDescriptor, envelopeBlob, opts); !errors.As(err, &ChainDepthError{}) {
+			t.Fatalf("expected ChainDepthError, but got %v", err)
+		}
+	})
+}
+
+func TestVerifyLeafIsNotCA(t *testing.T) {
+	now := time.Now()
+	rootCert := createRotationCertificate(t, "Trusted Root", now.AddDate(-1, 0, 0), now.AddDate(1, 0, 0), nil)
+	leafCert := createRotationCertificate(t, "Signer", now.AddDate(-1, 0, 0), now.AddDate(1, 0, 0), &rootCert)
+	internalSigner, err := signer.New(leafCert.PrivateKey, []*x509.Certificate{leafCert.Cert, rootCert.Cert})
+	if err != nil {
+		t.Fatalf("Unexpected error while creating signer: %v", err)
+	}
+	envelopeBlob, _, err := internalSigner.Sign(context.Background(), mock.ImageDescriptor, notation.SignerSignOptions{SignatureMediaType: "application/jose+json"})
+	if err != nil {
+		t.Fatalf("Unexpected error while generating blob: %v", err)
+	}
+
+	// present a trusted CA certificate holding the signing key as the
+	// signing certificate. The certificate chain is in the unprotected
+	// header, so the signature stays valid.
+	caTemplate := *leafCert.Cert
+	caTemplate.IsCA = true
+	caTemplate.BasicConstraintsValid = true
+	caTemplate.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature
+	caCertBytes, err := x509.CreateCertificate(rand.Reader, &caTemplate, rootCert.Cert, leafCert.Cert.PublicKey, rootCert.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caCert, err := x509.ParseCertificate(caCertBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var jwsEnvelope map[string]any
+	if err := json.Unmarshal(envelopeBlob, &jwsEnvelope); err != nil {
+		t.Fatal(err)
+	}
+	jwsEnvelope["header"].(map[string]any)["x5c"] = [][]byte{caCert.Raw, rootCert.Cert.Raw}
+	caLeafEnvelopeBlob, err := json.Marshal(jwsEnvelope)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	policyDoc := dummyPolicyDocument()
+	policyDoc.TrustPolicies[0].TrustedIdentities = []string{"*"}
+	policyDoc.TrustPolicies[0].SignatureVerification.Override = map[trustpolicy.ValidationType]trustpolicy.ValidationAction{
+		trustpolicy.TypeRevocation: trustpolicy.ActionSkip,
+	}
+	v := verifier{
+		trustPolicyDoc: &policyDoc,
+		trustStore:     staticTrustStore{rootCert.Cert, caCert},
+		pluginManager:  mock.PluginManager{},
+	}
+	opts := notation.VerifierVerifyOptions{ArtifactReference: mock.SampleArtifactUri, SignatureMediaType: "application/jose+json"}
 
-	// Get revokable certs and set up mock client (will always say certs are revoked)
+	if _, err := v.Verify(context.Background(), mock.ImageDescriptor, envelopeBlob, opts); err != nil {
+		t.Fatalf("expected signature of the leaf certificate to verify, but got %v", err)
+	}
+	outcome, err := v.Verify(context.Background(), mock.ImageDescriptor, caLeafEnvelopeBlob, opts)
+	var leafIsCAErr LeafIsCAError
+	if !errors.As(err, &leafIsCAErr) {
+		t.Fatalf("expected LeafIsCAError, but got %v", err)
+	}
+	if outcome.VerificationResults[0].Type != trustpolicy.TypeIntegrity {
+		t.Fatalf("expected integrity failure, but got %v", outcome.VerificationResults[0].Type)
+	}
+}
+
+func TestCheckOrder(t *testing.T) {
 	revokableChain := testhelper.GetRevokableRSAChain(2)
 	httpClient := testhelper.MockClient(revokableChain, []ocsp.ResponseStatus{ocsp.Revoked}, nil, true)
 	revocationClient, err := revocation.New(httpClient)
 	if err != nil {
 		t.Fatalf("unexpected error while creating revocation object: %v", err)
 	}
-
-	// Generate blob with revokable certs
 	internalSigner, err := signer.New(revokableChain[0].PrivateKey, []*x509.Certificate{revokableChain[0].Cert, revokableChain[1].Cert})
 	if err != nil {
 		t.Fatalf("Unexpected error while creating signer: %v", err)
 	}
-	envelopeBlob, _, err := internalSigner.Sign(context.Background(), payload.TargetArtifact, notation.SignerSignOptions{ExpiryDuration: 24 * time.Hour, SignatureMediaType: "application/jose+json"})
+	envelopeBlob, _, err := internalSigner.Sign(context.Background(), mock.ImageDescriptor, notation.SignerSignOptions{ExpiryDuration: 24 * time.Hour, SignatureMediaType: "application/jose+json"})
 	if err != nil {
 		t.Fatalf("Unexpected error while generating blob: %v", err)
 	}
 
-	t.Run("enforced revoked cert", func(t *testing.T) {
-		testedLevel := trustpolicy.LevelStrict
-		policyDoc := dummyPolicyDocument()
-		policyDoc.TrustPolicies[0].SignatureVerification.VerificationLevel = testedLevel.Name
-		policyDoc.TrustPolicies[0].SignatureVerification.Override = map[trustpolicy.ValidationType]trustpolicy.ValidationAction{
-			trustpolicy.TypeAuthenticity: trustpolicy.ActionLog,
-			trustpolicy.TypeRevocation:   trustpolicy.ActionEnforce,
-		}
-		var expectedErr error = fmt.Errorf("signing certificate with subject %q is revoked", revokableChain[0].Cert.Subject.String())
-		expectedResult := notation.ValidationResult{
-			Type:   trustpolicy.TypeRevocation,
-			Action: trustpolicy.ActionEnforce,
-			Error:  expectedErr,
-		}
-
-		dir.UserConfigDir = "testdata"
-
-		verifier := verifier{
-			trustPolicyDoc:   &policyDoc,
-			trustStore:       truststore.NewX509TrustStore(dir.ConfigFS()),
-			pluginManager:    pluginManager,
-			revocationClient: revocationClient,
-		}
-		outcome, err := verifier.Verify(context.Background(), desc, envelopeBlob, opts)
-		if err == nil || err.Error() != expectedErr.Error() {
-			t.Fatalf("Expected verify to fail with %v, but got %v", expectedErr, err)
-		}
-		verifyResult(outcome, expectedResult, expectedErr, t)
-	})
-	t.Run("log revoked cert", func(t *testing.T) {
-		testedLevel := trustpolicy.LevelStrict
-		policyDoc := dummyPolicyDocument()
-		policyDoc.TrustPolicies[0].SignatureVerification.VerificationLevel = testedLevel.Name
-		policyDoc.TrustPolicies[0].SignatureVerification.Override = map[trustpolicy.ValidationType]trustpolicy.ValidationAction{
-			trustpolicy.TypeAuthenticity: trustpolicy.ActionLog,
-			trustpolicy.TypeRevocation:   trustpolicy.ActionLog,
-		}
-		var expectedErr error = fmt.Errorf("signing certificate with subject %q is revoked", revokableChain[0].Cert.Subject.String())
-		expectedResult := notation.ValidationResult{
-			Type:   trustpolicy.TypeRevocation,
-			Action: trustpolicy.ActionLog,
-			Error:  expectedErr,
-		}
-
-		dir.UserConfigDir = "testdata"
-
-		verifier := verifier{
-			trustPolicyDoc:   &policyDoc,
-			trustStore:       truststore.NewX509TrustStore(dir.ConfigFS()),
-			pluginManager:    pluginManager,
-			revocationClient: revocationClient,
-		}
-		ctx := context.Background()
-		outcome, err := verifier.Verify(ctx, desc, envelopeBlob, opts)
-		if err != nil {
-			t.Fatalf("Unexpected error while verifying: %v", err)
-		}
-		verifyResult(outcome, expectedResult, expectedErr, t)
-	})
-	t.Run("skip revoked cert", func(t *testing.T) {
-		testedLevel := trustpolicy.LevelStrict
-		policyDoc := dummyPolicyDocument()
-		policyDoc.TrustPolicies[0].SignatureVerification.VerificationLevel = testedLevel.Name
-		policyDoc.TrustPolicies[0].SignatureVerification.Override = map[trustpolicy.ValidationType]trustpolicy.ValidationAction{
-			trustpolicy.TypeAuthenticity: trustpolicy.ActionLog,
-			trustpolicy.TypeRevocation:   trustpolicy.ActionSkip,
-		}
-
-		dir.UserConfigDir = "testdata"
+	// the signing certificate is revoked and does not match the trusted
+	// identity
+	policyDoc := dummyPolicyDocument()
+	policyDoc.TrustPolicies[0].TrustedIdentities = []string{"x509.subject:CN=Someone Else,O=Notary,ST=WA,C=US"}
+	opts := notation.VerifierVerifyOptions{ArtifactReference: mock.SampleArtifactUri, SignatureMediaType: "application/jose+json"}
+	revokedErr := fmt.Sprintf("signing certificate with subject %q is revoked", revokableChain[0].Cert.Subject.String())
+	identityErr := "signing certificate from the digital signature does not match the X.509 trusted identities"
 
-		verifier := verifier{
-			trustPolicyDoc:   &policyDoc,
-			trustStore:       truststore.NewX509TrustStore(dir.ConfigFS()),
-			pluginManager:    pluginManager,
-			revocationClient: revocationClient,
-		}
-		outcome, err := verifier.Verify(context.Background(), desc, envelopeBlob, opts)
-		if err != nil {
-			t.Fatalf("Unexpected error while verifying: %v", err)
-		}
-		for _, result := range outcome.VerificationResults {
-			if result.Type == trustpolicy.TypeRevocation {
-				t.Fatal("expected no result for TypeRevocation after skip")
+	tests := []struct {
+		name        string
+		checkOrder  []Check
+		expectedErr string
+	}{
+		{
+			name:        "default order checks trusted identity first",
+			expectedErr: identityErr,
+		},
+		{
+			name:        "revocation checked before trusted identity",
+			checkOrder:  []Check{CheckRevocation, CheckTrustedIdentity, CheckExpiry, CheckAuthenticTimestamp},
+			expectedErr: revokedErr,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := NewWithOptions(&policyDoc, staticTrustStore{revokableChain[1].Cert}, mock.PluginManager{}, VerifierOptions{RevocationClient: revocationClient, CheckOrder: tt.checkOrder})
+			if err != nil {
+				t.Fatalf("unexpected error while creating verifier: %v", err)
 			}
+			_, err = v.Verify(context.Background(), mock.ImageDescriptor, envelopeBlob, opts)
+			if err == nil || !strings.HasPrefix(err.Error(), tt.expectedErr) {
+				t.Fatalf("expected error %q, but got %v", tt.expectedErr, err)
+			}
+		})
+	}
+
+	for _, checkOrder := range [][]Check{
+		{CheckRevocation, CheckTrustedIdentity, CheckExpiry},
+		{CheckRevocation, CheckRevocation, CheckExpiry, CheckAuthenticTimestamp},
+		{CheckRevocation, CheckTrustedIdentity, CheckExpiry, "integrity"},
+	} {
+		if _, err := NewWithOptions(&policyDoc, staticTrustStore{revokableChain[1].Cert}, mock.PluginManager{}, VerifierOptions{RevocationClient: revocationClient, CheckOrder: checkOrder}); err == nil {
+			t.Errorf("expected invalid check order %v to be rejected", checkOrder)
 		}
-	})
+	}
 }
 
 func createMockOutcome(certChain []*x509.Certificate, signingTime time.Time) *notation.VerificationOutcome {
@@ -745,6 +2531,17 @@ func TestNewWithOptions(t *testing.T) {
 			t.Fatalf("expected %v to be created, but got %v", expectedV, v)
 		}
 	})
+	t.Run("fail with invalid required certificate policy", func(t *testing.T) {
+		v, err := NewWithOptions(&policy, store, pm, VerifierOptions{RequiredCertificatePolicies: []string{"2.23.x.1"}})
+
+		expectedErrMsg := "invalid required certificate policy: \"2.23.x.1\" is not a valid object identifier"
+		if err == nil || err.Error() != expectedErrMsg {
+			t.Fatalf("expected NewWithOptions constructor to fail with %v, but got %v", expectedErrMsg, err)
+		}
+		if v != nil {
+			t.Fatal("expected constructor to return nil")
+		}
+	})
 	t.Run("fail with nil trust policy", func(t *testing.T) {
 		v, err := NewWithOptions(nil, store, pm, opts)
 
@@ -1039,6 +2836,9 @@ func TestVerifyX509TrustedIdentities(t *testing.T) {
 
 	certs, _ := corex509.ReadCertificateFile(filepath.FromSlash("testdata/verifier/signing-cert.pem"))        // cert's subject is "CN=SomeCN,OU=SomeOU,O=SomeOrg,L=Seattle,ST=WA,C=US"
 	unsupportedCerts, _ := corex509.ReadCertificateFile(filepath.FromSlash("testdata/verifier/bad-cert.pem")) // cert's subject is "CN=bad=#CN,OU=SomeOU,O=SomeOrg,L=Seattle,ST=WA,C=US"
+	fingerprint := sha256.Sum256(certs[0].Raw)
+	certFingerprint := hex.EncodeToString(fingerprint[:])
+	otherFingerprint := strings.Repeat("0", 64)
 
 	tests := []struct {
 		certs          []*x509.Certificate
@@ -1055,6 +2855,12 @@ func TestVerifyX509TrustedIdentities(t *testing.T) {
 		{certs, []string{"x509.subject:C=IND,O=SomeOrg,ST=TS", "x509.subject:C=LOL,O=LOL,ST=LOL"}, true},
 		{certs, []string{"x509.subject:C=bad=#identity,O=LOL,ST=LOL"}, true},
 		{unsupportedCerts, []string{"x509.subject:C=US,O=SomeOrg,ST=WA", "nonX509Prefix:my-custom-identity"}, true},
+		{certs, []string{"x509.fingerprint.sha256:" + certFingerprint}, false},
+		{certs, []string{"x509.fingerprint.sha256:" + strings.ToUpper(certFingerprint)}, false},
+		{certs, []string{"x509.fingerprint.sha256:" + otherFingerprint}, true},
+		{certs, []string{"x509.fingerprint.sha256:" + otherFingerprint, "x509.subject:C=US,O=SomeOrg,ST=WA"}, false},
+		{certs, []string{"x509.fingerprint.sha256:" + certFingerprint, "x509.subject:C=IND,O=SomeOrg,ST=TS"}, false},
+		{certs, []string{"x509.fingerprint.sha256:" + otherFingerprint, "x509.subject:C=IND,O=SomeOrg,ST=TS"}, true},
 	}
 	for i, tt := range tests {
 		t.Run(strconv.Itoa(i), func(t *testing.T) {
@@ -1074,6 +2880,89 @@ func TestVerifyX509TrustedIdentities(t *testing.T) {
 	}
 }
 
+func TestVerifyCertificatePolicies(t *testing.T) {
+	requiredPolicy := asn1.ObjectIdentifier{2, 23, 140, 1, 2, 1}
+	otherPolicy := asn1.ObjectIdentifier{2, 23, 140, 1, 2, 2}
+	tests := []struct {
+		name     string
+		policies []asn1.ObjectIdentifier
+		wantErr  bool
+	}{
+		{"required policy", []asn1.ObjectIdentifier{otherPolicy, requiredPolicy}, false},
+		{"any policy", []asn1.ObjectIdentifier{oidAnyPolicy}, false},
+		{"missing required policy", []asn1.ObjectIdentifier{otherPolicy}, true},
+		{"no policies", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			leafCert := &x509.Certificate{PolicyIdentifiers: tt.policies}
+			err := verifyCertificatePolicies([]*x509.Certificate{leafCert}, []asn1.ObjectIdentifier{requiredPolicy})
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("TestVerifyCertificatePolicies Error: %v WantErr: %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.As(err, &CertificatePolicyError{}) {
+				t.Fatalf("expected CertificatePolicyError, but got %T", err)
+			}
+		})
+	}
+}
+
+func TestCheckCertificateExpiryWindow(t *testing.T) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	window := 30 * 24 * time.Hour
+	tests := []struct {
+		name        string
+		scheme      signature.SigningScheme
+		notAfter    time.Time
+		wantWarning bool
+	}{
+		{"expiring soon", signature.SigningSchemeX509, now.Add(7 * 24 * time.Hour), true},
+		{"ample validity", signature.SigningSchemeX509, now.Add(365 * 24 * time.Hour), false},
+		{"signing authority scheme", signature.SigningSchemeX509SigningAuthority, now.Add(7 * 24 * time.Hour), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signerInfo := &signature.SignerInfo{
+				SignedAttributes: signature.SignedAttributes{SigningScheme: tt.scheme},
+				CertificateChain: []*x509.Certificate{{NotAfter: tt.notAfter}},
+			}
+			err := checkCertificateExpiryWindow(signerInfo, window, now)
+			if tt.wantWarning != (err != nil) {
+				t.Fatalf("TestCheckCertificateExpiryWindow Error: %v WantWarning: %v", err, tt.wantWarning)
+			}
+		})
+	}
+
+	t.Run("warning recorded in outcome", func(t *testing.T) {
+		policyDocument := dummyPolicyDocument()
+		policyDocument.TrustPolicies[0].SignatureVerification.Override = map[trustpolicy.ValidationType]trustpolicy.ValidationAction{
+			trustpolicy.TypeRevocation: trustpolicy.ActionSkip,
+		}
+		dir.UserConfigDir = "testdata"
+		store := truststore.NewX509TrustStore(dir.ConfigFS())
+		opts := notation.VerifierVerifyOptions{ArtifactReference: mock.SampleArtifactUri, SignatureMediaType: "application/jose+json"}
+		for _, tc := range []struct {
+			window       time.Duration
+			wantWarnings int
+		}{
+			{200 * 365 * 24 * time.Hour, 1},
+			{30 * 24 * time.Hour, 0},
+		} {
+			v, err := NewWithOptions(&policyDocument, store, mock.PluginManager{}, VerifierOptions{CertificateExpiryWarningWindow: tc.window})
+			if err != nil {
+				t.Fatalf("unexpected error while creating verifier: %v", err)
+			}
+			outcome, err := v.Verify(context.Background(), mock.ImageDescriptor, mock.MockCaValidSigEnv, opts)
+			if err != nil {
+				t.Fatalf("expected verification to succeed, but got %v", err)
+			}
+			if len(outcome.Warnings) != tc.wantWarnings {
+				t.Fatalf("expected %d warnings with window %v, but got %v", tc.wantWarnings, tc.window, outcome.Warnings)
+			}
+		}
+	})
+}
+
 func TestVerifyUserMetadata(t *testing.T) {
 	policyDocument := dummyPolicyDocument()
 	policyDocument.TrustPolicies[0].SignatureVerification.VerificationLevel = trustpolicy.LevelAudit.Name
@@ -1123,6 +3012,316 @@ func TestVerifyUserMetadata(t *testing.T) {
 	}
 }
 
+func TestVerifyPayloadAnnotations(t *testing.T) {
+	manyAnnotations := make(map[string]string)
+	for i := 0; i <= defaultMaxPayloadAnnotationCount; i++ {
+		manyAnnotations[strconv.Itoa(i)] = "value"
+	}
+	largeAnnotations := map[string]string{"key": strings.Repeat("a", defaultMaxPayloadAnnotationSize)}
+
+	tests := []struct {
+		name        string
+		verifier    verifier
+		annotations map[string]string
+		wantErr     string
+	}{
+		{"no annotations", verifier{}, nil, ""},
+		{"within default limits", verifier{}, map[string]string{"key": "value"}, ""},
+		{"excessive annotation count", verifier{}, manyAnnotations, "signature payload has 1025 annotations, which exceeds the limit of 1024 annotations"},
+		{"oversized annotations", verifier{}, largeAnnotations, "signature payload annotations have a total size of 1048579 bytes, which exceeds the limit of 1048576 bytes"},
+		{"configured count limit", verifier{maxPayloadAnnotationCount: 1}, map[string]string{"k1": "v1", "k2": "v2"}, "signature payload has 2 annotations, which exceeds the limit of 1 annotations"},
+		{"configured size limit", verifier{maxPayloadAnnotationSize: 4}, map[string]string{"key": "value"}, "signature payload annotations have a total size of 8 bytes, which exceeds the limit of 4 bytes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload := &envelope.Payload{TargetArtifact: ocispec.Descriptor{Annotations: tt.annotations}}
+			err := tt.verifier.verifyPayloadAnnotations(payload)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Fatalf("expected error %q, got: %v", tt.wantErr, err)
+			}
+			if tt.wantErr != "" && !errors.As(err, &notation.ErrorVerificationFailed{}) {
+				t.Fatalf("expected notation.ErrorVerificationFailed, got: %T", err)
+			}
+		})
+	}
+
+	t.Run("verify signature with excessive annotation count", func(t *testing.T) {
+		policyDocument := dummyPolicyDocument()
+		policyDocument.TrustPolicies[0].SignatureVerification.VerificationLevel = trustpolicy.LevelAudit.Name
+		dir.UserConfigDir = "testdata"
+		revocationClient, err := revocation.New(&http.Client{Timeout: 2 * time.Second})
+		if err != nil {
+			t.Fatalf("unexpected error while creating revocation object: %v", err)
+		}
+		v := verifier{
+			trustPolicyDoc:            &policyDocument,
+			trustStore:                truststore.NewX509TrustStore(dir.ConfigFS()),
+			pluginManager:             mock.PluginManager{},
+			revocationClient:          revocationClient,
+			maxPayloadAnnotationCount: 1,
+		}
+		opts := notation.VerifierVerifyOptions{ArtifactReference: mock.SampleArtifactUri, SignatureMediaType: "application/jose+json"}
+		outcome, err := v.Verify(context.Background(), mock.MetadataSigEnvDescriptor, mock.MockSigEnvWithMetadata, opts)
+		expectedErr := "signature payload has 2 annotations, which exceeds the limit of 1 annotations"
+		if err == nil || err.Error() != expectedErr || outcome.Error == nil {
+			t.Fatalf("expected error %q, got: %v", expectedErr, err)
+		}
+		if !errors.As(err, &notation.ErrorVerificationFailed{}) {
+			t.Fatalf("expected notation.ErrorVerificationFailed, got: %T", err)
+		}
+		// the limits are enforced by the integrity check, before the payload
+		// is exposed
+		if len(outcome.VerificationResults) != 1 || outcome.VerificationResults[0].Type != trustpolicy.TypeIntegrity || outcome.EnvelopeContent != nil {
+			t.Fatalf("expected the integrity check to fail without exposing the payload, got results %v", outcome.VerificationResults)
+		}
+	})
+}
+
+func TestEnvelopeCache(t *testing.T) {
+	policyDocument := dummyPolicyDocument()
+	dir.UserConfigDir = "testdata"
+	store := truststore.NewX509TrustStore(dir.ConfigFS())
+	revocationClient, err := revocation.New(&http.Client{Timeout: 2 * time.Second})
+	if err != nil {
+		t.Fatalf("unexpected error while creating revocation object: %v", err)
+	}
+	v, err := NewWithOptions(&policyDocument, store, mock.PluginManager{}, VerifierOptions{RevocationClient: revocationClient, EnvelopeCacheSize: 1})
+	if err != nil {
+		t.Fatalf("unexpected error while creating verifier: %v", err)
+	}
+	verifierV := v.(*verifier)
+	parseCount := 0
+	verifierV.envelopeParser = func(envelopeMediaType string, envelopeBytes []byte) (*envelope.Envelope, error) {
+		parseCount++
+		return envelope.Parse(envelopeMediaType, envelopeBytes)
+	}
+	opts := notation.VerifierVerifyOptions{ArtifactReference: mock.SampleArtifactUri, SignatureMediaType: "application/jose+json"}
+
+	verify := func(sigBlob []byte, expectedParseCount int) {
+		t.Helper()
+		outcome, _ := v.Verify(context.Background(), ocispec.Descriptor{}, sigBlob, opts)
+		verifyResult(outcome, notation.ValidationResult{Type: trustpolicy.TypeIntegrity, Action: trustpolicy.ActionEnforce}, nil, t)
+		if parseCount != expectedParseCount {
+			t.Fatalf("expected envelope to be parsed %d times, but got %d", expectedParseCount, parseCount)
+		}
+	}
+	verify(mock.MockCaValidSigEnv, 1)
+	// cache hit
+	verify(mock.MockCaValidSigEnv, 1)
+	// cache miss evicts the previously parsed envelope
+	verify(mock.MockSaValidSigEnv, 2)
+	verify(mock.MockCaValidSigEnv, 3)
+
+	// parse failures are not cached
+	opts.SignatureMediaType = "application/unsupported+json"
+	for i := 0; i < 2; i++ {
+		outcome, _ := v.Verify(context.Background(), ocispec.Descriptor{}, mock.MockCaValidSigEnv, opts)
+		if outcome.Error == nil {
+			t.Fatal("expected verification to fail for unsupported signature media type")
+		}
+	}
+	if parseCount != 5 {
+		t.Fatalf("expected envelope to be parsed 5 times, but got %d", parseCount)
+	}
+}
+
+func TestOutcomeCache(t *testing.T) {
+	policyDocument := dummyPolicyDocument()
+	policyDocument.TrustPolicies[0].SignatureVerification.Override = map[trustpolicy.ValidationType]trustpolicy.ValidationAction{
+		trustpolicy.TypeRevocation: trustpolicy.ActionSkip,
+	}
+	dir.UserConfigDir = "testdata"
+	store := truststore.NewX509TrustStore(dir.ConfigFS())
+	v, err := NewWithOptions(&policyDocument, store, mock.PluginManager{}, VerifierOptions{OutcomeCacheTTL: time.Hour, OutcomeCacheFailureTTL: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("unexpected error while creating verifier: %v", err)
+	}
+	parseCount := 0
+	v.(*verifier).envelopeParser = func(envelopeMediaType string, envelopeBytes []byte) (*envelope.Envelope, error) {
+		parseCount++
+		return envelope.Parse(envelopeMediaType, envelopeBytes)
+	}
+	opts := notation.VerifierVerifyOptions{ArtifactReference: mock.SampleArtifactUri, SignatureMediaType: "application/jose+json"}
+
+	verify := func(desc ocispec.Descriptor, expectErr bool, expectedParseCount int) {
+		t.Helper()
+		_, err := v.Verify(context.Background(), desc, mock.MockCaValidSigEnv, opts)
+		if (err != nil) != expectErr {
+			t.Fatalf("unexpected verification error: %v", err)
+		}
+		if parseCount != expectedParseCount {
+			t.Fatalf("expected signature to be verified %d times, but got %d", expectedParseCount, parseCount)
+		}
+	}
+	verify(mock.ImageDescriptor, false, 1)
+	// cache hit for an unchanged policy
+	verify(mock.ImageDescriptor, false, 1)
+
+	// cache miss after the policy is modified
+	policyDocument.TrustPolicies[0].TrustedIdentities = append(policyDocument.TrustPolicies[0].TrustedIdentities, "x509.subject:CN=Other,O=Notary,ST=WA,C=US")
+	verify(mock.ImageDescriptor, false, 2)
+	verify(mock.ImageDescriptor, false, 2)
+
+	// failures are cached for the shorter failure TTL
+	mismatchedDesc := mock.ImageDescriptor
+	mismatchedDesc.Size++
+	verify(mismatchedDesc, true, 3)
+	verify(mismatchedDesc, true, 3)
+	time.Sleep(20 * time.Millisecond)
+	verify(mismatchedDesc, true, 4)
+	// successes are still cached
+	verify(mock.ImageDescriptor, false, 4)
+
+	// modifying a returned outcome does not affect the cached outcome
+	outcome, _ := v.Verify(context.Background(), mock.ImageDescriptor, mock.MockCaValidSigEnv, opts)
+	outcome.Warnings = append(outcome.Warnings, errors.New("modified"))
+	outcome.Durations[trustpolicy.TypeIntegrity] = time.Hour
+	outcome.VerificationResults[0].Error = errors.New("modified")
+	outcome.VerificationLevel.Enforcement[trustpolicy.TypeIntegrity] = trustpolicy.ActionSkip
+	outcome.EnvelopeContent.Payload.Content[0] = 0
+	cached, _ := v.Verify(context.Background(), mock.ImageDescriptor, mock.MockCaValidSigEnv, opts)
+	if parseCount != 4 {
+		t.Fatalf("expected a cache hit, but the signature was verified %d times", parseCount)
+	}
+	if len(cached.Warnings) != 0 || cached.Durations[trustpolicy.TypeIntegrity] == time.Hour || cached.VerificationResults[0].Error != nil ||
+		cached.VerificationLevel.Enforcement[trustpolicy.TypeIntegrity] != trustpolicy.ActionEnforce || cached.EnvelopeContent.Payload.Content[0] == 0 {
+		t.Fatal("expected the cached outcome to be unchanged by modifications of a returned outcome")
+	}
+
+	// repeated verification of a cached failure reports the same results
+	policyDocument.TrustPolicies[0].TrustedIdentities = []string{"x509.subject:CN=Other,O=Notary,ST=WA,C=US"}
+	v, err = NewWithOptions(&policyDocument, store, mock.PluginManager{}, VerifierOptions{OutcomeCacheTTL: time.Hour, OutcomeCacheFailureTTL: time.Hour})
+	if err != nil {
+		t.Fatalf("unexpected error while creating verifier: %v", err)
+	}
+	verifyOpts := notation.VerifyOptions{ArtifactReference: mock.SampleArtifactUri, MaxSignatureAttempts: 50}
+	_, _, firstErr := notation.Verify(context.Background(), v, mock.NewRepository(), verifyOpts)
+	if firstErr == nil {
+		t.Fatal("expected verification to fail for mismatched trusted identities")
+	}
+	for i := 0; i < 2; i++ {
+		_, _, err := notation.Verify(context.Background(), v, mock.NewRepository(), verifyOpts)
+		if err == nil || err.Error() != firstErr.Error() {
+			t.Fatalf("expected error %q, but got %v", firstErr, err)
+		}
+	}
+}
+
+func BenchmarkEnvelopeCache(b *testing.B) {
+	policyDocument := dummyPolicyDocument()
+	policyDocument.TrustPolicies[0].SignatureVerification.Override = map[trustpolicy.ValidationType]trustpolicy.ValidationAction{
+		trustpolicy.TypeRevocation: trustpolicy.ActionSkip,
+	}
+	dir.UserConfigDir = "testdata"
+	store := truststore.NewX509TrustStore(dir.ConfigFS())
+	opts := notation.VerifierVerifyOptions{ArtifactReference: mock.SampleArtifactUri, SignatureMediaType: "application/jose+json"}
+
+	for _, cacheSize := range []int{0, 16} {
+		b.Run(fmt.Sprintf("cacheSize=%d", cacheSize), func(b *testing.B) {
+			v, err := NewWithOptions(&policyDocument, store, mock.PluginManager{}, VerifierOptions{EnvelopeCacheSize: cacheSize})
+			if err != nil {
+				b.Fatalf("unexpected error while creating verifier: %v", err)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				v.Verify(context.Background(), mock.ImageDescriptor, mock.MockCaValidSigEnv, opts)
+			}
+		})
+	}
+}
+
+// namespaceResolver resolves trusted identities based on the namespace of
+// the artifact reference
+type namespaceResolver struct {
+	identities map[string][]string
+	calls      int
+}
+
+func (r *namespaceResolver) Resolve(ctx context.Context, statement *trustpolicy.TrustPolicy, artifactReference string) ([]string, error) {
+	r.calls++
+	_, repository, _ := strings.Cut(artifactReference, "/")
+	namespace, _, _ := strings.Cut(repository, "/")
+	return r.identities[namespace], nil
+}
+
+func TestTrustedIdentityResolver(t *testing.T) {
+	policyDocument := dummyPolicyDocument()
+	policyDocument.TrustPolicies[0].RegistryScopes = []string{"*"}
+	policyDocument.TrustPolicies[0].TrustedIdentities = []string{"x509.subject:CN=Static Identity,O=Notary,L=Seattle,ST=WA,C=US"}
+	policyDocument.TrustPolicies[0].SignatureVerification.Override = map[trustpolicy.ValidationType]trustpolicy.ValidationAction{
+		trustpolicy.TypeRevocation: trustpolicy.ActionSkip,
+	}
+	dir.UserConfigDir = "testdata"
+	store := truststore.NewX509TrustStore(dir.ConfigFS())
+	resolver := &namespaceResolver{
+		identities: map[string][]string{
+			"software":  {"x509.subject:CN=Notation Test Root,O=Notary,L=Seattle,ST=WA,C=US"},
+			"untrusted": {"x509.subject:CN=Untrusted,O=Notary,L=Seattle,ST=WA,C=US"},
+			"malformed": {"*", "x509.subject:CN=Untrusted,O=Notary,L=Seattle,ST=WA,C=US"},
+		},
+	}
+	v, err := NewWithOptions(&policyDocument, store, mock.PluginManager{}, VerifierOptions{TrustedIdentityResolver: resolver, TrustedIdentityCacheTTL: time.Hour})
+	if err != nil {
+		t.Fatalf("unexpected error while creating verifier: %v", err)
+	}
+
+	tests := []struct {
+		reference string
+		wantErr   bool
+	}{
+		{"registry.acme-rockets.io/software/net-monitor@sha256:60043cf45eaebc4c0867fea485a039b598f52fd09fd5b07b0b2d2f88fad9d74e", false},
+		{"registry.acme-rockets.io/untrusted/net-monitor@sha256:60043cf45eaebc4c0867fea485a039b598f52fd09fd5b07b0b2d2f88fad9d74e", true},
+		{"registry.acme-rockets.io/unknown/net-monitor@sha256:60043cf45eaebc4c0867fea485a039b598f52fd09fd5b07b0b2d2f88fad9d74e", true},
+		{"registry.acme-rockets.io/malformed/net-monitor@sha256:60043cf45eaebc4c0867fea485a039b598f52fd09fd5b07b0b2d2f88fad9d74e", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.reference, func(t *testing.T) {
+			opts := notation.VerifierVerifyOptions{ArtifactReference: tt.reference, SignatureMediaType: "application/jose+json"}
+			outcome, err := v.Verify(context.Background(), mock.ImageDescriptor, mock.MockCaValidSigEnv, opts)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("TestTrustedIdentityResolver Error: %v WantErr: %v", err, tt.wantErr)
+			}
+			if outcome == nil {
+				t.Fatal("expected non-nil outcome")
+			}
+		})
+	}
+
+	// resolved identities are cached
+	calls := resolver.calls
+	opts := notation.VerifierVerifyOptions{ArtifactReference: tests[0].reference, SignatureMediaType: "application/jose+json"}
+	if _, err := v.Verify(context.Background(), mock.ImageDescriptor, mock.MockCaValidSigEnv, opts); err != nil {
+		t.Fatalf("expected verification to succeed, but got %v", err)
+	}
+	if resolver.calls != calls {
+		t.Fatalf("expected resolved trusted identities to be cached, but resolver was called %d more times", resolver.calls-calls)
+	}
+}
+
+func TestTrustedIdentityCacheSize(t *testing.T) {
+	cache := newTrustedIdentityCache(time.Hour)
+	for i := 0; i < defaultTrustedIdentityCacheSize+10; i++ {
+		cache.add(trustedIdentityCacheKey{statement: "test", artifactReference: fmt.Sprintf("registry.acme-rockets.io/software/net-monitor:%d", i)}, []string{"*"})
+	}
+	if n := cache.entries.Len(); n != defaultTrustedIdentityCacheSize {
+		t.Fatalf("expected %d cached entries, but got %d", defaultTrustedIdentityCacheSize, n)
+	}
+	if _, ok := cache.get(trustedIdentityCacheKey{statement: "test", artifactReference: "registry.acme-rockets.io/software/net-monitor:0"}); ok {
+		t.Fatal("expected the least recently used entry to be evicted")
+	}
+
+	// expired entries are not returned
+	cache = newTrustedIdentityCache(-time.Second)
+	key := trustedIdentityCacheKey{statement: "test", artifactReference: "registry.acme-rockets.io/software/net-monitor:v1"}
+	cache.add(key, []string{"*"})
+	if _, ok := cache.get(key); ok {
+		t.Fatal("expected expired entry not to be returned")
+	}
+}
+
 func TestPluginVersionCompatibility(t *testing.T) {
 
 	errTemplate := "found plugin io.cncf.notary.plugin.unittest.mock with version 1.0.0 but signature verification needs plugin version greater than or equal to "